@@ -0,0 +1,105 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/importer"
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// aclCmd represents the acl command
+var aclCmd = &cobra.Command{
+	Use:   "acl <acl.txt>",
+	Short: "audit the address matches of Cisco access lists",
+	Long: `Read the IPv4 access lists in a Cisco IOS config or the output of 'show access-lists', convert each address and
+wildcard mask pair to a prefix, and report:
+  - entries with non-contiguous wildcards, such as 0.0.255.0, which can't be written as prefixes
+  - the sources of each ACL's permit entries, aggregated into the fewest prefixes
+  - source prefixes within an ACL that overlap
+  - entries shadowed by an earlier entry that matches everything they do, so they are never reached. A shadowed entry
+    with a different action than the one shadowing it is a conflict; one with the same action is just redundant.
+
+Ports and other qualifiers are compared as written, so an entry only shadows later entries with the same qualifiers
+unless it has none. Use - to read from stdin.
+
+Exits 1 if any entries are shadowed.
+
+Examples:
+  # Audit the ACLs in a saved running config:
+  subnetCalc acl router1.cfg
+
+  # Audit a live router's ACLs:
+  ssh router1 'show access-lists' | subnetCalc acl - --json
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		f, err := openInput(args[0])
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		entries, err := importer.ReadACL(f)
+		f.Close()
+		if err != nil {
+			utils.Log.Fatal().Msgf("%s: %v", args[0], err)
+		}
+		if len(entries) == 0 {
+			utils.Log.Fatal().Msgf("%s: no access list entries found", args[0])
+		}
+		audit := subnet.AuditACL(entries)
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			printJSON(audit)
+		} else {
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.SetStyle(table.StyleRounded)
+			t.AppendHeader(table.Row{"ACL", "PERMITTED SOURCES", "ADDRESSES"})
+			for _, c := range audit.Coverage {
+				permitted := make([]string, len(c.Permitted))
+				for i, p := range c.Permitted {
+					permitted[i] = p.String()
+				}
+				t.AppendRow(table.Row{c.ACL, strings.Join(permitted, "\n"), formatBigInt(c.Addresses)})
+			}
+			t.Render()
+			if len(audit.NonContiguous) > 0 {
+				fmt.Println("Non-contiguous wildcards:")
+				for _, e := range audit.NonContiguous {
+					fmt.Printf("  line %-5d %s\n", e.Line, e.Text)
+				}
+			}
+			if len(audit.Overlaps) > 0 {
+				fmt.Println("Overlapping sources:")
+				for _, o := range audit.Overlaps {
+					fmt.Printf("  %-12s %-20v %v\n", o.ACL, o.Outer, o.Inner)
+				}
+			}
+			if len(audit.Shadowed) > 0 {
+				fmt.Println("Shadowed entries:")
+				for _, s := range audit.Shadowed {
+					kind := "conflict"
+					if s.Redundant {
+						kind = "redundant"
+					}
+					fmt.Printf("  line %-5d %s\n    %s, shadowed by line %d: %s\n", s.Entry.Line, s.Entry.Text, kind, s.By.Line, s.By.Text)
+				}
+			}
+		}
+		if len(audit.Shadowed) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(aclCmd)
+	aclCmd.Flags().BoolP("json", "j", false, "output the audit in json format")
+}
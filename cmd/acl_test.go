@@ -0,0 +1,70 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// writeACL writes config to a file in a temporary directory.
+// returns the path of the file.
+func writeACL(t *testing.T, config string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "router.cfg")
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestACL(t *testing.T) {
+	clean := writeACL(t, `access-list 20 permit 10.0.0.0 0.0.0.255
+access-list 20 permit 10.0.1.0 0.0.0.255
+`)
+	stdout, stderr, code := runCLI(t, "acl", clean)
+	if code != 0 || !strings.Contains(stdout, "10.0.0.0/23") || !strings.Contains(stdout, "512") ||
+		strings.Contains(stdout, "Shadowed entries:") {
+		t.Errorf("acl of a clean list = %q, exit %d %s, want 10.0.0.0/23 with 512 addresses and nothing shadowed",
+			stdout, code, stderr)
+	}
+
+	shadowed := writeACL(t, `access-list 10 permit 10.1.0.0 0.0.255.255
+access-list 10 deny 10.1.2.0 0.0.0.255
+access-list 10 permit 10.1.3.0 0.0.0.255
+access-list 10 deny 10.0.0.0 0.255.0.255
+`)
+	stdout, _, code = runCLI(t, "acl", shadowed)
+	for _, want := range []string{
+		"Non-contiguous wildcards:\n  line 4     access-list 10 deny 10.0.0.0 0.255.0.255\n",
+		"  10           10.1.0.0/16          10.1.3.0/24\n",
+		"  line 2     access-list 10 deny 10.1.2.0 0.0.0.255\n    conflict, shadowed by line 1:",
+		"  line 3     access-list 10 permit 10.1.3.0 0.0.0.255\n    redundant, shadowed by line 1:",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("acl = %q, want it to contain %q", stdout, want)
+		}
+	}
+	if code != 1 {
+		t.Errorf("acl with shadowed entries exited %d, want 1", code)
+	}
+
+	stdout, _, code = runCLI(t, "acl", shadowed, "--json")
+	var audit subnet.ACLAudit
+	if err := json.Unmarshal([]byte(stdout), &audit); err != nil || code != 1 || audit.Entries != 4 ||
+		len(audit.Shadowed) != 2 || len(audit.NonContiguous) != 1 {
+		t.Errorf("acl --json = %q, exit %d, want 4 entries, 2 shadowed and 1 non-contiguous", stdout, code)
+	}
+
+	for _, config := range []string{"hostname router1\n", "access-list 101 permit tcp object-group WEB any\n"} {
+		if _, stderr, code := runCLI(t, "acl", writeACL(t, config)); code == 0 || stderr == "" {
+			t.Errorf("acl of %q exited %d, want an error", config, code)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"os"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+// parsePrefixArgs parses prefixes given as arguments, reading bare addresses as single host prefixes.
+func parsePrefixArgs(args []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(args))
+	for _, arg := range args {
+		p, err := netip.ParsePrefix(arg)
+		if err != nil {
+			a, addrErr := netip.ParseAddr(arg)
+			if addrErr != nil {
+				return nil, err
+			}
+			p = netip.PrefixFrom(a, a.BitLen())
+		}
+		prefixes = append(prefixes, p.Masked())
+	}
+	return prefixes, nil
+}
+
+// aggregateCmd represents the aggregate command
+var aggregateCmd = &cobra.Command{
+	Use:   "aggregate [CIDR]...",
+	Short: "summarize prefixes into the fewest covering prefixes",
+	Long: `Combine a list of prefixes into the smallest set of prefixes covering exactly the same addresses: duplicates and
+prefixes inside other prefixes are dropped, and adjacent blocks are merged into their supernet. Prefixes are taken
+from the arguments, or read one per line from --file or stdin.
+
+Use --max-prefixes to summarize into at most that many prefixes instead, accepting the fewest possible extra
+addresses. The number of extra addresses is written to stderr.
+
+Examples:
+  # Summarize four /24s into a /22:
+  subnetCalc aggregate 10.0.0.0/24 10.0.1.0/24 10.0.2.0/24 10.0.3.0/24
+
+  # Summarize a prefix list into at most 8 routes for a peer:
+  subnetCalc aggregate --file prefixes.txt --max-prefixes 8
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("file")
+		max, _ := cmd.Flags().GetInt("max-prefixes")
+		if max < 0 {
+			utils.Log.Fatal().Msgf("max prefixes must be positive, got %d", max)
+		}
+
+		prefixes, err := parsePrefixArgs(args)
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		if len(args) == 0 || cmd.Flags().Changed("file") {
+			f, err := openInput(file)
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			read, err := readPrefixes(f)
+			f.Close()
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			prefixes = append(prefixes, read...)
+		}
+
+		aggregates, extra := subnet.AggregateBudget(prefixes, max)
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			printJSON(struct {
+				Prefixes   int            `json:"prefixes"`
+				Aggregates []netip.Prefix `json:"aggregates"`
+				Overshoot  *big.Int       `json:"overshoot"`
+			}{len(prefixes), aggregates, extra})
+			return
+		}
+		for _, p := range aggregates {
+			fmt.Println(p)
+		}
+		if extra.Sign() > 0 {
+			fmt.Fprintf(os.Stderr, "overshoot: %s addresses not in the prefixes are covered\n", formatBigInt(extra))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(aggregateCmd)
+	aggregateCmd.Flags().StringP("file", "f", "", "file listing prefixes to aggregate, one per line, - for stdin")
+	aggregateCmd.Flags().IntP("max-prefixes", "m", 0, "summarize into at most this many prefixes, allowing extra addresses")
+	aggregateCmd.Flags().BoolP("json", "j", false, "output the aggregates and overshoot in json format")
+}
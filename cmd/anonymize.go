@@ -0,0 +1,105 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"bufio"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+// anonymizeCmd represents the anonymize command
+var anonymizeCmd = &cobra.Command{
+	Use:   "anonymize [file]...",
+	Short: "rewrite addresses in text into documentation ranges",
+	Long: `Rewrite the IPv4 and IPv6 addresses found in arbitrary text, such as device configs or logs, so they can be shared in
+bug reports without leaking internal addressing. Addresses inside the --map source prefixes are rewritten into the
+replacement prefixes with a prefix-preserving mapping: addresses in the same subnet stay in the same subnet. Network
+addresses followed by their prefix length stay network addresses. Other text is unchanged.
+
+When a replacement prefix is smaller than its source, relationships are only preserved within blocks the size of the
+replacement and unrelated addresses may collide. The mapping is random for each run unless --key is given.
+
+Reads the files given, or stdin, and writes to stdout.
+
+Examples:
+  # Rewrite a config's private addresses into documentation ranges:
+  subnetCalc anonymize --map 10.0.0.0/8=198.51.100.0/24 --map 2001:db8:1234::/48=3fff::/20 < config.txt
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		specs, _ := cmd.Flags().GetStringArray("map")
+		if len(specs) == 0 {
+			utils.Log.Fatal().Msg("at least one --map is required")
+		}
+		var mappings []subnet.Mapping
+		for _, spec := range specs {
+			m, err := subnet.ParseMapping(spec)
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			mappings = append(mappings, m)
+		}
+
+		key, _ := cmd.Flags().GetString("key")
+		secret := []byte(key)
+		if key == "" {
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+		}
+		a := subnet.NewAnonymizer(secret, mappings)
+
+		if len(args) == 0 {
+			args = []string{"-"}
+		}
+		out := bufio.NewWriter(os.Stdout)
+		err := anonymizeFiles(out, a, args)
+		// write what was rewritten before a read error too, so the output shows where it stopped
+		if flushErr := out.Flush(); err == nil {
+			err = flushErr
+		}
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+	},
+}
+
+// anonymizeFiles writes the text of each named file, or stdin for "-", to w with its addresses rewritten by a.
+// returns the first error opening or reading a file.
+func anonymizeFiles(w io.Writer, a *subnet.Anonymizer, names []string) error {
+	for _, name := range names {
+		f, err := openInput(name)
+		if err != nil {
+			return err
+		}
+		r := bufio.NewReader(f)
+		for {
+			line, err := r.ReadString('\n')
+			fmt.Fprint(w, a.Text(line))
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				f.Close()
+				return fmt.Errorf("reading %s: %w", name, err)
+			}
+		}
+		f.Close()
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(anonymizeCmd)
+	anonymizeCmd.Flags().StringArrayP("map", "m", nil, "source and replacement prefixes, e.g. 10.0.0.0/8=198.51.100.0/24, may be repeated")
+	anonymizeCmd.Flags().StringP("key", "k", "", "secret that makes the mapping repeatable across runs")
+}
@@ -0,0 +1,28 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnonymizeReadError(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.txt")
+	if err := os.WriteFile(config, []byte("hostname edge1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// a directory opens but can't be read, which must fail the command after writing what came before it
+	stdout, stderr, code := runCLI(t, "anonymize", "--map", "10.0.0.0/8=198.51.100.0/24", config, dir)
+	if code == 0 || !strings.Contains(stderr, dir) {
+		t.Errorf("anonymize of a directory exited %d with %q, want an error naming it", code, stderr)
+	}
+	if stdout != "hostname edge1\n" {
+		t.Errorf("anonymize printed %q before the error, want the first file flushed", stdout)
+	}
+}
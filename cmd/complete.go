@@ -0,0 +1,65 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// completeSubnetSizes suggests the prefix lengths longer than the network being split. Sizes already typed in a comma
+// separated list are kept as a prefix of each suggestion.
+func completeSubnetSizes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	p, err := netip.ParsePrefix(args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	typed := toComplete[:strings.LastIndex(toComplete, ",")+1]
+	var sizes []string
+	for bits := p.Bits() + 1; bits <= p.Addr().BitLen(); bits++ {
+		sizes = append(sizes, fmt.Sprintf("%s%d", typed, bits))
+	}
+	return sizes, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// completeList returns a completion function suggesting a fixed list of values. Values already typed in a comma
+// separated list are kept as a prefix of each suggestion when multiple is true.
+func completeList(values []string, multiple bool) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if !multiple {
+			return values, cobra.ShellCompDirectiveNoFileComp
+		}
+		typed := toComplete[:strings.LastIndex(toComplete, ",")+1]
+		var suggestions []string
+		for _, v := range values {
+			suggestions = append(suggestions, typed+v)
+		}
+		return suggestions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+}
+
+// completeProfiles suggests the profiles defined in the config file.
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	path, _ := cmd.Flags().GetString("config")
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigPath()
+	}
+	c, err := loadConfig(path, explicit)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return c.profileNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// noCompletion suggests nothing, for positional arguments such as CIDRs that can't be completed.
+func noCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
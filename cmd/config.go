@@ -0,0 +1,200 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+// Profile is a named set of organizational conventions applied to every command by --profile.
+type Profile struct {
+	Supernets []netip.Prefix         `json:"supernets,omitempty"` // networks are warned about unless they fall within one of these
+	Cloud     string                 `json:"cloud,omitempty"`     // provider whose reserved addresses are excluded from host ranges
+	Gateway   string                 `json:"gateway,omitempty"`   // gateway convention, first or last
+	Naming    string                 `json:"naming,omitempty"`    // default --file-name template
+	Flags     map[string]string      `json:"flags,omitempty"`     // defaults for any other flag, by name
+	Hooks     []Hook                 `json:"hooks,omitempty"`     // run after a command changes a plan file
+	Platforms []subnet.PlatformRange `json:"platforms,omitempty"` // platform default ranges to warn about, replacing the built-in list
+}
+
+// Config is the contents of the config file.
+type Config struct {
+	Profile  string             `json:"profile,omitempty"` // profile used when --profile isn't given
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// profile holds the conventions selected by --profile, or by the config file's default profile.
+var profile Profile
+
+// defaultConfigPath returns the path of the config file used when --config isn't given.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "subnetCalc", "config.json")
+}
+
+// loadConfig reads the config file at path.
+// returns an empty config if path is the default location and doesn't exist.
+func loadConfig(path string, explicit bool) (Config, error) {
+	var c Config
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return c, nil
+	} else if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// profileNames returns the names of the config's profiles in sorted order.
+func (c Config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validate checks that the profile's settings are understood.
+// returns an error naming the first unknown cloud provider or gateway convention, or invalid hook.
+func (p Profile) validate() error {
+	if _, ok := subnet.CloudReservations[p.Cloud]; p.Cloud != "" && !ok {
+		return fmt.Errorf("unknown cloud provider %q", p.Cloud)
+	}
+	if p.Gateway != "" && !slices.Contains(subnet.GatewayConventions, p.Gateway) {
+		return fmt.Errorf("unknown gateway convention %q", p.Gateway)
+	}
+	for _, h := range p.Hooks {
+		if err := h.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyProfile selects the profile named by --profile, or the config file's default, and sets any of the command's
+// flags it has defaults for that weren't given on the command line. Flags the command doesn't have are ignored, so one
+// profile can serve every command.
+func applyProfile(cmd *cobra.Command) error {
+	path, _ := cmd.Flags().GetString("config")
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigPath()
+	}
+	c, err := loadConfig(path, explicit)
+	if err != nil {
+		return err
+	}
+
+	name, _ := cmd.Flags().GetString("profile")
+	if name == "" {
+		name = c.Profile
+	}
+	if name == "" {
+		return nil
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		return fmt.Errorf("profile %q not found in %s, expected one of: %v", name, path, c.profileNames())
+	}
+	if err := p.validate(); err != nil {
+		return fmt.Errorf("profile %q: %w", name, err)
+	}
+	profile = p
+
+	defaults := map[string]string{}
+	for flag, value := range p.Flags {
+		defaults[flag] = value
+	}
+	if p.Naming != "" {
+		defaults["file-name"] = p.Naming
+	}
+	for flag, value := range defaults {
+		f := cmd.Flags().Lookup(flag)
+		if f == nil || f.Changed {
+			continue
+		}
+		if err := cmd.Flags().Set(flag, value); err != nil {
+			return fmt.Errorf("profile %q: --%s: %w", name, flag, err)
+		}
+		utils.Module("profile").Debug().Msgf("--%s=%s from profile %q", flag, value, name)
+	}
+	return nil
+}
+
+// applyConventions applies the profile's cloud reservations and gateway convention to n and its subnets, and warns
+// if n isn't within one of the profile's supernets. if strict is true, a network outside the supernets is fatal.
+func applyConventions(n *subnet.Network, strict bool) {
+	within := len(profile.Supernets) == 0
+	for _, s := range profile.Supernets {
+		within = within || (s.Bits() <= n.MaskBits && s.Contains(n.NetworkAddr))
+	}
+	if !within {
+		msg := fmt.Sprintf("%v is not within the profile's supernets %v", n.CIDR, profile.Supernets)
+		if strict {
+			utils.Log.Fatal().Msg(msg)
+		}
+		fmt.Fprintln(os.Stderr, "warning:", msg)
+	}
+	applyHostConventions(n)
+}
+
+// platformRanges returns the platform default ranges to warn about: the profile's, if it lists any, even an empty
+// list, or the built-in ones.
+func platformRanges() []subnet.PlatformRange {
+	if profile.Platforms != nil {
+		return profile.Platforms
+	}
+	return subnet.PlatformRanges
+}
+
+var noPlatformCheck bool
+
+// checkPlatforms warns, in a single line, if p overlaps any platform default ranges, which hosts running the platform
+// route to it instead, unless --no-platform-check is set.
+func checkPlatforms(p netip.Prefix) {
+	if noPlatformCheck {
+		return
+	}
+	collisions := subnet.PlatformCollisions(p, platformRanges())
+	if len(collisions) == 0 {
+		return
+	}
+	ranges := make([]string, len(collisions))
+	for i, r := range collisions {
+		ranges[i] = r.String()
+	}
+	fmt.Fprintf(os.Stderr, "warning: %v overlaps platform default ranges, unreachable from hosts running them: %s\n", p, strings.Join(ranges, ", "))
+}
+
+// applyHostConventions applies the profile's cloud reservations and gateway convention to n and its subnets.
+func applyHostConventions(n *subnet.Network) {
+	if profile.Cloud != "" {
+		n.Reserve(subnet.CloudReservations[profile.Cloud])
+	}
+	if profile.Gateway != "" {
+		if err := n.SetGateway(profile.Gateway); err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// conflictsCmd represents the conflicts command
+var conflictsCmd = &cobra.Command{
+	Use:   "conflicts <a-plan.json> <b-plan.json>",
+	Short: "report where two address plans collide",
+	Long: `Compare the address plans of two organizations before joining their networks. Reports the supernets that overlap,
+every pair of allocations using the same addresses, and the blocks to translate with NAT so the plans can coexist. The
+smaller prefix of each colliding pair is suggested, as it renumbers the fewest addresses; a side of "either" means both
+plans use exactly the same prefix.
+
+Exits 1 if the plans collide.
+
+Examples:
+  # Find the collisions between two companies' plans:
+  subnetCalc conflicts acme.json globex.json
+
+  # Feed the NAT candidates to another tool:
+  subnetCalc conflicts acme.json globex.json --json | jq -r '.natCandidates[].prefix'
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := subnet.FindCollisions(loadPlan(args[0]), loadPlan(args[1]))
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			printJSON(c)
+		} else if c.Empty() {
+			fmt.Println("no collisions")
+		} else {
+			if len(c.Supernets) > 0 {
+				fmt.Println("Overlapping supernets:")
+				for _, o := range c.Supernets {
+					fmt.Printf("  %-20v %v\n", o.Outer, o.Inner)
+				}
+			}
+			if len(c.Conflicts) > 0 {
+				t := table.NewWriter()
+				t.SetOutputMirror(os.Stdout)
+				t.SetStyle(table.StyleRounded)
+				t.AppendHeader(table.Row{"A PREFIX", "A NAME", "B PREFIX", "B NAME", "SHARED", "ADDRESSES"})
+				for _, conflict := range c.Conflicts {
+					t.AppendRow(table.Row{conflict.A.CIDR, conflict.A.Name, conflict.B.CIDR, conflict.B.Name, conflict.Shared, formatBigInt(subnet.AddressCount(conflict.Shared))})
+				}
+				t.Render()
+			}
+			if len(c.NATCandidates) > 0 {
+				fmt.Println("NAT candidates:")
+				for _, n := range c.NATCandidates {
+					fmt.Println(strings.TrimRight(fmt.Sprintf("  %-20v %-7s %s", n.Prefix, n.Side, n.Name), " "))
+				}
+			}
+		}
+		if !c.Empty() {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(conflictsCmd)
+	conflictsCmd.Flags().BoolP("json", "j", false, "output the collisions in json format")
+}
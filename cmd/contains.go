@@ -0,0 +1,110 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"os"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+// containment describes whether a prefix or address is inside a network.
+type containment struct {
+	CIDR     netip.Prefix `json:"cidr"`
+	Inside   bool         `json:"inside"`
+	Relation string       `json:"relation,omitempty"` // as returned by subnet.Relate, when not inside
+	Offset   *big.Int     `json:"offset,omitempty"`   // position of the first address in the network
+	Index    *big.Int     `json:"index,omitempty"`    // 1-based position among the network's subnets of the same size
+}
+
+// newContainment checks whether p is inside n.
+func newContainment(n subnet.Network, p netip.Prefix) containment {
+	c := containment{CIDR: p, Inside: n.ContainsPrefix(p)}
+	if !c.Inside {
+		c.Relation = subnet.Relate(p, n.CIDR)
+		return c
+	}
+	c.Offset, _ = n.Offset(p.Addr())
+	if p.Bits() > n.MaskBits && p.Bits() < p.Addr().BitLen() {
+		size := subnet.AddressCount(p)
+		c.Index = new(big.Int).Div(c.Offset, size)
+		c.Index.Add(c.Index, big.NewInt(1))
+	}
+	return c
+}
+
+// containsCmd represents the contains command
+var containsCmd = &cobra.Command{
+	Use:   "contains <CIDR> <CIDR | address>...",
+	Short: "check whether addresses or prefixes are inside a network",
+	Long: `Check whether each address or prefix is inside a network, and where: the offset of its first address from the network
+address and, for prefixes, its position among the network's subnets of the same size.
+
+Exits 0 if everything is inside the network, 1 if anything isn't, and 2 if an argument can't be parsed, so it can be
+used in scripts with --quiet.
+
+Examples:
+  # Where is this host in the VPC?
+  subnetCalc contains 10.0.0.0/16 10.0.5.7
+
+  # Fail a deployment if a subnet is outside its VPC:
+  subnetCalc contains 10.0.0.0/16 "$SUBNET" --quiet || exit 1
+`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		supernet, err := netip.ParsePrefix(args[0])
+		if err != nil {
+			utils.Log.Error().Msg(err.Error())
+			os.Exit(2)
+		}
+		prefixes, err := parsePrefixArgs(args[1:])
+		if err != nil {
+			utils.Log.Error().Msg(err.Error())
+			os.Exit(2)
+		}
+
+		n := subnet.NewNetworkFromPrefix(supernet.Masked())
+		results := make([]containment, len(prefixes))
+		inside := true
+		for i, p := range prefixes {
+			results[i] = newContainment(n, p)
+			inside = inside && results[i].Inside
+		}
+
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		switch asJSON, _ := cmd.Flags().GetBool("json"); {
+		case quiet:
+		case asJSON:
+			printJSON(results)
+		default:
+			for i, c := range results {
+				what := args[i+1]
+				switch {
+				case !c.Inside && c.Relation == subnet.RelationContains:
+					fmt.Printf("%s is not in %v, it contains it\n", what, n.CIDR)
+				case !c.Inside:
+					fmt.Printf("%s is not in %v\n", what, n.CIDR)
+				case c.Index != nil:
+					fmt.Printf("%s is in %v at offset %s, /%d number %s of %s\n", what, n.CIDR, formatBigInt(c.Offset), c.CIDR.Bits(), formatBigInt(c.Index), formatBigInt(n.SubnetCount(c.CIDR.Bits())))
+				default:
+					fmt.Printf("%s is in %v at offset %s\n", what, n.CIDR, formatBigInt(c.Offset))
+				}
+			}
+		}
+		if !inside {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(containsCmd)
+	containsCmd.Flags().BoolP("quiet", "q", false, "print nothing, only set the exit code")
+	containsCmd.Flags().BoolP("json", "j", false, "output the results in json format")
+}
@@ -0,0 +1,92 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/formatter"
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+// coverCmd represents the cover command
+var coverCmd = &cobra.Command{
+	Use:     "cover <start-end|CIDR>",
+	Aliases: []string{"range"},
+	Short:   "list the prefixes covering an address range",
+	Long: `List the fewest CIDR prefixes that exactly cover an inclusive range of IPv4 or IPv6 addresses, one per line.
+
+With --format, the prefixes are written in any output format as the subnets of the smallest prefix enclosing the
+range, showing the first and last address and host count of each.
+
+ACL and prefix-list tables often limit the number of entries. Use --max-prefixes to cover the range with at most that
+many prefixes instead, accepting the fewest possible extra addresses outside the range. The number of extra addresses
+is written to stderr.
+
+Examples:
+  # Exactly cover an arbitrary range:
+  subnetCalc cover 10.0.0.5-10.0.3.250
+
+  # Cover the same range with no more than three prefixes:
+  subnetCalc cover 10.0.0.5-10.0.3.250 --max-prefixes 3
+
+  # Show the details of each prefix as a table:
+  subnetCalc range 10.0.0.5-10.0.3.17 --format table
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		r, err := subnet.ParseRange(args[0])
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		max, _ := cmd.Flags().GetInt("max-prefixes")
+		if max < 0 {
+			utils.Log.Fatal().Msgf("max prefixes must be positive, got %d", max)
+		}
+		prefixes, overshoot := subnet.CoverBudget(r, max)
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			printJSON(struct {
+				Range     subnet.Range   `json:"range"`
+				Prefixes  []netip.Prefix `json:"prefixes"`
+				Overshoot *big.Int       `json:"overshoot"`
+			}{r, prefixes, overshoot})
+			return
+		}
+		if format, _ := cmd.Flags().GetString("format"); format != "" {
+			fm, err := newFormatter(cmd, format)
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			n := subnet.NewNetworkFromPrefix(r.Enclosing())
+			for _, p := range prefixes {
+				n.Subnets = append(n.Subnets, subnet.NewNetworkFromPrefix(p))
+			}
+			if err := fm.Format(os.Stdout, n); err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+		} else {
+			for _, p := range prefixes {
+				fmt.Println(p)
+			}
+		}
+		if overshoot.Sign() > 0 {
+			fmt.Fprintf(os.Stderr, "overshoot: %s addresses outside %v are covered\n", formatBigInt(overshoot), r)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(coverCmd)
+	coverCmd.Flags().IntP("max-prefixes", "m", 0, "cover the range with at most this many prefixes, allowing extra addresses")
+	coverCmd.Flags().BoolP("json", "j", false, "output the prefixes and overshoot in json format")
+	coverCmd.Flags().StringP("format", "f", "", "write the prefixes as subnets in this output format: "+strings.Join(formatter.Formats, ", "))
+	coverCmd.RegisterFlagCompletionFunc("format", completeList(formatter.Formats, false))
+}
@@ -0,0 +1,48 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCover(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		stdout string
+		stderr string
+	}{
+		{"exact", []string{"cover", "10.0.0.5-10.0.0.10"}, "10.0.0.5/32\n10.0.0.6/31\n10.0.0.8/31\n10.0.0.10/32\n", ""},
+		{"cidr", []string{"cover", "10.0.0.0/30"}, "10.0.0.0/30\n", ""},
+		{"ipv6", []string{"cover", "2001:db8::1-2001:db8::6"}, "2001:db8::1/128\n2001:db8::2/127\n2001:db8::4/127\n2001:db8::6/128\n", ""},
+		{"budget", []string{"cover", "10.0.0.5-10.0.0.10", "--max-prefixes", "2"}, "10.0.0.4/30\n10.0.0.8/30\n",
+			"overshoot: 2 addresses outside 10.0.0.5-10.0.0.10 are covered\n"},
+		{"budget fits", []string{"cover", "10.0.0.0/30", "-m", "1"}, "10.0.0.0/30\n", ""},
+		{"range alias", []string{"range", "10.0.0.5-10.0.0.10", "-m", "1"}, "10.0.0.0/28\n",
+			"overshoot: 10 addresses outside 10.0.0.5-10.0.0.10 are covered\n"},
+	}
+	for _, tt := range tests {
+		stdout, stderr, code := runCLI(t, tt.args...)
+		if code != 0 || stdout != tt.stdout || stderr != tt.stderr {
+			t.Errorf("%s: %v = %q, %q, exit %d, want %q, %q", tt.name, tt.args, stdout, stderr, code, tt.stdout, tt.stderr)
+		}
+	}
+
+	stdout, _, code := runCLI(t, "cover", "10.0.0.5-10.0.0.10", "--json", "-m", "2")
+	var v struct {
+		Prefixes  []string `json:"prefixes"`
+		Overshoot int      `json:"overshoot"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &v); code != 0 || err != nil || len(v.Prefixes) != 2 || v.Overshoot != 2 {
+		t.Errorf("cover --json printed %q, exit %d, want two prefixes and an overshoot of 2", stdout, code)
+	}
+
+	for _, args := range [][]string{{"10.0.0.10-10.0.0.5"}, {"10.0.0.5-2001:db8::1"}, {"10.0.0.0/24", "-m", "-1"}} {
+		if _, _, code := runCLI(t, append([]string{"cover"}, args...)...); code == 0 {
+			t.Errorf("cover %v exited 0, want an error", args)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+// ptrResult is the outcome of looking up the PTR records of one address.
+type ptrResult struct {
+	Addr  netip.Addr
+	Names []string
+	Err   error
+}
+
+// newResolver returns a resolver that sends queries to server, a host with an optional port, or the system resolver if
+// server is empty.
+func newResolver(server string) *net.Resolver {
+	if server == "" {
+		return net.DefaultResolver
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, server)
+		},
+	}
+}
+
+// sweepPTR looks up the PTR records of every host address in prefix, starting at most rate lookups per second, and returns
+// the results in address order. Addresses without a reverse entry have no names and no error.
+func sweepPTR(r *net.Resolver, prefix netip.Prefix, rate int, timeout time.Duration) []ptrResult {
+	addrs := hostAddrs(prefix)
+	results := make([]ptrResult, len(addrs))
+	rateLimited(addrs, rate, func(i int, a netip.Addr) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		names, err := r.LookupAddr(ctx, a.String())
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			err = nil
+		}
+		results[i] = ptrResult{Addr: a, Names: names, Err: err}
+	})
+	return results
+}
+
+// planDiscoverCmd represents the plan discover command
+var planDiscoverCmd = &cobra.Command{
+	Use:   "discover <plan.json> <CIDR>",
+	Short: "mark addresses with reverse DNS entries as in use",
+	Long: `Look up the PTR record of every address in a prefix and add each address with a reverse entry to the plan as an
+allocation, named after its first PTR name and labelled source=ptr. Addresses inside an existing allocation are only
+reported. This gives a quick reality check of a plan against what is actually in DNS.
+
+Lookups are sent to the system resolver, or to --resolver, and limited to --rate per second. Use --dry-run to report
+what would be added without changing the plan.
+
+Examples:
+  # Find the hosts with reverse entries in a /24 the plan thinks is free:
+  subnetCalc plan discover plan.json 10.0.8.0/24 --resolver 10.0.0.53 --dry-run
+
+  # Record them in the plan:
+  subnetCalc plan discover plan.json 10.0.8.0/24 --resolver 10.0.0.53
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		p := loadPlan(args[0])
+		prefix, err := netip.ParsePrefix(args[1])
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		prefix = prefix.Masked()
+
+		flags := cmd.Flags()
+		server, _ := flags.GetString("resolver")
+		rate, _ := flags.GetInt("rate")
+		timeout, _ := flags.GetDuration("timeout")
+		limit, _ := flags.GetInt("max-addresses")
+		dryRun, _ := flags.GetBool("dry-run")
+		if rate < 1 {
+			utils.Log.Fatal().Msgf("rate must be positive, got %d", rate)
+		}
+		if count := subnet.AddressCount(prefix); count.Cmp(big.NewInt(int64(limit))) > 0 {
+			utils.Log.Fatal().Msgf("%v has %s addresses, more than --max-addresses %d", prefix, formatBigInt(count), limit)
+		}
+
+		added, failed := 0, 0
+		for _, res := range sweepPTR(newResolver(server), prefix, rate, timeout) {
+			if res.Err != nil {
+				failed++
+				utils.Module("discover").Debug().Msgf("%v: %v", res.Addr, res.Err)
+				continue
+			}
+			if len(res.Names) == 0 {
+				continue
+			}
+			name := strings.TrimSuffix(res.Names[0], ".")
+			host := netip.PrefixFrom(res.Addr, res.Addr.BitLen())
+			if existing, ok := p.Containing(host); ok {
+				fmt.Printf("%-15v %s, in use by %v %s\n", res.Addr, name, existing.CIDR, existing.Name)
+				continue
+			}
+			a := subnet.Allocation{CIDR: host, Name: name, Note: "discovered by PTR lookup", Labels: map[string]string{"source": "ptr"}}
+			if err := p.Allocate(a); err != nil {
+				fmt.Printf("%-15v %s, not added: %v\n", res.Addr, name, err)
+				continue
+			}
+			fmt.Printf("%-15v %s, added\n", res.Addr, name)
+			added++
+		}
+		if failed > 0 {
+			fmt.Fprintf(os.Stderr, "warning: %d lookups failed, use --log-level discover=debug to see why\n", failed)
+		}
+		if added > 0 && !dryRun {
+			savePlan(p, args[0])
+		}
+	},
+}
+
+func init() {
+	planCmd.AddCommand(planDiscoverCmd)
+	planDiscoverCmd.Flags().String("resolver", "", "DNS server to query, host[:port], instead of the system resolver")
+	planDiscoverCmd.Flags().Int("rate", 50, "maximum number of lookups started per second")
+	planDiscoverCmd.Flags().Duration("timeout", 2*time.Second, "time to wait for each lookup")
+	planDiscoverCmd.Flags().Int("max-addresses", 65536, "largest number of addresses to look up")
+	planDiscoverCmd.Flags().Bool("dry-run", false, "report the addresses found without changing the plan")
+}
@@ -0,0 +1,161 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+// dnsFormats lists the accepted --format values of the dns command.
+var dnsFormats = []string{"bind", "generic"}
+
+// dnsRecord is a forward record for an infrastructure address, and the data available to the --name template.
+type dnsRecord struct {
+	subnet.InfraAddr
+	Subnet string       // DNS label for the subnet, from its name or its prefix
+	Name   string       // name of the allocation, if any
+	CIDR   netip.Prefix // the subnet
+	VLAN   int          // VLAN ID of the allocation, if any
+}
+
+// Type returns the record type for the address, A or AAAA.
+func (r dnsRecord) Type() string {
+	if r.Addr.Is4() {
+		return "A"
+	}
+	return "AAAA"
+}
+
+// dnsSubnets returns the subnets named by args: the allocated prefixes of a plan file, or the CIDRs given.
+func dnsSubnets(args []string) []subnet.Allocation {
+	if _, err := netip.ParsePrefix(args[0]); err != nil && len(args) == 1 {
+		var allocations []subnet.Allocation
+		for _, a := range loadPlan(args[0]).Allocations {
+			if a.Status == "" || a.Status == subnet.StatusAllocated {
+				allocations = append(allocations, a)
+			}
+		}
+		return allocations
+	}
+	prefixes, err := parsePrefixArgs(args)
+	if err != nil {
+		utils.Log.Fatal().Msg(err.Error())
+	}
+	allocations := make([]subnet.Allocation, len(prefixes))
+	for i, p := range prefixes {
+		allocations[i] = subnet.Allocation{CIDR: p}
+	}
+	return allocations
+}
+
+// dnsCmd represents the dns command
+var dnsCmd = &cobra.Command{
+	Use:   "dns <plan.json | CIDR...>",
+	Short: "generate forward DNS records for gateways and reserved addresses",
+	Long: `Generate forward A and AAAA records for the gateway and reserved infrastructure addresses of every allocated subnet in
+a plan file, or of the CIDRs given, complementing the reverse zones calculated by 'reverse'.
+
+The gateway is the first or last usable address, following --gateway or the profile's gateway convention. --reserved
+addresses after it, counting up from a first-address gateway or down from a last-address one, are named too.
+
+Record names are produced by the --name text/template, relative to --zone, with these fields:
+  .Subnet  the allocation's name as a DNS label, or the prefix, such as 10-0-4-0-24, if it has no name
+  .Role    gw for the gateway, infra for reserved addresses
+  .Index   position of a reserved address, counting from 1, and 0 for the gateway
+  .Addr, .CIDR, .Name, .VLAN
+
+--format bind writes a zone file fragment with $ORIGIN and $TTL directives. --format generic writes one record per
+line with fully qualified names, for tools that import records without zone file directives.
+
+Examples:
+  # Name the gateway and two infrastructure addresses of every subnet in a plan:
+  subnetCalc dns plan.json --zone corp.example.com --reserved 2
+
+  # Use last-address gateways named after the VLAN:
+  subnetCalc dns plan.json --zone corp.example.com --gateway last --name 'vlan{{.VLAN}}-{{.Role}}{{if .Index}}{{.Index}}{{end}}'
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := cmd.Flags()
+		zone, _ := flags.GetString("zone")
+		nameTemplate, _ := flags.GetString("name")
+		gateway, _ := flags.GetString("gateway")
+		reserved, _ := flags.GetInt("reserved")
+		format, _ := flags.GetString("format")
+		ttl, _ := flags.GetInt("ttl")
+		if !flags.Changed("gateway") && profile.Gateway != "" {
+			gateway = profile.Gateway
+		}
+		if !slices.Contains(dnsFormats, format) {
+			utils.Log.Fatal().Msgf("invalid format %q, expected one of: %s", format, strings.Join(dnsFormats, ", "))
+		}
+		if reserved < 0 {
+			utils.Log.Fatal().Msgf("reserved must not be negative, got %d", reserved)
+		}
+		zone = strings.TrimSuffix(zone, ".")
+		tmpl, err := template.New("name").Option("missingkey=error").Parse(nameTemplate)
+		if err != nil {
+			utils.Log.Fatal().Msgf("invalid name template: %v", err)
+		}
+
+		if format == "bind" {
+			if zone != "" {
+				fmt.Printf("$ORIGIN %s.\n", zone)
+			}
+			fmt.Printf("$TTL %d\n", ttl)
+		}
+		for _, a := range dnsSubnets(args) {
+			addrs, err := subnet.InfraAddrs(a.CIDR, gateway, reserved)
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			if len(addrs) == 0 {
+				continue
+			}
+			label := subnet.DNSLabel(a.Name)
+			if label == "" {
+				label = subnet.PrefixLabel(a.CIDR)
+			}
+			if format == "bind" {
+				fmt.Println(strings.TrimRight(fmt.Sprintf("; %v %s", a.CIDR, a.Name), " "))
+			}
+			for _, addr := range addrs {
+				r := dnsRecord{InfraAddr: addr, Subnet: label, Name: a.Name, CIDR: a.CIDR, VLAN: a.VLAN}
+				var owner strings.Builder
+				if err := tmpl.Execute(&owner, r); err != nil {
+					utils.Log.Fatal().Msg(err.Error())
+				}
+				name := owner.String()
+				if format == "generic" {
+					if zone != "" {
+						name += "." + zone
+					}
+					fmt.Printf("%s.\t%d\tIN\t%s\t%v\n", name, ttl, r.Type(), r.Addr)
+				} else {
+					fmt.Printf("%s\tIN\t%s\t%v\n", name, r.Type(), r.Addr)
+				}
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dnsCmd)
+	dnsCmd.Flags().StringP("zone", "z", "", "zone the records belong to, such as corp.example.com")
+	dnsCmd.Flags().StringP("name", "n", "{{.Role}}{{if .Index}}{{.Index}}{{end}}.{{.Subnet}}", "text/template producing each record's name, relative to --zone")
+	dnsCmd.Flags().String("gateway", "first", "gateway convention: "+strings.Join(subnet.GatewayConventions, ", "))
+	dnsCmd.Flags().Int("reserved", 0, "number of infrastructure addresses reserved beside the gateway to name")
+	dnsCmd.Flags().StringP("format", "f", "bind", "output format: "+strings.Join(dnsFormats, ", "))
+	dnsCmd.Flags().Int("ttl", 3600, "time to live of the records, in seconds")
+	dnsCmd.RegisterFlagCompletionFunc("gateway", completeList(subnet.GatewayConventions, false))
+	dnsCmd.RegisterFlagCompletionFunc("format", completeList(dnsFormats, false))
+}
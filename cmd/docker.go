@@ -0,0 +1,163 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// containerNetwork is the subset of `docker network inspect` and `podman network inspect` output used for planning.
+// docker uses capitalized keys with subnets nested under IPAM, podman uses lowercase keys with a top-level subnet list.
+type containerNetwork struct {
+	Name   string `json:"name"`
+	Driver string `json:"driver"`
+	IPAM   struct {
+		Config []struct {
+			Subnet string `json:"subnet"`
+		} `json:"config"`
+	} `json:"ipam"`
+	PodmanSubnets []struct {
+		Subnet string `json:"subnet"`
+	} `json:"subnets"`
+}
+
+// subnets returns every subnet assigned to the network.
+func (c containerNetwork) subnets() []netip.Prefix {
+	var cidrs []string
+	for _, cfg := range c.IPAM.Config {
+		cidrs = append(cidrs, cfg.Subnet)
+	}
+	for _, s := range c.PodmanSubnets {
+		cidrs = append(cidrs, s.Subnet)
+	}
+
+	var prefixes []netip.Prefix
+	for _, cidr := range cidrs {
+		p, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			utils.Module("docker").Debug().Msgf("skipping subnet %q of network %s: %v", cidr, c.Name, err)
+			continue
+		}
+		prefixes = append(prefixes, p.Masked())
+	}
+	return prefixes
+}
+
+// parseContainerNetworks decodes a JSON array of networks, or a stream of JSON network objects as produced by
+// `docker network ls --format json`.
+func parseContainerNetworks(r io.Reader) ([]containerNetwork, error) {
+	var networks []containerNetwork
+	dec := json.NewDecoder(r)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); errors.Is(err, io.EOF) {
+			return networks, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		var batch []containerNetwork
+		if err := json.Unmarshal(raw, &batch); err != nil {
+			var single containerNetwork
+			if err := json.Unmarshal(raw, &single); err != nil {
+				return nil, err
+			}
+			batch = []containerNetwork{single}
+		}
+		networks = append(networks, batch...)
+	}
+}
+
+var containerPool string
+var containerSize int
+
+// dockerCmd represents the docker command
+var dockerCmd = &cobra.Command{
+	Use:     "docker",
+	Aliases: []string{"podman"},
+	Short:   "propose the next free subnet for a docker or podman network",
+	Long: `Read 'docker network inspect' or 'podman network inspect' JSON from a file or stdin, list the subnets already used by
+existing networks, and propose the next non-conflicting subnet in the preferred pool.
+
+Examples:
+  # Propose a /24 for a new network from the default pool:
+  docker network inspect $(docker network ls -q) | subnetCalc docker
+
+  # Propose a /20 from a custom pool using saved podman output:
+  subnetCalc podman --file networks.json --pool 10.200.0.0/16 --size 20
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		pool, err := netip.ParsePrefix(containerPool)
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		file, _ := cmd.Flags().GetString("file")
+		f, err := openInput(file)
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		defer f.Close()
+		networks, err := parseContainerNetworks(f)
+		if err != nil {
+			utils.Log.Fatal().Msgf("unable to parse network JSON: %v", err)
+		}
+
+		var used []netip.Prefix
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.SetStyle(table.StyleRounded)
+		t.AppendHeader(table.Row{"NETWORK", "DRIVER", "SUBNET"})
+		for _, n := range networks {
+			for _, s := range n.subnets() {
+				used = append(used, s)
+				t.AppendRow(table.Row{n.Name, n.Driver, s})
+			}
+		}
+
+		next, ok := subnet.NextFree(pool, containerSize, used)
+		if cmd.Flags().Changed("json") {
+			proposal := struct {
+				Used []netip.Prefix `json:"used"`
+				Next *netip.Prefix  `json:"next"`
+			}{Used: used}
+			if ok {
+				proposal.Next = &next
+			}
+			out, err := json.MarshalIndent(proposal, "", "  ")
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			fmt.Println(string(out))
+		} else {
+			fmt.Printf("\n  %d existing networks use %d subnets:\n", len(networks), len(used))
+			t.Render()
+			if ok {
+				fmt.Printf("\n  Next available /%d in %v: %v\n", containerSize, pool, next)
+				fmt.Printf("    docker network create --subnet %v <name>\n", next)
+			}
+		}
+		if !ok {
+			utils.Log.Fatal().Msgf("no free /%d left in %v", containerSize, pool)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dockerCmd)
+	dockerCmd.Flags().StringP("file", "f", "", "file containing network inspect JSON, - for stdin")
+	dockerCmd.Flags().StringVarP(&containerPool, "pool", "p", "172.16.0.0/12", "preferred supernet to allocate the new network from")
+	dockerCmd.Flags().IntVarP(&containerSize, "size", "s", 24, "mask bits of the new network")
+	dockerCmd.Flags().BoolP("json", "j", false, "output the used subnets and proposal in json format")
+}
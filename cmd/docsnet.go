@@ -0,0 +1,123 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+// checkDocumentation warns about every address in the named files, or stdin, that isn't reserved for documentation.
+// returns the number of addresses warned about.
+func checkDocumentation(names []string) int {
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+	found := 0
+	for _, name := range names {
+		f, err := openInput(name)
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		if name == "-" {
+			name = "stdin"
+		}
+		scanner := bufio.NewScanner(f)
+		for line := 1; scanner.Scan(); line++ {
+			for _, p := range subnet.FindPrefixes(scanner.Text()) {
+				if !subnet.IsDocumentation(p.Masked()) {
+					fmt.Fprintf(os.Stderr, "warning: %s:%d: %v is not a documentation address (%s)\n", name, line, p, subnet.Classify(p.Masked()))
+					found++
+				}
+			}
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			utils.Log.Fatal().Msgf("%s: %v", name, err)
+		}
+	}
+	return found
+}
+
+// docsNetCmd represents the docs-net command
+var docsNetCmd = &cobra.Command{
+	Use:   "docs-net",
+	Short: "hand out documentation prefixes and MAC addresses",
+	Long: `Print prefixes reserved for documentation (RFC 5737 for IPv4, RFC 3849 and RFC 9637 for IPv6) and MAC addresses
+reserved for documentation (RFC 7042), for use in docs, examples, and tests.
+
+With --check, scan text files, or stdin, for addresses that are not reserved for documentation and warn about each
+one, so real addressing isn't pasted into documentation by accident. Exits non-zero if any are found.
+
+Examples:
+  # Three IPv4 documentation networks:
+  subnetCalc docs-net --v4 --count 3
+
+  # Four /64 IPv6 documentation networks and two example MAC addresses:
+  subnetCalc docs-net --v6 --size 64 --count 4 --mac
+
+  # Check a document before publishing it:
+  subnetCalc docs-net --check README.md
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if check, _ := cmd.Flags().GetBool("check"); check {
+			if checkDocumentation(args) > 0 {
+				os.Exit(1)
+			}
+			return
+		}
+
+		count, _ := cmd.Flags().GetInt("count")
+		v4, _ := cmd.Flags().GetBool("v4")
+		v6, _ := cmd.Flags().GetBool("v6")
+		mac, _ := cmd.Flags().GetBool("mac")
+		if !v4 && !v6 && !mac {
+			v4 = true
+		}
+		for _, family := range []struct {
+			enabled     bool
+			ipv6        bool
+			defaultBits int
+		}{{v4, false, 24}, {v6, true, 48}} {
+			if !family.enabled {
+				continue
+			}
+			bits := family.defaultBits
+			if cmd.Flags().Changed("size") {
+				bits, _ = cmd.Flags().GetInt("size")
+			}
+			prefixes, err := subnet.DocumentationNetworks(family.ipv6, bits, count)
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			for _, p := range prefixes {
+				fmt.Println(p)
+			}
+		}
+		if mac {
+			macs, err := subnet.DocumentationMACs(count)
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			for _, m := range macs {
+				fmt.Println(m)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(docsNetCmd)
+	docsNetCmd.Flags().Bool("v4", false, "print IPv4 documentation prefixes, the default")
+	docsNetCmd.Flags().Bool("v6", false, "print IPv6 documentation prefixes")
+	docsNetCmd.Flags().Bool("mac", false, "print documentation MAC addresses")
+	docsNetCmd.Flags().IntP("count", "n", 1, "number of prefixes or MAC addresses to print")
+	docsNetCmd.Flags().IntP("size", "s", 0, "mask bits of the prefixes, defaults to 24 for IPv4 and 48 for IPv6")
+	docsNetCmd.Flags().Bool("check", false, "warn about addresses in the files given, or stdin, that aren't reserved for documentation")
+}
@@ -0,0 +1,80 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/exporter"
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+var exportFormat string
+var exportOptions exporter.Options
+
+// exportFormats returns the names of the registered exporters in sorted order.
+func exportFormats() []string {
+	var names []string
+	for name := range exporter.Exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export <plan.json>",
+	Short: "render a plan as infrastructure as code",
+	Long: `Render the allocations in a plan file as configuration for other tools.
+
+Formats:
+  cloudformation  AWS::EC2::Subnet resources in a CloudFormation YAML template
+  bicep           an Azure Bicep subnet array deployed into an existing VNet
+  infoblox        CSV for Infoblox bulk network import, with labels as extensible attributes
+  list            a plain text prefix list with names, labels, and notes, as read by 'import list'
+
+Examples:
+  # Render a plan as CloudFormation, referencing the VPC through a parameter named AppVpc:
+  subnetCalc export plan.json --format cloudformation --network-ref AppVpc
+
+  # Keep deprecated subnets in the template until they are freed:
+  subnetCalc export plan.json --format cloudformation --include-reserved
+
+  # Render a plan as a Bicep subnet array:
+  subnetCalc export plan.json --format bicep > subnets.bicep
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		exportFn, ok := exporter.Exporters[exportFormat]
+		if !ok {
+			utils.Log.Fatal().Msgf("unknown format %q, expected one of: %s", exportFormat, strings.Join(exportFormats(), ", "))
+		}
+		p, err := subnet.LoadPlan(args[0])
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		if err := exportFn(os.Stdout, p, exportOptions); err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "", "output format: "+strings.Join(exportFormats(), ", "))
+	exportCmd.Flags().StringVar(&exportOptions.NetworkRef, "network-ref", "", "name of the parameter referencing the VPC or VNet")
+	exportCmd.Flags().BoolVar(&exportOptions.IncludeReserved, "include-reserved", false, "also deploy reserved and deprecated allocations in cloudformation and bicep")
+	exportCmd.Flags().BoolVar(&exportOptions.ExpandIPv6, "expand-ipv6", false, "write IPv6 addresses in fully expanded form")
+	if err := exportCmd.MarkFlagRequired("format"); err != nil {
+		utils.Log.Fatal().Msg(err.Error())
+	}
+	if err := exportCmd.RegisterFlagCompletionFunc("format", completeList(exportFormats(), false)); err != nil {
+		utils.Log.Fatal().Msg(err.Error())
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	doc := `{"supernets": ["10.0.0.0/16"], "allocations": [
+		{"id": "a1", "cidr": "10.0.1.0/24", "name": "web"},
+		{"id": "a2", "cidr": "10.0.2.0/23", "name": "web", "status": "reserved"}
+	]}`
+	if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		notWant []string
+	}{
+		{"cloudformation", []string{"--format", "cloudformation", "--network-ref", "app-vpc"},
+			[]string{"  AppVpc:\n    Type: AWS::EC2::VPC::Id\n", "  WebSubnet:\n", "VpcId: !Ref AppVpc\n", "CidrBlock: 10.0.1.0/24\n"},
+			[]string{"10.0.2.0/23"}},
+		{"bicep", []string{"--format", "bicep", "--network-ref", "hub"},
+			[]string{"param hub string\n", "addressPrefix: '10.0.1.0/24'"}, []string{"10.0.2.0/23"}},
+		{"include reserved", []string{"-f", "cloudformation", "--include-reserved"},
+			[]string{"CidrBlock: 10.0.1.0/24\n", "CidrBlock: 10.0.2.0/23\n"}, nil},
+	}
+	for _, tt := range tests {
+		stdout, stderr, code := runCLI(t, append([]string{"export", path}, tt.args...)...)
+		if code != 0 {
+			t.Errorf("%s: export exited %d: %s", tt.name, code, stderr)
+			continue
+		}
+		for _, s := range tt.want {
+			if !strings.Contains(stdout, s) {
+				t.Errorf("%s: export printed:\n%s\nwant it to contain %q", tt.name, stdout, s)
+			}
+		}
+		for _, s := range tt.notWant {
+			if strings.Contains(stdout, s) {
+				t.Errorf("%s: export printed:\n%s\nwant it not to contain %q", tt.name, stdout, s)
+			}
+		}
+	}
+
+	for _, args := range [][]string{
+		{"export", path},
+		{"export", path, "--format", "terraform"},
+		{"export", filepath.Join(t.TempDir(), "missing.json"), "--format", "bicep"},
+	} {
+		if _, _, code := runCLI(t, args...); code == 0 {
+			t.Errorf("%v exited 0, want an error", args)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// fitsCmd represents the fits command
+var fitsCmd = &cobra.Command{
+	Use:   "fits <CIDR>",
+	Short: "list how many subnets of each size fit in a network",
+	Long: `List the capacity of a network for every prefix length from its own down to --max-bits: how many subnets of that
+length fit, and how many usable hosts each of them and all of them together hold. Handy when choosing a split size.
+
+--max-bits defaults to /32 for IPv4 networks and /64, the smallest subnet SLAAC works in, for IPv6 networks.
+
+Examples:
+  # What can a /20 be split into?
+  subnetCalc fits 10.0.0.0/20
+
+  # Only consider subnets down to a /26:
+  subnetCalc fits 10.0.0.0/20 --max-bits 26 --json
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		n, err := subnet.NewNetwork(args[0])
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		maxBits, _ := cmd.Flags().GetInt("max-bits")
+		if !cmd.Flags().Changed("max-bits") {
+			maxBits = 32
+			if n.NetworkAddr.Is6() {
+				maxBits = max(64, n.MaskBits)
+			}
+		}
+		if maxBits < n.MaskBits || maxBits > n.MaskSize {
+			utils.Log.Fatal().Msgf("max-bits must be between %d and %d, got %d", n.MaskBits, n.MaskSize, maxBits)
+		}
+		fits := n.Fits(maxBits)
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			printJSON(fits)
+			return
+		}
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.SetStyle(table.StyleRounded)
+		header := table.Row{"PREFIX", "SUBNETS", "HOSTS EACH", "TOTAL HOSTS"}
+		if n.NetworkAddr.Is4() {
+			header = append(table.Row{header[0], "MASK"}, header[1:]...)
+		}
+		t.AppendHeader(header)
+		for _, f := range fits {
+			row := table.Row{fmt.Sprintf("/%d", f.Bits), formatBigInt(f.Subnets), formatBigInt(f.Hosts), formatBigInt(f.TotalHosts)}
+			if n.NetworkAddr.Is4() {
+				row = append(table.Row{row[0], f.SubnetMask}, row[1:]...)
+			}
+			t.AppendRow(row)
+		}
+		t.Render()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fitsCmd)
+	fitsCmd.Flags().IntP("max-bits", "m", 0, "longest prefix length to list, /32 for IPv4 and /64 for IPv6 by default")
+	fitsCmd.Flags().BoolP("json", "j", false, "output the capacity table in json format")
+}
@@ -0,0 +1,131 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// freeReport is the free space left in a supernet after the used prefixes are removed.
+type freeReport struct {
+	Supernet netip.Prefix      `json:"supernet"`
+	Free     []netip.Prefix    `json:"free"`
+	Largest  *netip.Prefix     `json:"largest"`
+	Fits     map[string]string `json:"fits"`
+}
+
+// fitSizes returns the prefix lengths to count in a free-space report: the requested sizes, or the size of the
+// largest free block and the eight sizes below it.
+func fitSizes(requested []int, largest netip.Prefix) []int {
+	if len(requested) > 0 || !largest.IsValid() {
+		return requested
+	}
+	var sizes []int
+	for bits := largest.Bits(); bits <= largest.Bits()+8 && bits <= largest.Addr().BitLen(); bits++ {
+		sizes = append(sizes, bits)
+	}
+	return sizes
+}
+
+// freeCmd represents the free command
+var freeCmd = &cobra.Command{
+	Use:   "free <CIDR>",
+	Short: "list the free space left in a partially allocated supernet",
+	Long: `List the free blocks left in a supernet once the prefixes already in use are removed, the largest contiguous free
+block, and how many prefixes of each size still fit.
+
+Used prefixes are read one per line from --used, or stdin when --used is -. Blank lines and anything after a # are
+ignored.
+
+Examples:
+  # Report the free space in a /16:
+  subnetCalc free 10.0.0.0/16 --used used.txt
+
+  # Count how many /24 and /28 prefixes still fit:
+  subnetCalc free 10.0.0.0/16 --used used.txt --sizes 24,28
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		supernet, err := netip.ParsePrefix(args[0])
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		supernet = supernet.Masked()
+
+		var used []netip.Prefix
+		if file, _ := cmd.Flags().GetString("used"); file != "" {
+			f, err := openInput(file)
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			used, err = readPrefixes(f)
+			f.Close()
+			if err != nil {
+				utils.Log.Fatal().Msgf("%s: %v", file, err)
+			}
+		}
+
+		report := freeReport{Supernet: supernet, Free: subnet.Exclude(supernet, used), Fits: map[string]string{}}
+		largest, ok := subnet.Largest(report.Free)
+		if ok {
+			report.Largest = &largest
+		}
+		requested, _ := cmd.Flags().GetIntSlice("sizes")
+		sizes := fitSizes(requested, largest)
+		for _, bits := range sizes {
+			report.Fits[fmt.Sprintf("/%d", bits)] = subnet.FitCount(report.Free, bits).String()
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			printJSON(report)
+			return
+		}
+
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.SetStyle(table.StyleRounded)
+		t.AppendHeader(table.Row{"FREE BLOCK", "ADDRESSES"})
+		for _, f := range report.Free {
+			t.AppendRow(table.Row{f, formatBigInt(subnet.AddressCount(f))})
+		}
+		fmt.Printf("\n  %v has %d free blocks:\n", supernet, len(report.Free))
+		t.Render()
+		if !ok {
+			return
+		}
+		fmt.Printf("\n  Largest free block: %v\n\n", largest)
+		fits := table.NewWriter()
+		fits.SetOutputMirror(os.Stdout)
+		fits.SetStyle(table.StyleRounded)
+		fits.AppendHeader(table.Row{"SIZE", "STILL FIT"})
+		for _, bits := range sizes {
+			fits.AppendRow(table.Row{fmt.Sprintf("/%d", bits), formatBigInt(subnet.FitCount(report.Free, bits))})
+		}
+		fits.Render()
+	},
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v any) {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		utils.Log.Fatal().Msg(err.Error())
+	}
+	fmt.Println(string(out))
+}
+
+func init() {
+	rootCmd.AddCommand(freeCmd)
+	freeCmd.Flags().StringP("used", "u", "", "file listing the prefixes in use, one per line, - for stdin")
+	freeCmd.Flags().IntSlice("sizes", nil, "prefix lengths to count, defaults to the largest free block and the eight sizes below it")
+	freeCmd.Flags().BoolP("json", "j", false, "output the report in json format")
+}
@@ -0,0 +1,118 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+)
+
+// defaultHookTimeout is how long a hook may run when its timeout isn't set.
+const defaultHookTimeout = 10 * time.Second
+
+// Hook is run after a command changes a plan file. Exactly one of Exec and URL is set.
+type Hook struct {
+	Exec    string `json:"exec,omitempty"`    // shell command, given the change on stdin
+	URL     string `json:"url,omitempty"`     // endpoint the change is POSTed to
+	Timeout string `json:"timeout,omitempty"` // how long to wait for the hook, such as 5s, defaults to 10s
+}
+
+// PlanChange is the payload given to hooks: the plan file changed, the command that changed it, and the changes.
+type PlanChange struct {
+	Plan    string   `json:"plan"`
+	Command []string `json:"command"`
+	subnet.PlanDiff
+}
+
+var noHooks, hookDryRun bool
+
+// validate checks that the hook has exactly one action and a valid timeout.
+func (h Hook) validate() error {
+	if (h.Exec == "") == (h.URL == "") {
+		return fmt.Errorf("hook must set exactly one of exec and url")
+	}
+	if h.Timeout != "" {
+		if _, err := time.ParseDuration(h.Timeout); err != nil {
+			return fmt.Errorf("hook timeout: %w", err)
+		}
+	}
+	return nil
+}
+
+// String returns the action the hook takes.
+func (h Hook) String() string {
+	if h.Exec != "" {
+		return "exec " + h.Exec
+	}
+	return "POST " + h.URL
+}
+
+// run passes payload to the hook, waiting at most the hook's timeout.
+func (h Hook) run(payload []byte, planPath string) error {
+	timeout := defaultHookTimeout
+	if h.Timeout != "" {
+		timeout, _ = time.ParseDuration(h.Timeout)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if h.Exec != "" {
+		shell, flag := "sh", "-c"
+		if runtime.GOOS == "windows" {
+			shell, flag = "cmd", "/C"
+		}
+		c := exec.CommandContext(ctx, shell, flag, h.Exec)
+		c.Stdin = bytes.NewReader(payload)
+		c.Stdout, c.Stderr = os.Stderr, os.Stderr
+		c.Env = append(os.Environ(), "SUBNETCALC_PLAN="+planPath)
+		return c.Run()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", h.URL, resp.Status)
+	}
+	return nil
+}
+
+// runHooks runs the profile's hooks for a change to the plan at path, unless nothing changed. Hook failures are only
+// warned about, as the plan has already been saved.
+func runHooks(path string, diff subnet.PlanDiff) {
+	if len(profile.Hooks) == 0 || noHooks || diff.Empty() {
+		return
+	}
+	payload, err := json.Marshal(PlanChange{Plan: path, Command: os.Args, PlanDiff: diff})
+	if err != nil {
+		utils.Log.Fatal().Msg(err.Error())
+	}
+	for _, h := range profile.Hooks {
+		if hookDryRun {
+			fmt.Fprintf(os.Stderr, "dry run: would %s with %s\n", h, payload)
+			continue
+		}
+		utils.Module("hook").Debug().Msgf("running %s", h)
+		if err := h.run(payload, path); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: hook %s failed: %v\n", h, err)
+		}
+	}
+}
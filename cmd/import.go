@@ -0,0 +1,93 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/importer"
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+// importProviders returns the names of the registered importers in sorted order.
+func importProviders() []string {
+	var names []string
+	for name := range importer.Importers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import <provider> <file>...",
+	Short: "import cloud provider network exports into a plan",
+	Long: `Convert the JSON network inventory exported by a cloud provider CLI into a subnetCalc plan. No API calls are made, the
+files are produced by running the provider's CLI yourself. Hand-maintained prefix lists can be imported too, keeping
+the names and comments written beside each prefix. Use - to read a file from stdin.
+
+Providers:
+  aws    output of 'aws ec2 describe-vpcs' and/or 'aws ec2 describe-subnets'
+  azure  output of 'az network vnet show' or 'az network vnet list'
+  gcp    output of 'gcloud compute networks subnets list --format=json'
+  list   a plain text list of prefixes, one per line, followed by an optional name, key=value labels, and a # note
+
+Examples:
+  # Import a VPC and its subnets into a plan file:
+  aws ec2 describe-vpcs > vpcs.json
+  aws ec2 describe-subnets > subnets.json
+  subnetCalc import aws vpcs.json subnets.json --output plan.json
+
+  # Import a hand-maintained list such as "10.0.1.0/24 web env=prod # frontend servers":
+  subnetCalc import list allocations.txt --output plan.json
+
+  # Import GCP subnets from stdin:
+  gcloud compute networks subnets list --format=json | subnetCalc import gcp -
+`,
+	Args:      cobra.MinimumNArgs(2),
+	ValidArgs: importProviders(),
+	Run: func(cmd *cobra.Command, args []string) {
+		importFn, ok := importer.Importers[args[0]]
+		if !ok {
+			utils.Log.Fatal().Msgf("unknown provider %q, expected one of: %s", args[0], strings.Join(importProviders(), ", "))
+		}
+
+		var p subnet.Plan
+		for _, name := range args[1:] {
+			f, err := openInput(name)
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			err = importFn(f, &p)
+			f.Close()
+			if err != nil {
+				utils.Log.Fatal().Msgf("%s: %v", name, err)
+			}
+		}
+
+		p.AssignIDs()
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			out, err := json.MarshalIndent(p, "", "  ")
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			fmt.Println(string(out))
+			return
+		}
+		savePlan(p, output)
+		utils.Module("import").Info().Msgf("imported %d supernets and %d allocations into %s", len(p.Supernets), len(p.Allocations), output)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringP("output", "o", "", "write the plan to a file instead of stdout")
+}
@@ -0,0 +1,73 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/formatter"
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+var inferFormat string
+
+// inferCmd represents the infer command
+var inferCmd = &cobra.Command{
+	Use:   "infer <address> <address>...",
+	Short: "infer the smallest network containing observed addresses",
+	Long: `Find the smallest prefix containing every address given and show its details, to reverse-engineer an undocumented
+network from the hosts seen on it. The real network may be larger: a warning is printed if an address is the network
+or broadcast address of the inferred prefix, which no host can have, as the network must then be at least twice the
+size.
+
+Examples:
+  # Which network do these two hosts share?
+  subnetCalc infer 10.0.4.1 10.0.7.254
+
+  # Infer a prefix from the addresses in an ARP cache dump:
+  subnetCalc infer $(awk '{print $1}' arp.txt) --format json
+`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		addrs := make([]netip.Addr, len(args))
+		for i, arg := range args {
+			a, err := netip.ParseAddr(arg)
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			addrs[i] = a.Unmap()
+		}
+		p, err := subnet.InferPrefix(addrs)
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		n := subnet.NewNetworkFromPrefix(p)
+		for _, a := range addrs {
+			if n.HasBroadcast() && (a == n.NetworkAddr || a == n.BroadcastAddr) {
+				fmt.Fprintf(os.Stderr, "warning: %v is the network or broadcast address of %v, so the network is larger\n", a, p)
+			}
+		}
+
+		fm, err := newFormatter(cmd, inferFormat)
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		if err := fm.Format(os.Stdout, n); err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(inferCmd)
+	inferCmd.Flags().StringVarP(&inferFormat, "format", "f", "table", "output format: "+strings.Join(formatter.Formats, ", "))
+	if err := inferCmd.RegisterFlagCompletionFunc("format", completeList(formatter.Formats, false)); err != nil {
+		utils.Log.Fatal().Msg(err.Error())
+	}
+}
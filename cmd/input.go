@@ -0,0 +1,34 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"io"
+	"net/netip"
+	"os"
+
+	"github.com/JakeTRogers/subnetCalc/importer"
+)
+
+// openInput opens the named file for reading. An empty name or "-" reads from stdin.
+func openInput(name string) (io.ReadCloser, error) {
+	if name == "" || name == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(name)
+}
+
+// readPrefixes reads one prefix per line from r, as read by importer.ReadList, dropping the names, labels, and notes
+// written alongside them. Bare addresses are read as single host prefixes.
+func readPrefixes(r io.Reader) ([]netip.Prefix, error) {
+	allocations, err := importer.ReadList(r)
+	if err != nil {
+		return nil, err
+	}
+	prefixes := make([]netip.Prefix, len(allocations))
+	for i, a := range allocations {
+		prefixes[i] = a.CIDR
+	}
+	return prefixes, nil
+}
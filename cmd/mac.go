@@ -0,0 +1,121 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+// printMACInfo writes the details of a MAC address as a list of labelled values.
+func printMACInfo(m subnet.MACInfo) {
+	vendor := m.Vendor
+	switch {
+	case m.Local:
+		vendor = "none, locally administered"
+	case vendor == "":
+		vendor = "unknown, use --oui-file to search the full IEEE registry"
+	}
+	kind := "unicast"
+	if m.Multicast {
+		kind = "multicast"
+	}
+	rows := [][2]string{
+		{"MAC", m.MAC},
+		{"Other Forms", fmt.Sprintf("%s  %s  %s", m.Hyphens, m.Dots, m.Bare)},
+		{"OUI", m.OUI},
+		{"Vendor", vendor},
+		{"Type", kind},
+		{"EUI-64", m.EUI64},
+		{"Link-Local", m.LinkLocal.String()},
+	}
+	if m.SLAAC != nil {
+		rows = append(rows, [2]string{"SLAAC", m.SLAAC.String()})
+	}
+	for _, r := range rows {
+		fmt.Printf("%-12s %s\n", r[0]+":", r[1])
+	}
+}
+
+// macCmd represents the mac command
+var macCmd = &cobra.Command{
+	Use:   "mac <MAC>...",
+	Short: "look up a MAC address vendor and derive its EUI-64 addresses",
+	Long: `Validate MAC addresses, written with colons, dashes, Cisco style dots, or as bare hex digits, and show each one in
+every form, its vendor, whether it is multicast or locally administered, and the modified EUI-64 interface identifier
+and IPv6 link-local address a host with it configures. Give an IPv6 /64 with --prefix to also show its SLAAC address.
+
+Vendors are looked up in a selection of common network, server, and virtualization vendors embedded in subnetCalc. To
+search the whole registry, download oui.csv or oui.txt from the IEEE and pass it to --oui-file.
+
+Examples:
+  # Who made this NIC, and what is its link-local address?
+  subnetCalc mac 00:50:56:12:34:56
+
+  # Predict a host's SLAAC address:
+  subnetCalc mac 0050.5612.3456 --prefix 2001:db8:0:1::/64
+
+  # Look up the vendor in the full IEEE registry:
+  subnetCalc mac 3c:22:fb:01:02:03 --oui-file oui.csv
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		db := subnet.BuiltinOUIs()
+		if file, _ := cmd.Flags().GetString("oui-file"); file != "" {
+			f, err := openInput(file)
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			if db, err = subnet.ReadOUIs(f); err != nil {
+				utils.Log.Fatal().Msgf("%s: %v", file, err)
+			}
+			f.Close()
+		}
+		var prefix netip.Prefix
+		if s, _ := cmd.Flags().GetString("prefix"); s != "" {
+			var err error
+			if prefix, err = netip.ParsePrefix(s); err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+		}
+
+		infos := make([]subnet.MACInfo, len(args))
+		for i, arg := range args {
+			mac, err := subnet.ParseMAC(arg)
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			infos[i] = subnet.NewMACInfo(mac, db)
+			if prefix.IsValid() {
+				slaac, err := subnet.SLAACAddr(prefix, mac)
+				if err != nil {
+					utils.Log.Fatal().Msg(err.Error())
+				}
+				infos[i].SLAAC = &slaac
+			}
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			printJSON(infos)
+			return
+		}
+		for i, info := range infos {
+			if i > 0 {
+				fmt.Println()
+			}
+			printMACInfo(info)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(macCmd)
+	macCmd.Flags().String("oui-file", "", "IEEE oui.csv or oui.txt to look vendors up in instead of the built-in selection")
+	macCmd.Flags().StringP("prefix", "p", "", "IPv6 /64 to derive the SLAAC address in")
+	macCmd.Flags().BoolP("json", "j", false, "output the details in json format")
+}
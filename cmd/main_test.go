@@ -0,0 +1,44 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// argsEnv holds the arguments of a command run by runCLI, separated by argsSep. Commands exit the process on fatal
+// errors, so each one runs in a copy of the test binary started with this variable set.
+const argsEnv, argsSep = "SUBNETCALC_TEST_ARGS", "\x1f"
+
+func TestMain(m *testing.M) {
+	if args, ok := os.LookupEnv(argsEnv); ok {
+		rootCmd.SetArgs(strings.Split(args, argsSep))
+		Execute()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runCLI runs subnetCalc with args in a separate process, without the user's config file, and with "test" as the actor
+// recorded in plan histories.
+// returns the command's stdout, stderr, and exit code.
+func runCLI(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+	c := exec.Command(os.Args[0])
+	c.Env = append(os.Environ(), argsEnv+"="+strings.Join(args, argsSep), "XDG_CONFIG_HOME="+t.TempDir(),
+		"HOME="+t.TempDir(), "SUBNETCALC_ACTOR=test", "NO_COLOR=1")
+	var stdout, stderr bytes.Buffer
+	c.Stdout, c.Stderr = &stdout, &stderr
+	err := c.Run()
+	var exit *exec.ExitError
+	if err != nil && !errors.As(err, &exit) {
+		t.Fatalf("running %v: %v", args, err)
+	}
+	return stdout.String(), stderr.String(), c.ProcessState.ExitCode()
+}
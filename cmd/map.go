@@ -0,0 +1,203 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math/big"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/importer"
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+// mapSymbols are the characters used to mark each prefix in the ASCII chart, in input order.
+const mapSymbols = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// mapSymbol returns the chart character for the prefix at index i. Prefixes beyond the end of mapSymbols share #.
+func mapSymbol(i int) byte {
+	if i < len(mapSymbols) {
+		return mapSymbols[i]
+	}
+	return '#'
+}
+
+// addressMap holds the prefixes being charted and their position within the parent.
+type addressMap struct {
+	parent   netip.Prefix
+	size     *big.Int
+	prefixes []netip.Prefix
+	names    []string
+	offsets  []*big.Int
+}
+
+// newAddressMap returns a map of the allocations inside parent, labelled in the legend with their names and notes.
+// Allocations outside of it are skipped with a warning.
+func newAddressMap(parent netip.Prefix, allocations []subnet.Allocation) addressMap {
+	m := addressMap{parent: parent, size: subnet.AddressCount(parent)}
+	start := subnet.RangeOf(parent).From
+	for _, a := range allocations {
+		p := a.CIDR
+		if p.Addr().BitLen() != parent.Addr().BitLen() || !parent.Contains(p.Addr()) || p.Bits() < parent.Bits() {
+			fmt.Fprintf(os.Stderr, "warning: %v is not within %v and is not charted\n", p, parent)
+			continue
+		}
+		m.prefixes = append(m.prefixes, p)
+		m.names = append(m.names, strings.Join(slices.DeleteFunc([]string{a.Name, a.Note}, func(s string) bool { return s == "" }), " - "))
+		offset := new(big.Int).Sub(new(big.Int).SetBytes(p.Addr().AsSlice()), new(big.Int).SetBytes(start.AsSlice()))
+		m.offsets = append(m.offsets, offset)
+	}
+	return m
+}
+
+// cells returns which prefix covers each of count equally sized cells of the parent: the prefix index, -1 for free
+// cells, and -2 for cells shared by several prefixes. Prefixes smaller than a cell still mark the cell they fall in.
+func (m addressMap) cells(count int) []int {
+	cells := make([]int, count)
+	for i := range cells {
+		cells[i] = -1
+	}
+	c := big.NewInt(int64(count))
+	for i, p := range m.prefixes {
+		end := new(big.Int).Add(m.offsets[i], subnet.AddressCount(p))
+		first := new(big.Int).Quo(new(big.Int).Mul(m.offsets[i], c), m.size)
+		last := new(big.Int).Quo(new(big.Int).Sub(new(big.Int).Mul(end, c), big.NewInt(1)), m.size)
+		for j := first.Int64(); j <= last.Int64(); j++ {
+			if cells[j] == -1 || cells[j] == i {
+				cells[j] = i
+			} else {
+				cells[j] = -2
+			}
+		}
+	}
+	return cells
+}
+
+// writeASCII draws the parent as rows of width cells, each labelled with the address it starts at, followed by a
+// legend.
+func (m addressMap) writeASCII(w io.Writer, rows, width int) {
+	count := rows * width
+	if m.size.IsInt64() && m.size.Int64() < int64(count) {
+		count = int(m.size.Int64())
+	}
+	cells := m.cells(count)
+	start := new(big.Int).SetBytes(m.parent.Addr().AsSlice())
+
+	fmt.Fprintf(w, "\n  %v, each cell is %s addresses\n\n", m.parent, formatBigInt(new(big.Int).Quo(m.size, big.NewInt(int64(count)))))
+	for row := 0; row*width < count; row++ {
+		offset := new(big.Int).Quo(new(big.Int).Mul(m.size, big.NewInt(int64(row*width))), big.NewInt(int64(count)))
+		addr, _ := netip.AddrFromSlice(new(big.Int).Add(start, offset).FillBytes(make([]byte, m.parent.Addr().BitLen()/8)))
+		var line strings.Builder
+		for _, cell := range cells[row*width : min((row+1)*width, count)] {
+			switch cell {
+			case -1:
+				line.WriteByte('.')
+			case -2:
+				line.WriteByte('*')
+			default:
+				line.WriteByte(mapSymbol(cell))
+			}
+		}
+		fmt.Fprintf(w, "  %-15s |%s|\n", addr, line.String())
+	}
+
+	fmt.Fprintln(w, "\n  Legend: . free, * several prefixes")
+	for i, p := range m.prefixes {
+		fmt.Fprintln(w, strings.TrimRight(fmt.Sprintf("    %c %-18v %-8s %s", mapSymbol(i), p, formatPercent(subnet.AddressCount(p), m.size), m.names[i]), " "))
+	}
+}
+
+// formatPercent returns part as a percentage of whole.
+func formatPercent(part, whole *big.Int) string {
+	pct, _ := new(big.Float).Quo(new(big.Float).SetInt(part), new(big.Float).SetInt(whole)).Float64()
+	return fmt.Sprintf("%.4g%%", pct*100)
+}
+
+// writeSVG draws the parent as a single bar with a rectangle for each prefix, proportional to its size and position,
+// followed by a legend.
+func (m addressMap) writeSVG(w io.Writer) {
+	const barWidth, barHeight, lineHeight = 1000.0, 40.0, 18.0
+	height := barHeight + 30 + lineHeight*float64(len(m.prefixes))
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" font-family="monospace" font-size="12">`+"\n", barWidth+20, height)
+	fmt.Fprintf(w, `  <title>%s</title>`+"\n", html.EscapeString(m.parent.String()))
+	fmt.Fprintf(w, `  <rect x="10" y="10" width="%.0f" height="%.0f" fill="#eeeeee" stroke="#999999"/>`+"\n", barWidth, barHeight)
+	for i, p := range m.prefixes {
+		x, _ := new(big.Float).Quo(new(big.Float).SetInt(m.offsets[i]), new(big.Float).SetInt(m.size)).Float64()
+		width, _ := new(big.Float).Quo(new(big.Float).SetInt(subnet.AddressCount(p)), new(big.Float).SetInt(m.size)).Float64()
+		color := fmt.Sprintf("hsl(%d, 60%%, 50%%)", (i*137)%360)
+		// keep prefixes far smaller than a pixel visible
+		fmt.Fprintf(w, `  <rect x="%.3f" y="10" width="%.3f" height="%.0f" fill="%s"><title>%v</title></rect>`+"\n", 10+x*barWidth, max(width*barWidth, 0.5), barHeight, color, p)
+		y := barHeight + 30 + lineHeight*float64(i)
+		fmt.Fprintf(w, `  <rect x="10" y="%.0f" width="12" height="12" fill="%s"/>`+"\n", y-10, color)
+		fmt.Fprintf(w, `  <text x="28" y="%.0f">%v %s %s</text>`+"\n", y, p, html.EscapeString(formatPercent(subnet.AddressCount(p), m.size)), html.EscapeString(m.names[i]))
+	}
+	fmt.Fprintln(w, "</svg>")
+}
+
+// mapCmd represents the map command
+var mapCmd = &cobra.Command{
+	Use:   "map",
+	Short: "chart where prefixes fall within the address space",
+	Long: `Draw a proportional chart of where a list of prefixes falls within the whole IPv4 address space, or within the
+--parent prefix, to reveal clustering and fragmentation at a glance.
+
+Prefixes are read one per line from --file, or stdin, and any name or # comment beside a prefix is shown in the
+legend. The ASCII chart divides the parent into --rows rows of --width cells, each marked with the letter of the
+prefix covering it. Use --svg to draw an SVG image instead.
+
+Examples:
+  # Chart where a list of networks falls in the IPv4 address space:
+  subnetCalc map --file nets.txt
+
+  # Chart the fragmentation of a /16 as an SVG image:
+  subnetCalc map --file nets.txt --parent 10.20.0.0/16 --svg > map.svg
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("file")
+		f, err := openInput(file)
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		allocations, err := importer.ReadList(f)
+		f.Close()
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+
+		parentFlag, _ := cmd.Flags().GetString("parent")
+		parent, err := netip.ParsePrefix(parentFlag)
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		rows, _ := cmd.Flags().GetInt("rows")
+		width, _ := cmd.Flags().GetInt("width")
+		if rows < 1 || width < 1 {
+			utils.Log.Fatal().Msgf("rows and width must be positive, got %d and %d", rows, width)
+		}
+
+		m := newAddressMap(parent.Masked(), allocations)
+		if svg, _ := cmd.Flags().GetBool("svg"); svg {
+			m.writeSVG(os.Stdout)
+			return
+		}
+		m.writeASCII(os.Stdout, rows, width)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mapCmd)
+	mapCmd.Flags().StringP("file", "f", "", "file listing the prefixes to chart, one per line, - for stdin")
+	mapCmd.Flags().StringP("parent", "p", "0.0.0.0/0", "prefix to chart the prefixes within")
+	mapCmd.Flags().Int("rows", 16, "number of rows in the ASCII chart")
+	mapCmd.Flags().Int("width", 64, "number of cells in each row of the ASCII chart")
+	mapCmd.Flags().Bool("svg", false, "draw an SVG image instead of an ASCII chart")
+}
@@ -0,0 +1,120 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/csv"
+	"net/netip"
+	"os"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+// containmentMatrix holds the relation of every row prefix to every column prefix.
+type containmentMatrix struct {
+	Rows      []netip.Prefix `json:"rows"`
+	Columns   []netip.Prefix `json:"columns"`
+	Relations [][]string     `json:"relations"` // Relations[i][j] is how Rows[i] relates to Columns[j]
+}
+
+// newContainmentMatrix returns the relations between each of rows and each of cols.
+func newContainmentMatrix(rows, cols []netip.Prefix) containmentMatrix {
+	m := containmentMatrix{Rows: rows, Columns: cols, Relations: make([][]string, len(rows))}
+	for i, r := range rows {
+		m.Relations[i] = make([]string, len(cols))
+		for j, c := range cols {
+			m.Relations[i][j] = subnet.Relate(r, c)
+		}
+	}
+	return m
+}
+
+// readPrefixFile reads the prefixes listed in the named file, exiting on error.
+func readPrefixFile(name string) []netip.Prefix {
+	f, err := openInput(name)
+	if err != nil {
+		utils.Log.Fatal().Msg(err.Error())
+	}
+	defer f.Close()
+	prefixes, err := readPrefixes(f)
+	if err != nil {
+		utils.Log.Fatal().Msgf("%s: %v", name, err)
+	}
+	return prefixes
+}
+
+// matrixCmd represents the matrix command
+var matrixCmd = &cobra.Command{
+	Use:   "matrix",
+	Short: "compare two sets of prefixes pairwise",
+	Long: `Write a matrix of how every prefix in one list relates to every prefix in another, for example to audit firewall
+objects against IPAM allocations. Each cell is equal, contains (the row prefix contains the column prefix), within
+(the row prefix is inside the column prefix), or empty when they share no addresses.
+
+Prefixes are read one per line from --rows and --cols, one of which may be - for stdin. The matrix is written as CSV,
+or as JSON with --json.
+
+Examples:
+  # Check which IPAM allocations each firewall object covers:
+  subnetCalc matrix --rows firewall-objects.txt --cols allocations.txt
+
+  # Only list the pairs that overlap, one per line:
+  subnetCalc matrix --rows a.txt --cols b.txt --pairs
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		rowsFile, _ := cmd.Flags().GetString("rows")
+		colsFile, _ := cmd.Flags().GetString("cols")
+		if (rowsFile == "" || rowsFile == "-") && (colsFile == "" || colsFile == "-") {
+			utils.Log.Fatal().Msg("only one of --rows and --cols can be read from stdin")
+		}
+		m := newContainmentMatrix(readPrefixFile(rowsFile), readPrefixFile(colsFile))
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			printJSON(m)
+			return
+		}
+
+		w := csv.NewWriter(os.Stdout)
+		if pairs, _ := cmd.Flags().GetBool("pairs"); pairs {
+			w.Write([]string{"row", "column", "relation"})
+			for i, r := range m.Rows {
+				for j, c := range m.Columns {
+					if rel := m.Relations[i][j]; rel != subnet.RelationDisjoint {
+						w.Write([]string{r.String(), c.String(), rel})
+					}
+				}
+			}
+		} else {
+			header := []string{""}
+			for _, c := range m.Columns {
+				header = append(header, c.String())
+			}
+			w.Write(header)
+			for i, r := range m.Rows {
+				w.Write(append([]string{r.String()}, m.Relations[i]...))
+			}
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(matrixCmd)
+	matrixCmd.Flags().String("rows", "", "file listing the prefixes for the rows, one per line, - for stdin")
+	matrixCmd.Flags().String("cols", "", "file listing the prefixes for the columns, one per line, - for stdin")
+	matrixCmd.Flags().Bool("pairs", false, "list each related pair on its own line instead of writing the full matrix")
+	matrixCmd.Flags().BoolP("json", "j", false, "output the matrix in json format")
+	matrixCmd.MarkFlagsMutuallyExclusive("pairs", "json")
+	for _, name := range []string{"rows", "cols"} {
+		if err := matrixCmd.MarkFlagRequired(name); err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/importer"
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// defaultNeighborCommand returns the command that prints the neighbor cache on this platform.
+func defaultNeighborCommand() string {
+	switch runtime.GOOS {
+	case "linux":
+		return "ip neigh"
+	case "windows":
+		return "arp -a"
+	}
+	return "arp -an"
+}
+
+// readNeighborOutput runs command and reads the neighbor cache it prints.
+func readNeighborOutput(command string) ([]importer.Neighbor, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty neighbor command")
+	}
+	out, err := exec.Command(args[0], args[1:]...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", command, err)
+	}
+	return importer.ReadNeighbors(bytes.NewReader(out))
+}
+
+// inSupernet reports whether addr is inside one of the plan's supernets.
+func inSupernet(p subnet.Plan, addr netip.Addr) bool {
+	for _, s := range p.Supernets {
+		if s.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// neighborsCmd represents the neighbors command
+var neighborsCmd = &cobra.Command{
+	Use:   "neighbors [CIDR]",
+	Short: "list the addresses in the local ARP/NDP neighbor cache",
+	Long: `List the addresses in the local ARP/NDP neighbor cache, optionally only those within a network. The cache is read from
+the output of --from, 'ip neigh' on Linux and 'arp -an' or 'arp -a' elsewhere, or from a saved copy with --file.
+Nothing is sent on the network, so this is a cheap, passive complement to sweep.
+
+With --plan, every neighbor inside one of the plan's supernets but not in any allocation is added to the plan as an
+allocated host prefix labelled source=neighbor and with its MAC address, so the plan's utilization and free space
+reflect the hosts actually present.
+
+Examples:
+  # List the neighbors on the LAN:
+  subnetCalc neighbors 192.168.1.0/24
+
+  # Record them in a plan, reading the cache of another host:
+  ssh router ip neigh > neigh.txt
+  subnetCalc neighbors --file neigh.txt --plan plan.json
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := cmd.Flags()
+		from, _ := flags.GetString("from")
+		file, _ := flags.GetString("file")
+		planFile, _ := flags.GetString("plan")
+		dryRun, _ := flags.GetBool("dry-run")
+
+		var neighbors []importer.Neighbor
+		var err error
+		if flags.Changed("file") {
+			var f io.ReadCloser
+			if f, err = openInput(file); err == nil {
+				neighbors, err = importer.ReadNeighbors(f)
+				f.Close()
+			}
+		} else {
+			neighbors, err = readNeighborOutput(from)
+		}
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		if len(args) > 0 {
+			prefix, err := netip.ParsePrefix(args[0])
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			var within []importer.Neighbor
+			for _, n := range neighbors {
+				if prefix.Contains(n.Addr) {
+					within = append(within, n)
+				}
+			}
+			neighbors = within
+		}
+
+		if planFile == "" {
+			if asJSON, _ := flags.GetBool("json"); asJSON {
+				printJSON(neighbors)
+				return
+			}
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.SetStyle(table.StyleRounded)
+			t.AppendHeader(table.Row{"ADDRESS", "MAC", "INTERFACE", "STATE"})
+			for _, n := range neighbors {
+				t.AppendRow(table.Row{n.Addr, n.MAC, n.Interface, n.State})
+			}
+			t.Render()
+			return
+		}
+
+		p := loadPlan(planFile)
+		added := 0
+		for _, n := range neighbors {
+			host := netip.PrefixFrom(n.Addr, n.Addr.BitLen())
+			if existing, ok := p.Containing(host); ok {
+				fmt.Printf("%-15v %s, in use by %v %s\n", n.Addr, n.MAC, existing.CIDR, existing.Name)
+				continue
+			}
+			if !inSupernet(p, n.Addr) {
+				continue
+			}
+			a := subnet.Allocation{CIDR: host, Note: "found in the neighbor cache", Labels: map[string]string{"source": "neighbor", "mac": n.MAC}}
+			if err := p.Allocate(a); err != nil {
+				fmt.Printf("%-15v %s, not added: %v\n", n.Addr, n.MAC, err)
+				continue
+			}
+			fmt.Printf("%-15v %s, added\n", n.Addr, n.MAC)
+			added++
+		}
+		if added > 0 && !dryRun {
+			savePlan(p, planFile)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(neighborsCmd)
+	neighborsCmd.Flags().String("from", defaultNeighborCommand(), "command printing the neighbor cache")
+	neighborsCmd.Flags().StringP("file", "f", "", "read the neighbor cache from a file instead, - for stdin")
+	neighborsCmd.Flags().StringP("plan", "p", "", "add the neighbors to the allocations in this plan file")
+	neighborsCmd.Flags().Bool("dry-run", false, "with --plan, report the neighbors found without changing the plan")
+	neighborsCmd.Flags().BoolP("json", "j", false, "output the neighbors in json format")
+	neighborsCmd.MarkFlagFilename("plan", "json")
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/JakeTRogers/subnetCalc/formatter"
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// formatExtensions maps output formats to the extension of the files they are written to.
+var formatExtensions = map[string]string{
+	"table":    "txt",
+	"json":     "json",
+	"ndjson":   "ndjson",
+	"tsv":      "tsv",
+	"csv":      "csv",
+	"markdown": "md",
+	"summary":  "txt",
+	"msgpack":  "msgpack",
+	"pb":       "pb",
+	"prom":     "prom",
+	"inetnum":  "txt",
+	"template": "txt",
+}
+
+// fileName is the data available to the --file-name template.
+type fileName struct {
+	Index int    // 1-based position of the subnet in the split, 0 for the network itself
+	Addr  string // network address, with IPv6 colons replaced by dashes so it is safe in any file system
+	Bits  int    // mask bits
+	Label string // label from the plan, if any
+	VLAN  int    // VLAN from the plan, if any
+}
+
+// newFileName returns the template data for network n at position index.
+func newFileName(n subnet.Network, index int) fileName {
+	if n.Index != nil && n.Index.IsInt64() {
+		index = int(n.Index.Int64())
+	}
+	return fileName{
+		Index: index,
+		Addr:  strings.ReplaceAll(n.NetworkAddr.String(), ":", "-"),
+		Bits:  n.MaskBits,
+		Label: n.Label,
+		VLAN:  n.VLAN,
+	}
+}
+
+// writeFile formats n into a file in dir named by executing tmpl and appending the format's extension.
+func writeFile(f formatter.Formatter, format, dir string, tmpl *template.Template, n subnet.Network, index int) error {
+	var name strings.Builder
+	if err := tmpl.Execute(&name, newFileName(n, index)); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, name.String()+"."+formatExtensions[format])
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := f.Format(out, n); err != nil {
+		out.Close()
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return out.Close()
+}
+
+// writeFiles writes the network to a file in dir, or, when split is true, each of its subnets to a file of its own.
+// file names are generated from the text/template pattern, which has access to the fields of fileName.
+func writeFiles(f formatter.Formatter, format, dir, pattern string, n subnet.Network, split bool) error {
+	tmpl, err := template.New("file-name").Option("missingkey=error").Parse(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid file name template: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if !split {
+		return writeFile(f, format, dir, tmpl, n, 0)
+	}
+	if len(n.Subnets) == 0 {
+		return fmt.Errorf("--split-files requires --subnet_size")
+	}
+	for i, s := range n.Subnets {
+		if err := writeFile(f, format, dir, tmpl, s, i+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"math/big"
+	"net/netip"
+	"os"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// overlapReport is a pair of overlapping prefixes and the addresses they share.
+type overlapReport struct {
+	subnet.Overlap
+	Shared    subnet.Range `json:"shared"`
+	Addresses *big.Int     `json:"addresses"`
+}
+
+// findOverlapReports returns every pair of overlapping prefixes with the range of addresses they share.
+func findOverlapReports(prefixes []netip.Prefix) []overlapReport {
+	var reports []overlapReport
+	for _, o := range subnet.FindOverlaps(prefixes) {
+		shared, _ := subnet.NewNetworkFromPrefix(o.Outer).Overlaps(subnet.NewNetworkFromPrefix(o.Inner))
+		reports = append(reports, overlapReport{Overlap: o, Shared: shared, Addresses: shared.Size()})
+	}
+	return reports
+}
+
+// overlapsCmd represents the overlaps command
+var overlapsCmd = &cobra.Command{
+	Use:   "overlaps [CIDR]...",
+	Short: "report the prefixes in a list that overlap",
+	Long: `Report every pair of prefixes in a list that share addresses, with the exact range of addresses they share. Because
+prefixes are aligned blocks, one prefix of an overlapping pair always contains the other. Prefixes are taken from the
+arguments, or read one per line from --file or stdin.
+
+Exits 1 if any prefixes overlap, so it can be used as a check before applying a config.
+
+Examples:
+  # Check a handful of prefixes:
+  subnetCalc overlaps 10.0.0.0/16 10.0.4.0/22 10.1.0.0/16 10.0.5.0/24
+
+  # Check every VPC CIDR in an inventory:
+  subnetCalc overlaps --file vpc-cidrs.txt --json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		prefixes, err := parsePrefixArgs(args)
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		if file, _ := cmd.Flags().GetString("file"); len(args) == 0 || cmd.Flags().Changed("file") {
+			f, err := openInput(file)
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			read, err := readPrefixes(f)
+			f.Close()
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			prefixes = append(prefixes, read...)
+		}
+
+		reports := findOverlapReports(prefixes)
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			printJSON(reports)
+		} else if len(reports) > 0 {
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.SetStyle(table.StyleRounded)
+			t.AppendHeader(table.Row{"PREFIX", "OVERLAPS", "SHARED RANGE", "ADDRESSES"})
+			for _, r := range reports {
+				t.AppendRow(table.Row{r.Outer, r.Inner, r.Shared, formatBigInt(r.Addresses)})
+			}
+			t.Render()
+		}
+		if len(reports) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(overlapsCmd)
+	overlapsCmd.Flags().StringP("file", "f", "", "file listing prefixes to check, one per line, - for stdin")
+	overlapsCmd.Flags().BoolP("json", "j", false, "output the overlapping pairs in json format")
+}
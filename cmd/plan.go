@@ -0,0 +1,477 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"os/user"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+// loadPlan reads a plan file, exiting on error.
+func loadPlan(path string) subnet.Plan {
+	p, err := subnet.LoadPlan(path)
+	if err != nil {
+		utils.Log.Fatal().Msg(err.Error())
+	}
+	return p
+}
+
+var actor string
+
+// currentActor returns who is changing plans: --actor, the SUBNETCALC_ACTOR environment variable, or the user running
+// subnetCalc.
+func currentActor() string {
+	if actor != "" {
+		return actor
+	}
+	if env := os.Getenv("SUBNETCALC_ACTOR"); env != "" {
+		return env
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+// savePlan writes a plan file, giving any allocations without one a stable ID first, records the changes made to the
+// file in the plan's history, and runs the profile's hooks with them. exits on error.
+func savePlan(p subnet.Plan, path string) {
+	p.AssignIDs()
+	old, _ := subnet.LoadPlan(path) // a new or unreadable file is diffed against an empty plan
+	diff := p.Diff(old)
+	p.History = append(p.History, diff.Events(time.Now().UTC().Truncate(time.Second), currentActor())...)
+	if err := p.Save(path); err != nil {
+		utils.Log.Fatal().Msg(err.Error())
+	}
+	runHooks(path, diff)
+}
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "manage an address plan file",
+	Long: `Manage an address plan: the supernets available to a site or organization and the named allocations made from them.
+Plans are JSON files, as written by 'subnetCalc import'.
+
+Examples:
+  # Check a plan for overlapping or misplaced allocations:
+  subnetCalc plan validate plan.json
+
+  # Also require every name to follow a convention:
+  subnetCalc plan validate plan.json --name-pattern '^[a-z]+-[a-z]+-[0-9]{2}$'
+
+  # Allocate the next free /24 and name it:
+  subnetCalc plan allocate plan.json --size 24 --name web --vlan 120
+
+  # Allocate the next free /24 and reserve its sibling so it can grow to a /23:
+  subnetCalc plan allocate plan.json --size 24 --name app --growth-reserve 1
+
+  # Allocate the next free /24 and /64 together for a dual-stack VLAN:
+  subnetCalc plan allocate plan.json --size 24 --dual-stack --name users --vlan 130
+
+  # Reserve a specific prefix:
+  subnetCalc plan allocate plan.json 10.0.8.0/22 --name future --status reserved
+
+  # Release an allocation:
+  subnetCalc plan free plan.json 10.0.8.0/22
+
+  # Show utilization and the free space left in a plan:
+  subnetCalc plan show plan.json
+
+  # Record who owns an allocation:
+  subnetCalc plan annotate plan.json 10.0.4.0/24 --label prod-db --note "owned by DBA"
+
+  # Combine per-team plans into one:
+  subnetCalc plan merge east.json west.json --output merged.json
+`,
+}
+
+// planValidateCmd represents the plan validate command
+var planValidateCmd = &cobra.Command{
+	Use:   "validate <plan.json>",
+	Short: "check a plan for overlapping or misplaced allocations",
+	Long: `Check a plan for allocations that overlap, lie outside the plan's supernets, or have host bits set, and lint the
+allocations against the naming and metadata conventions:
+
+  duplicate-name  a name is used by more than one allocation, a warning by default
+  name-pattern    a name doesn't match --name-pattern, a warning by default
+  missing-vlan    an allocated prefix has no VLAN ID, off by default
+  platform-range  an allocated prefix overlaps a default range of Docker, Kubernetes, or another platform, a warning
+                  by default. The profile's platforms list replaces the built-in ranges
+
+The severity of each rule can be set to error, warning, info, or off with --severity. The command exits with status 1
+if any errors are found.
+
+Examples:
+  # Require every allocation to have a VLAN ID and a name like web-prod-01:
+  subnetCalc plan validate plan.json --name-pattern '^[a-z]+-[a-z]+-[0-9]{2}$' --severity missing-vlan=error
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		p := loadPlan(args[0])
+		rules := subnet.LintRules{Platforms: platformRanges()}
+		rules.Severities, _ = cmd.Flags().GetStringToString("severity")
+		if pattern, _ := cmd.Flags().GetString("name-pattern"); pattern != "" {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				utils.Log.Fatal().Msgf("invalid name pattern: %v", err)
+			}
+			rules.NamePattern = re
+		}
+		lint, err := p.Lint(rules)
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+
+		issues := append(p.Validate(), lint...)
+		failed := false
+		for _, issue := range issues {
+			fmt.Printf("%s: %v: %s\n", issue.Severity, issue.CIDR, issue.Message)
+			failed = failed || issue.Severity == subnet.SeverityError
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// checkAllocateArgs checks that plan allocate was given a CIDR or --size, and flags that can be combined with it.
+// returns an error describing the first conflict.
+func checkAllocateArgs(cidr bool, size, growth int, dualStack bool) error {
+	switch {
+	case dualStack && (cidr || size == 0 || growth > 0):
+		return errors.New("--dual-stack requires --size, and can't be combined with a CIDR or --growth-reserve")
+	case cidr && growth > 0:
+		return errors.New("--growth-reserve requires --size instead of a CIDR")
+	case !cidr && size <= 0:
+		return errors.New("either a CIDR or --size is required")
+	}
+	return nil
+}
+
+// checkStatus checks that status is one of subnet.Statuses.
+// returns an error listing the valid statuses if it isn't.
+func checkStatus(status string) error {
+	if !slices.Contains(subnet.Statuses, status) {
+		return fmt.Errorf("invalid status %q, expected one of: %s", status, strings.Join(subnet.Statuses, ", "))
+	}
+	return nil
+}
+
+// planAllocateCmd represents the plan allocate command
+var planAllocateCmd = &cobra.Command{
+	Use:   "allocate <plan.json> [CIDR]",
+	Short: "add an allocation to a plan",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		p := loadPlan(args[0])
+		name, _ := cmd.Flags().GetString("name")
+		status, _ := cmd.Flags().GetString("status")
+		zone, _ := cmd.Flags().GetString("zone")
+		size, _ := cmd.Flags().GetInt("size")
+		growth, _ := cmd.Flags().GetInt("growth-reserve")
+		vlan, _ := cmd.Flags().GetInt("vlan")
+		dualStack, _ := cmd.Flags().GetBool("dual-stack")
+		ipv6Size, _ := cmd.Flags().GetInt("ipv6-size")
+		a := subnet.Allocation{Name: name, Status: status, Zone: zone, VLAN: vlan}
+
+		if err := checkAllocateArgs(len(args) == 2, size, growth, dualStack); err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		if err := checkStatus(status); err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+
+		var reserved []netip.Prefix
+		switch {
+		case len(args) == 2:
+			prefix, err := netip.ParsePrefix(args[1])
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			a.CIDR = prefix
+			if err := p.Allocate(a); err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+		case dualStack:
+			v4, v6, err := p.AllocateDualStack(size, ipv6Size, a)
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			savePlan(p, args[0])
+			fmt.Println(v4.CIDR)
+			fmt.Println(v6.CIDR)
+			checkPlatforms(v4.CIDR)
+			checkPlatforms(v6.CIDR)
+			return
+		default:
+			var err error
+			if a, reserved, err = p.AllocateNextWithGrowth(size, growth, a); err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+		}
+		savePlan(p, args[0])
+		fmt.Println(a.CIDR)
+		for _, r := range reserved {
+			fmt.Fprintf(os.Stderr, "reserved %v for growth\n", r)
+		}
+		checkPlatforms(a.CIDR)
+	},
+}
+
+// planFreeCmd represents the plan free command
+var planFreeCmd = &cobra.Command{
+	Use:   "free <plan.json> <CIDR>",
+	Short: "remove an allocation from a plan",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		p := loadPlan(args[0])
+		prefix, err := netip.ParsePrefix(args[1])
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		if err := p.Free(prefix); err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		savePlan(p, args[0])
+	},
+}
+
+// planShowCmd represents the plan show command
+var planShowCmd = &cobra.Command{
+	Use:   "show <plan.json>",
+	Short: "show a plan's allocations, utilization, and free space",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		p := loadPlan(args[0])
+		if p.Name != "" {
+			fmt.Println("Plan:", p.Name)
+		}
+		fmt.Println("Supernets:")
+		for _, s := range p.Supernets {
+			fmt.Printf("  %-20v %.4g%% used\n", s, p.UtilizationOf(s)*100)
+		}
+		fmt.Println("Allocations:")
+		for _, a := range p.Allocations {
+			status := a.Status
+			if status == "" {
+				status = subnet.StatusAllocated
+			}
+			fmt.Println(strings.TrimRight(fmt.Sprintf("  %-20v %-12s %s", a.CIDR, status, a.Name), " "))
+		}
+		var growth []string
+		for _, a := range p.Allocations {
+			if a.Status == subnet.StatusReserved || a.Status == subnet.StatusContainer {
+				continue
+			}
+			if room := p.GrowthRoom(a.CIDR); room > 0 {
+				growth = append(growth, fmt.Sprintf("  %-20v can grow to /%d", a.CIDR, a.CIDR.Bits()-room))
+			}
+		}
+		if len(growth) > 0 {
+			fmt.Println("Growth room:")
+			fmt.Println(strings.Join(growth, "\n"))
+		}
+		fmt.Println("Free:")
+		for _, f := range p.FreeSpace() {
+			fmt.Println(" ", f)
+		}
+		for _, family := range []int{4, 6} {
+			if u, ok := p.Utilization(family); ok {
+				fmt.Printf("IPv%d utilization: %.4g%%\n", family, u*100)
+			}
+		}
+	},
+}
+
+// planLogCmd represents the plan log command
+var planLogCmd = &cobra.Command{
+	Use:   "log <plan.json> [CIDR]",
+	Short: "show the history of changes made to a plan",
+	Long: `Show the history subnetCalc records in a plan file: every allocation, release, and change, when it was made, and by
+whom. Give a prefix to only show the history of the allocations overlapping it. The actor is taken from --actor, the
+SUBNETCALC_ACTOR environment variable, or the user running subnetCalc.
+
+Examples:
+  # When was this block allocated, and by whom?
+  subnetCalc plan log plan.json 10.0.4.0/24
+
+  # Record a change under a service account's name:
+  SUBNETCALC_ACTOR=ci-pipeline subnetCalc plan allocate plan.json --size 24 --name build
+`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		p := loadPlan(args[0])
+		events := p.History
+		if len(args) > 1 {
+			prefix, err := netip.ParsePrefix(args[1])
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			events = p.EventsFor(prefix.Masked())
+		}
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			printJSON(events)
+			return
+		}
+		for _, e := range events {
+			line := fmt.Sprintf("%s  %-12s %-16s %-20v", e.Time.Format(time.RFC3339), e.Actor, e.Action, e.CIDR)
+			if e.Name != "" {
+				line += " " + e.Name
+			}
+			if e.Detail != "" {
+				line += " (" + e.Detail + ")"
+			}
+			fmt.Println(strings.TrimRight(line, " "))
+		}
+	},
+}
+
+// planAnnotateCmd represents the plan annotate command
+var planAnnotateCmd = &cobra.Command{
+	Use:   "annotate <plan.json> <CIDR>",
+	Short: "update the name, status, note, or labels of an allocation",
+	Long: `Update the metadata of an existing allocation in place. Only the given flags are changed, and members of the plan
+file that subnetCalc doesn't know about are preserved.
+
+Examples:
+  # Name an allocation, mark it allocated, and record its owner:
+  subnetCalc plan annotate plan.json 10.0.4.0/24 --label prod-db --status allocated --note "owned by DBA"
+
+  # Set one label and remove another:
+  subnetCalc plan annotate plan.json 10.0.4.0/24 --set env=prod --set team=
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		p := loadPlan(args[0])
+		prefix, err := netip.ParsePrefix(args[1])
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		a, ok := p.Find(prefix)
+		if !ok {
+			utils.Log.Fatal().Msgf("%v is not allocated in %s", prefix, args[0])
+		}
+
+		flags := cmd.Flags()
+		if flags.Changed("label") {
+			a.Name, _ = flags.GetString("label")
+		}
+		if flags.Changed("status") {
+			status, _ := flags.GetString("status")
+			if err := checkStatus(status); err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			a.Status = status
+		}
+		if flags.Changed("note") {
+			a.Note, _ = flags.GetString("note")
+		}
+		if flags.Changed("zone") {
+			a.Zone, _ = flags.GetString("zone")
+		}
+		if flags.Changed("vlan") {
+			a.VLAN, _ = flags.GetInt("vlan")
+		}
+		labels, _ := flags.GetStringToString("set")
+		for k, v := range labels {
+			if v == "" {
+				delete(a.Labels, k)
+				continue
+			}
+			if a.Labels == nil {
+				a.Labels = map[string]string{}
+			}
+			a.Labels[k] = v
+		}
+		savePlan(p, args[0])
+	},
+}
+
+// planMergeCmd represents the plan merge command
+var planMergeCmd = &cobra.Command{
+	Use:   "merge <plan.json>...",
+	Short: "combine several plans into one",
+	Long: `Combine plans for different regions, sites, or teams into one. Allocations that overlap an allocation from an earlier
+plan are reported as errors and names used more than once as warnings. The merged plan is only written when there
+are no errors, unless --force is given.
+
+Examples:
+  # Merge two team plans:
+  subnetCalc plan merge east.json west.json --output merged.json
+`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		var merged subnet.Plan
+		failed := false
+		for _, name := range args {
+			for _, issue := range merged.Merge(loadPlan(name)) {
+				fmt.Fprintf(os.Stderr, "%s: %s: %v: %s\n", issue.Severity, name, issue.CIDR, issue.Message)
+				failed = failed || issue.Severity == subnet.SeverityError
+			}
+		}
+		if planName, _ := cmd.Flags().GetString("name"); planName != "" {
+			merged.Name = planName
+		}
+		if force, _ := cmd.Flags().GetBool("force"); failed && !force {
+			utils.Log.Fatal().Msg("plans conflict, not writing the merged plan. use --force to write it anyway")
+		}
+
+		// the merge only brings together what the input plans and their histories already record, so events are only
+		// added for allocations no history mentions
+		output, _ := cmd.Flags().GetString("output")
+		var old subnet.Plan
+		if output != "" {
+			old, _ = subnet.LoadPlan(output) // a new or unreadable file is diffed against an empty plan
+		}
+		diff := merged.RecordMerge(old, time.Now().UTC().Truncate(time.Second), currentActor())
+		if output == "" {
+			printJSON(merged)
+			return
+		}
+		if err := merged.Save(output); err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		runHooks(output, diff)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+	planCmd.AddCommand(planValidateCmd, planAllocateCmd, planFreeCmd, planShowCmd, planMergeCmd, planAnnotateCmd, planLogCmd)
+	planAllocateCmd.Flags().StringP("name", "n", "", "name of the allocation")
+	planAllocateCmd.Flags().String("status", subnet.StatusAllocated, "status of the allocation: "+strings.Join(subnet.Statuses, ", "))
+	planAllocateCmd.Flags().StringP("zone", "z", "", "zone or region of the allocation")
+	planAllocateCmd.Flags().Int("vlan", 0, "VLAN ID of the allocation")
+	planAnnotateCmd.Flags().StringP("label", "l", "", "name of the allocation")
+	planAnnotateCmd.Flags().String("status", "", "status of the allocation: "+strings.Join(subnet.Statuses, ", "))
+	planAnnotateCmd.Flags().String("note", "", "free-form note about the allocation")
+	planAnnotateCmd.Flags().StringP("zone", "z", "", "zone or region of the allocation")
+	planAnnotateCmd.Flags().Int("vlan", 0, "VLAN ID of the allocation, 0 to remove it")
+	planValidateCmd.Flags().String("name-pattern", "", "regular expression every allocation name must match")
+	planValidateCmd.Flags().StringToString("severity", nil, "severity of a lint rule: error, warning, info, or off, e.g. missing-vlan=error")
+	if err := planValidateCmd.RegisterFlagCompletionFunc("severity", completeList(subnet.LintRuleNames, true)); err != nil {
+		utils.Log.Fatal().Msg(err.Error())
+	}
+	planAnnotateCmd.Flags().StringToString("set", nil, "set a label, e.g. env=prod. an empty value removes the label")
+	planLogCmd.Flags().BoolP("json", "j", false, "output the history in json format")
+	planMergeCmd.Flags().StringP("output", "o", "", "write the merged plan to a file instead of stdout")
+	planMergeCmd.Flags().StringP("name", "n", "", "name of the merged plan, defaults to the name of the first plan")
+	planMergeCmd.Flags().Bool("force", false, "write the merged plan even if the plans conflict")
+	planAllocateCmd.Flags().IntP("size", "s", 0, "allocate the next free prefix of this size when no CIDR is given")
+	planAllocateCmd.Flags().Bool("dual-stack", false, "with --size, also allocate the next free IPv6 prefix of --ipv6-size, paired with the IPv4 one")
+	planAllocateCmd.Flags().Int("ipv6-size", 64, "size of the IPv6 prefix allocated by --dual-stack")
+	planAllocateCmd.Flags().Int("growth-reserve", 0, "with --size, reserve room for the allocation to double in size this many times")
+}
@@ -0,0 +1,376 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// writePlan writes a plan with the given supernets and no allocations to a temporary file.
+// returns the file's path.
+func writePlan(t *testing.T, supernets ...string) string {
+	t.Helper()
+	return writePlanDoc(t, `{"supernets": ["`+strings.Join(supernets, `", "`)+`"], "allocations": []}`)
+}
+
+// writePlanDoc writes the plan JSON document doc to a temporary file.
+// returns the file's path.
+func writePlanDoc(t *testing.T, doc string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCheckAllocateArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		cidr      bool
+		size      int
+		growth    int
+		dualStack bool
+		wantErr   string
+	}{
+		{"cidr", true, 0, 0, false, ""},
+		{"size", false, 24, 0, false, ""},
+		{"size with growth", false, 24, 1, false, ""},
+		{"dual-stack size", false, 24, 0, true, ""},
+		{"nothing", false, 0, 0, false, "either a CIDR or --size"},
+		{"cidr with growth", true, 0, 1, false, "--growth-reserve requires --size"},
+		{"dual-stack cidr", true, 0, 0, true, "--dual-stack requires --size"},
+		{"dual-stack cidr and size", true, 24, 0, true, "--dual-stack requires --size"},
+		{"dual-stack without size", false, 0, 0, true, "--dual-stack requires --size"},
+		{"dual-stack with growth", false, 24, 1, true, "--dual-stack requires --size"},
+	}
+	for _, tt := range tests {
+		err := checkAllocateArgs(tt.cidr, tt.size, tt.growth, tt.dualStack)
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: checkAllocateArgs() = %v, want an error containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPlanAllocateDualStack(t *testing.T) {
+	path := writePlan(t, "10.0.0.0/16", "2001:db8::/48")
+
+	// a CIDR can't be paired, so the combination is rejected without changing the plan
+	if _, stderr, code := runCLI(t, "plan", "allocate", path, "10.0.8.0/24", "--dual-stack"); code == 0 || !strings.Contains(stderr, "--dual-stack") {
+		t.Errorf("allocate with a CIDR and --dual-stack exited %d with %q, want a --dual-stack error", code, stderr)
+	}
+	if p, err := subnet.LoadPlan(path); err != nil || len(p.Allocations) != 0 {
+		t.Fatalf("plan after a rejected allocation has %d allocations, %v, want 0", len(p.Allocations), err)
+	}
+
+	stdout, stderr, code := runCLI(t, "plan", "allocate", path, "--dual-stack", "--size", "24", "--name", "users")
+	if code != 0 {
+		t.Fatalf("allocate --dual-stack exited %d: %s", code, stderr)
+	}
+	if want := "10.0.0.0/24\n2001:db8::/64\n"; stdout != want {
+		t.Errorf("allocate --dual-stack printed %q, want %q", stdout, want)
+	}
+	p, err := subnet.LoadPlan(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Allocations) != 2 || p.Allocations[0].Pair != p.Allocations[1].ID || p.Allocations[1].Pair != p.Allocations[0].ID {
+		t.Errorf("allocate --dual-stack made %+v, want two allocations paired with each other", p.Allocations)
+	}
+}
+
+func TestPlanShowUtilization(t *testing.T) {
+	path := writePlan(t, "10.0.0.0/24", "2001:db8::/48")
+	if _, stderr, code := runCLI(t, "plan", "allocate", path, "10.0.0.0/25"); code != 0 {
+		t.Fatalf("allocate exited %d: %s", code, stderr)
+	}
+	stdout, stderr, code := runCLI(t, "plan", "show", path)
+	if code != 0 {
+		t.Fatalf("show exited %d: %s", code, stderr)
+	}
+	// a mixed total would round the half-used IPv4 supernet down to 0%, so each family is reported on its own
+	for _, want := range []string{"IPv4 utilization: 50%\n", "IPv6 utilization: 0%\n"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("show printed:\n%s\nwant it to contain %q", stdout, want)
+		}
+	}
+	if strings.Contains(stdout, "\nUtilization:") {
+		t.Errorf("show printed a mixed family total:\n%s", stdout)
+	}
+}
+
+func TestPlanAllocateStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		ok     bool
+	}{
+		{subnet.StatusAllocated, true},
+		{subnet.StatusReserved, true},
+		{subnet.StatusDeprecated, true},
+		{subnet.StatusContainer, true},
+		{"reserverd", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		path := writePlan(t, "10.0.0.0/16")
+		_, stderr, code := runCLI(t, "plan", "allocate", path, "--size", "24", "--status", tt.status)
+		if (code == 0) != tt.ok {
+			t.Errorf("allocate --status %q exited %d with %q, want success %t", tt.status, code, stderr, tt.ok)
+			continue
+		}
+		p, err := subnet.LoadPlan(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tt.ok && len(p.Allocations) != 0 {
+			t.Errorf("allocate --status %q added %+v, want the plan unchanged", tt.status, p.Allocations)
+		}
+		if tt.ok && (len(p.Allocations) != 1 || p.Allocations[0].Status != tt.status) {
+			t.Errorf("allocate --status %q added %+v, want one allocation with that status", tt.status, p.Allocations)
+		}
+	}
+}
+
+func TestPlanMergeHistory(t *testing.T) {
+	dir := t.TempDir()
+	east := filepath.Join(dir, "east.json")
+	west := filepath.Join(dir, "west.json")
+	docs := map[string]string{
+		east: `{"supernets": ["10.0.0.0/16"], "allocations": [{"id": "e1", "cidr": "10.0.0.0/24", "name": "east"}],
+			"history": [
+				{"time": "2023-01-01T00:00:00Z", "actor": "alice", "action": "supernet added", "cidr": "10.0.0.0/16"},
+				{"time": "2023-01-02T00:00:00Z", "actor": "alice", "action": "allocated", "cidr": "10.0.0.0/24", "id": "e1"}
+			]}`,
+		west: `{"supernets": ["10.1.0.0/16"], "allocations": [{"id": "w1", "cidr": "10.1.0.0/24", "name": "west"}]}`,
+	}
+	for path, doc := range docs {
+		if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// alice's events are kept, and only what no history records is added by the current actor
+	want := []string{
+		"alice supernet added 10.0.0.0/16",
+		"alice allocated 10.0.0.0/24",
+		"test supernet added 10.1.0.0/16",
+		"test allocated 10.1.0.0/24",
+	}
+	summarize := func(p subnet.Plan) []string {
+		var got []string
+		for _, e := range p.History {
+			got = append(got, e.Actor+" "+e.Action+" "+e.CIDR.String())
+		}
+		return got
+	}
+
+	stdout, stderr, code := runCLI(t, "plan", "merge", east, west)
+	if code != 0 {
+		t.Fatalf("merge exited %d: %s", code, stderr)
+	}
+	var printed subnet.Plan
+	if err := json.Unmarshal([]byte(stdout), &printed); err != nil {
+		t.Fatal(err)
+	}
+	if got := summarize(printed); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("merge printed a history of %q, want %q", got, want)
+	}
+
+	output := filepath.Join(dir, "merged.json")
+	for i := 0; i < 2; i++ {
+		if _, stderr, code := runCLI(t, "plan", "merge", east, west, "--output", output); code != 0 {
+			t.Fatalf("merge --output exited %d: %s", code, stderr)
+		}
+		saved, err := subnet.LoadPlan(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// merging again over the same output records nothing new
+		if got := summarize(saved); strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("merge --output run %d saved a history of %q, want %q", i+1, got, want)
+		}
+	}
+}
+
+func TestPlanValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		allocations string
+		args        []string
+		wantCode    int
+		want        []string
+	}{
+		{"clean", `{"cidr": "10.0.1.0/24", "name": "web", "vlan": 10}`, nil, 0, nil},
+		{"overlap", `{"cidr": "10.0.1.0/24", "name": "web"}, {"cidr": "10.0.1.128/25", "name": "db"}`, nil, 1,
+			[]string{"error: 10.0.1.0/24: overlaps allocation 10.0.1.128/25 db"}},
+		{"outside", `{"cidr": "192.168.0.0/24", "name": "web"}`, nil, 1,
+			[]string{"error: 192.168.0.0/24: not inside any of the plan's supernets"}},
+		{"host bits", `{"cidr": "10.0.1.5/24", "name": "web"}`, nil, 1, []string{"host bits are set, expected 10.0.1.0/24"}},
+		{"unknown status", `{"cidr": "10.0.1.0/24", "name": "web", "status": "retired"}`, nil, 0,
+			[]string{`warning: 10.0.1.0/24: unknown status "retired"`}},
+		{"mergeable siblings", `{"cidr": "10.0.2.0/25", "name": "a"}, {"cidr": "10.0.2.128/25", "name": "b"}`, nil, 0,
+			[]string{"info: 10.0.2.0/24: allocated siblings 10.0.2.0/25 and 10.0.2.128/25 could be merged"}},
+		{"lint severity", `{"cidr": "10.0.1.0/24", "name": "web"}`, []string{"--severity", "missing-vlan=error"}, 1,
+			[]string{"error: 10.0.1.0/24:"}},
+		{"name pattern", `{"cidr": "10.0.1.0/24", "name": "Web_1", "vlan": 10}`, []string{"--name-pattern", "^[a-z]+$"}, 0,
+			[]string{"warning: 10.0.1.0/24:"}},
+	}
+	for _, tt := range tests {
+		path := writePlanDoc(t, `{"supernets": ["10.0.0.0/16"], "allocations": [`+tt.allocations+`]}`)
+		stdout, stderr, code := runCLI(t, append([]string{"plan", "validate", path}, tt.args...)...)
+		if code != tt.wantCode {
+			t.Errorf("%s: validate exited %d, want %d\n%s%s", tt.name, code, tt.wantCode, stdout, stderr)
+		}
+		if tt.want == nil && stdout != "" {
+			t.Errorf("%s: validate printed %q, want nothing", tt.name, stdout)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(stdout, want) {
+				t.Errorf("%s: validate printed:\n%s\nwant it to contain %q", tt.name, stdout, want)
+			}
+		}
+	}
+}
+
+func TestPlanAllocateAndFree(t *testing.T) {
+	path := writePlan(t, "10.0.0.0/22")
+	steps := []struct {
+		args   []string
+		ok     bool
+		stdout string
+	}{
+		{[]string{"allocate", path, "10.0.1.0/24", "--name", "fixed"}, true, "10.0.1.0/24\n"},
+		{[]string{"allocate", path, "--size", "24", "--name", "first"}, true, "10.0.0.0/24\n"},
+		{[]string{"allocate", path, "--size", "24", "--name", "next"}, true, "10.0.2.0/24\n"},
+		{[]string{"allocate", path, "10.0.1.128/25"}, false, ""},
+		{[]string{"allocate", path, "--size", "23"}, false, ""},
+		{[]string{"free", path, "10.0.0.0/24"}, true, ""},
+		{[]string{"free", path, "10.0.0.0/24"}, false, ""},
+		{[]string{"allocate", path, "--size", "24", "--name", "reused"}, true, "10.0.0.0/24\n"},
+	}
+	for _, step := range steps {
+		stdout, stderr, code := runCLI(t, append([]string{"plan"}, step.args...)...)
+		if (code == 0) != step.ok {
+			t.Fatalf("plan %v exited %d with %q, want success %t", step.args, code, stderr, step.ok)
+		}
+		if step.ok && stdout != step.stdout {
+			t.Errorf("plan %v printed %q, want %q", step.args, stdout, step.stdout)
+		}
+	}
+
+	p, err := subnet.LoadPlan(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, a := range p.Allocations {
+		got = append(got, a.CIDR.String()+" "+a.Name)
+	}
+	if want := "10.0.1.0/24 fixed,10.0.2.0/24 next,10.0.0.0/24 reused"; strings.Join(got, ",") != want {
+		t.Errorf("allocations = %v, want %s", got, want)
+	}
+}
+
+func TestPlanAnnotate(t *testing.T) {
+	path := writePlanDoc(t, `{"supernets": ["10.0.0.0/16"], "owner": "netops", "allocations": [
+		{"id": "a1", "cidr": "10.0.4.0/24", "name": "db", "labels": {"team": "dba"}, "ticket": "NET-1"}
+	]}`)
+	tests := []struct {
+		args []string
+		want subnet.Allocation
+	}{
+		{[]string{"--label", "prod-db", "--status", "reserved"},
+			subnet.Allocation{Name: "prod-db", Status: "reserved", Labels: map[string]string{"team": "dba"}}},
+		{[]string{"--note", "owned by DBA", "--zone", "az1", "--vlan", "40"},
+			subnet.Allocation{Name: "prod-db", Status: "reserved", Note: "owned by DBA", Zone: "az1", VLAN: 40,
+				Labels: map[string]string{"team": "dba"}}},
+		{[]string{"--set", "env=prod", "--set", "team=", "--vlan", "0"},
+			subnet.Allocation{Name: "prod-db", Status: "reserved", Note: "owned by DBA", Zone: "az1",
+				Labels: map[string]string{"env": "prod"}}},
+	}
+	for _, tt := range tests {
+		if _, stderr, code := runCLI(t, append([]string{"plan", "annotate", path, "10.0.4.0/24"}, tt.args...)...); code != 0 {
+			t.Fatalf("annotate %v exited %d: %s", tt.args, code, stderr)
+		}
+		p, err := subnet.LoadPlan(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a := p.Allocations[0]
+		if a.Name != tt.want.Name || a.Status != tt.want.Status || a.Note != tt.want.Note || a.Zone != tt.want.Zone ||
+			a.VLAN != tt.want.VLAN || !reflect.DeepEqual(a.Labels, tt.want.Labels) {
+			t.Errorf("after annotate %v the allocation is %+v, want %+v", tt.args, a, tt.want)
+		}
+	}
+
+	// members subnetCalc doesn't know about survive, and each change is in the history
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"owner": "netops"`, `"ticket": "NET-1"`} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("annotated plan lost %s:\n%s", want, b)
+		}
+	}
+	p, _ := subnet.LoadPlan(path)
+	if len(p.History) != len(tests) {
+		t.Errorf("history has %d events, want one per annotate: %+v", len(p.History), p.History)
+	}
+
+	for _, args := range [][]string{{"10.0.5.0/24", "--note", "x"}, {"10.0.4.0/24", "--status", "gone"}} {
+		if _, _, code := runCLI(t, append([]string{"plan", "annotate", path}, args...)...); code == 0 {
+			t.Errorf("annotate %v exited 0, want an error", args)
+		}
+	}
+}
+
+func TestPlanLog(t *testing.T) {
+	path := writePlanDoc(t, `{"supernets": ["10.0.0.0/16"], "allocations": [{"id": "a1", "cidr": "10.0.4.0/24", "name": "db"}],
+		"history": [
+			{"time": "2023-01-01T00:00:00Z", "actor": "alice", "action": "allocated", "cidr": "10.0.4.0/24", "id": "a1", "name": "db", "detail": "allocated"},
+			{"time": "2023-01-02T00:00:00Z", "actor": "bob", "action": "allocated", "cidr": "10.0.5.0/24", "id": "a2", "name": "web", "detail": "allocated"},
+			{"time": "2023-01-03T00:00:00Z", "actor": "bob", "action": "freed", "cidr": "10.0.5.0/24", "id": "a2", "name": "web"}
+		]}`)
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"all", nil, `2023-01-01T00:00:00Z  alice        allocated        10.0.4.0/24          db (allocated)
+2023-01-02T00:00:00Z  bob          allocated        10.0.5.0/24          web (allocated)
+2023-01-03T00:00:00Z  bob          freed            10.0.5.0/24          web
+`},
+		{"prefix", []string{"10.0.5.0/25"}, `2023-01-02T00:00:00Z  bob          allocated        10.0.5.0/24          web (allocated)
+2023-01-03T00:00:00Z  bob          freed            10.0.5.0/24          web
+`},
+		{"supernet", []string{"10.0.0.0/16"}, ""},
+		{"unrelated", []string{"192.168.0.0/16"}, ""},
+	}
+	tests[2].want = tests[0].want
+	for _, tt := range tests {
+		stdout, stderr, code := runCLI(t, append([]string{"plan", "log", path}, tt.args...)...)
+		if code != 0 || stdout != tt.want {
+			t.Errorf("%s: log = %q, exit %d %s, want %q", tt.name, stdout, code, stderr, tt.want)
+		}
+	}
+
+	// changes are recorded under --actor
+	if _, stderr, code := runCLI(t, "plan", "allocate", path, "--size", "24", "--name", "app", "--actor", "ci"); code != 0 {
+		t.Fatalf("allocate exited %d: %s", code, stderr)
+	}
+	stdout, _, code := runCLI(t, "plan", "log", path, "10.0.0.0/24", "--json")
+	var events []subnet.Event
+	if err := json.Unmarshal([]byte(stdout), &events); code != 0 || err != nil || len(events) != 1 ||
+		events[0].Actor != "ci" || events[0].Action != subnet.ActionAllocated || events[0].Name != "app" {
+		t.Errorf("log --json = %q, exit %d, want the allocation of app by ci", stdout, code)
+	}
+}
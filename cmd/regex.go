@@ -0,0 +1,55 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+var anchored bool
+
+// regexCmd represents the regex command
+var regexCmd = &cobra.Command{
+	Use:   "regex <CIDR|start-end>",
+	Short: "generate a regular expression matching an IPv4 network or range",
+	Long: `Generate a regular expression that matches every IPv4 address in a CIDR or an inclusive start-end range, for tools
+that only support regex filters such as log processors and WAF rules.
+
+By default the expression is wrapped in word boundaries so it can match addresses embedded in a line of text. Use
+--anchored to match whole lines instead.
+
+Examples:
+  # Match any address in 10.0.0.0/14:
+  subnetCalc regex 10.0.0.0/14
+
+  # Match an arbitrary range of addresses:
+  subnetCalc regex 192.168.1.10-192.168.1.77 --anchored
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		r, err := subnet.ParseRange(args[0])
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		re, err := subnet.Regex(r)
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+
+		if anchored {
+			fmt.Printf("^%s$\n", re)
+		} else {
+			fmt.Printf(`\b%s\b`+"\n", re)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(regexCmd)
+	regexCmd.Flags().BoolVarP(&anchored, "anchored", "a", false, "anchor the expression to the start and end of the line")
+}
@@ -0,0 +1,54 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRegex(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		match   []string
+		noMatch []string
+	}{
+		{"cidr", []string{"10.0.0.0/14"}, []string{"10.0.0.0", "10.1.2.3", "10.3.255.255", "ip 10.2.0.1 seen"},
+			[]string{"10.4.0.0", "9.255.255.255", "110.0.0.1", "10.0.0.256"}},
+		{"range", []string{"192.168.1.10-192.168.1.77"}, []string{"192.168.1.10", "192.168.1.45", "192.168.1.77"},
+			[]string{"192.168.1.9", "192.168.1.78", "192.168.1.100", "192.168.2.10"}},
+		{"host", []string{"198.51.100.7/32"}, []string{"198.51.100.7"}, []string{"198.51.100.70", "198.51.100.6"}},
+		{"anchored", []string{"10.0.0.0/24", "--anchored"}, []string{"10.0.0.1"}, []string{"ip 10.0.0.1", "10.0.0.1 "}},
+	}
+	for _, tt := range tests {
+		stdout, stderr, code := runCLI(t, append([]string{"regex"}, tt.args...)...)
+		if code != 0 {
+			t.Errorf("%s: regex %v exited %d: %s", tt.name, tt.args, code, stderr)
+			continue
+		}
+		re, err := regexp.Compile(strings.TrimSpace(stdout))
+		if err != nil {
+			t.Errorf("%s: regex %v printed %q, which doesn't compile: %v", tt.name, tt.args, stdout, err)
+			continue
+		}
+		for _, s := range tt.match {
+			if !re.MatchString(s) {
+				t.Errorf("%s: %s doesn't match %q", tt.name, re, s)
+			}
+		}
+		for _, s := range tt.noMatch {
+			if re.MatchString(s) {
+				t.Errorf("%s: %s matches %q", tt.name, re, s)
+			}
+		}
+	}
+
+	for _, args := range [][]string{{"2001:db8::/64"}, {"10.0.0.9-10.0.0.1"}, {"not-a-network"}} {
+		if _, _, code := runCLI(t, append([]string{"regex"}, args...)...); code == 0 {
+			t.Errorf("regex %v exited 0, want an error", args)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/formatter"
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+var renderFormat string
+
+// readNetworks decodes the networks in a plan file, or in the JSON written by --json with or without --with-meta.
+func readNetworks(r io.Reader) ([]subnet.Network, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(b, &keys); err != nil {
+		return nil, err
+	}
+	if _, ok := keys["allocations"]; ok {
+		var p subnet.Plan
+		if err := json.Unmarshal(b, &p); err != nil {
+			return nil, err
+		}
+		return p.Networks(), nil
+	}
+	n, err := subnet.ReadNetwork(bytes.NewReader(b))
+	return []subnet.Network{n}, err
+}
+
+// renderCmd represents the render command
+var renderCmd = &cobra.Command{
+	Use:   "render <file.json>",
+	Short: "re-format a saved network or plan file",
+	Long: `Render the JSON written by --json, with or without --with-meta, or a plan file with any output format, without
+recomputing the subnets. Each of a plan's supernets is rendered with its allocations as subnets. Use - to read from
+stdin.
+
+Examples:
+  # Generate documentation from a saved split:
+  subnetCalc 10.0.0.0/16 --subnet_size 20 --json > network.json
+  subnetCalc render network.json --format markdown > network.md
+
+  # Show a plan as a table with names and statuses:
+  subnetCalc render plan.json --columns subnet,range,label,status,util
+
+  # Publish a plan's utilization to the node_exporter textfile collector:
+  subnetCalc render plan.json --format prom > /var/lib/node_exporter/textfile/subnets.prom
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		f, err := openInput(args[0])
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		networks, err := readNetworks(f)
+		f.Close()
+		if err != nil {
+			utils.Log.Fatal().Msgf("%s: %v", args[0], err)
+		}
+
+		fm, err := newFormatter(cmd, renderFormat)
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		if mf, ok := fm.(formatter.MultiFormatter); ok {
+			if err := mf.FormatAll(os.Stdout, networks); err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			return
+		}
+		for _, n := range networks {
+			if err := fm.Format(os.Stdout, n); err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(renderCmd)
+	renderCmd.Flags().StringVarP(&renderFormat, "format", "f", "table", "output format: "+strings.Join(formatter.Formats, ", "))
+	renderCmd.Flags().StringVar(&tableFormatter.Style, "style", "rounded", "subnet table style: "+strings.Join(formatter.TableStyleNames(), ", "))
+	renderCmd.Flags().StringSliceVar(&tableFormatter.Columns, "columns", formatter.DefaultColumns, "subnet table, tsv, csv, and markdown columns: "+strings.Join(formatter.ColumnNames, ", "))
+	renderCmd.Flags().StringVar(&outputTemplate, "template", "", "text/template, or a file holding one, to write each network and its subnets with")
+	renderCmd.Flags().BoolVar(&noHeader, "no-header", false, "leave out the header row of tsv and csv output")
+	renderCmd.Flags().BoolVar(&tableFormatter.ExpandIPv6, "expand-ipv6", false, "write IPv6 addresses in fully expanded form")
+	renderCmd.Flags().BoolVar(&tableFormatter.Binary, "binary", false, "add the network address, mask, and last address in binary, with the network and host bits split by |")
+	renderCmd.Flags().BoolVar(&tableFormatter.LegacyBroadcast, "legacy-broadcast", false, "label the last address of IPv6 networks and /31s as broadcast, and keep the broadcastAddr JSON key")
+	renderCmd.Flags().BoolVar(&tableFormatter.ASCII, "ascii", false, "only use ASCII characters, drawing table borders with +, -, and |")
+	renderCmd.Flags().BoolVar(&tableFormatter.Deterministic, "deterministic", false, "reproducible output for golden tests: ASCII borders, no colors, no terminal width, no timestamps")
+	renderCmd.Flags().IntVar(&outputWidth, "width", 0, "width to fit the subnet table to, overriding terminal detection and $COLUMNS")
+	if err := renderCmd.RegisterFlagCompletionFunc("format", completeList(formatter.Formats, false)); err != nil {
+		utils.Log.Fatal().Msg(err.Error())
+	}
+}
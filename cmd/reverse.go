@@ -0,0 +1,101 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+// printDelegation writes the reverse zones for a network and, for classless delegations, the records to add to the
+// parent zone in zone file syntax.
+func printDelegation(d subnet.ReverseDelegation, nameservers []string) {
+	if len(d.CNAMEs) == 0 {
+		fmt.Printf("; %v is delegated with %d reverse zones\n", d.Network, len(d.Zones))
+		for _, z := range d.Zones {
+			fmt.Printf("%s.\n", z)
+			for _, ns := range nameservers {
+				fmt.Printf("%s.\tIN\tNS\t%s\n", z, ns)
+			}
+		}
+		return
+	}
+
+	zone := d.Zones[0]
+	fmt.Printf("; RFC 2317 delegation of %v, add to the %s zone\n", d.Network, d.Parent)
+	for _, ns := range nameservers {
+		fmt.Printf("%s.\tIN\tNS\t%s\n", zone, ns)
+	}
+	for _, c := range d.CNAMEs {
+		fmt.Printf("%s\tIN\tCNAME\t%s\n", c.Name, c.Target)
+	}
+	fmt.Printf("; the delegated zone is %s.\n", zone)
+}
+
+// reverseCmd represents the reverse command
+var reverseCmd = &cobra.Command{
+	Use:   "reverse <CIDR>...",
+	Short: "calculate reverse DNS zones and RFC 2317 classless delegations",
+	Long: `Calculate the in-addr.arpa zones for IPv4 networks. Networks of /24 or larger are listed as the /8, /16, or /24
+zones they cover. Networks smaller than a /24 get an RFC 2317 classless zone, and the CNAME records the parent /24 zone
+needs to point each address into it are printed in zone file syntax, along with NS records for every --ns given.
+
+The classless zone label is controlled by --style: rfc (64/26, as in RFC 2317), dash (64-26, for software that rejects
+slashes in names), or range (64-127).
+
+Examples:
+  # Delegate a /27 to a customer's nameservers:
+  subnetCalc reverse 192.0.2.32/27 --ns ns1.example.net. --ns ns2.example.net.
+
+  # Name the zone by address range and print the records as JSON:
+  subnetCalc reverse 198.51.100.128/25 --style range --json
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		style, _ := cmd.Flags().GetString("style")
+		nameservers, _ := cmd.Flags().GetStringSlice("ns")
+		for i, ns := range nameservers {
+			if !strings.HasSuffix(ns, ".") {
+				nameservers[i] = ns + "."
+			}
+		}
+
+		var delegations []subnet.ReverseDelegation
+		for _, arg := range args {
+			p, err := netip.ParsePrefix(arg)
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			d, err := subnet.ReverseDelegationFor(p, style)
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			delegations = append(delegations, d)
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			printJSON(delegations)
+			return
+		}
+		for i, d := range delegations {
+			if i > 0 {
+				fmt.Println()
+			}
+			printDelegation(d, nameservers)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reverseCmd)
+	reverseCmd.Flags().String("style", "rfc", "classless zone label style: "+strings.Join(subnet.ReverseStyles, ", "))
+	reverseCmd.Flags().StringSlice("ns", nil, "nameservers to delegate the zones to")
+	reverseCmd.Flags().BoolP("json", "j", false, "output the zones and records in json format")
+	reverseCmd.RegisterFlagCompletionFunc("style", completeList(subnet.ReverseStyles, false))
+}
@@ -4,180 +4,228 @@ Copyright © 2023 Jake Rogers <code@supportoss.org>
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"math"
+	"math/big"
 	"net/netip"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/JakeTRogers/subnetCalc/formatter"
+	"github.com/JakeTRogers/subnetCalc/subnet"
 	"github.com/JakeTRogers/subnetCalc/utils"
-	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
-	"golang.org/x/text/language"
-	"golang.org/x/text/message"
+	"github.com/spf13/pflag"
 )
 
-// flipBytes performs a bitwise XOR on each byte in the slice.
-// returns a slice of bytes with the bits flipped.
-func flipBytes(b []byte) []byte {
-	for i := 0; i < len(b); i++ {
-		b[i] ^= 0xFF
-	}
-	return b
-}
-
-type network struct {
-	CIDR          netip.Prefix `json:"cidr"`
-	FirstHostIP   netip.Addr   `json:"firstIP"`
-	LastHostIP    netip.Addr   `json:"lastIP"`
-	NetworkAddr   netip.Addr   `json:"networkAddr"`
-	BroadcastAddr netip.Addr   `json:"broadcastAddr"`
-	SubnetMask    netip.Addr   `json:"subnetMask"`
-	MaskBits      int          `json:"maskBits"`
-	SubnetBits    int          `json:"subnetBits"`
-	MaxSubnets    uint         `json:"maxSubnets"`
-	MaxHosts      uint         `json:"maxHosts"`
-	MaskSize      int          `json:"-"`
-	Subnets       []network    `json:"subnets,omitempty"`
+// formatBigInt formats a big integer with commas separating each group of three digits.
+func formatBigInt(n *big.Int) string {
+	return formatter.RenderOptions{}.BigNumber(n)
 }
 
-// getBroadcastAddr calculates the broadcast address for a subnet by ORing the network address and the inverted subnet mask.
-// returns the broadcast address as a netip.Addr.
-func (n network) getBroadcastAddr() netip.Addr {
-	invertedMask := flipBytes(n.SubnetMask.AsSlice())
-	var lastIPBytes = make([]byte, len(n.NetworkAddr.AsSlice()))
-
-	for i := 0; i < len(n.NetworkAddr.AsSlice()); i++ {
-		lastIPBytes[i] = n.NetworkAddr.AsSlice()[i] | invertedMask[i]
+// tableRequested reports whether the table format, or any flag that only affects the table, was explicitly requested.
+func tableRequested(cmd *cobra.Command) bool {
+	for _, name := range []string{"format", "color", "style", "borders", "zebra", "depth-colors", "column-widths", "width", "ascii", "deterministic"} {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
 	}
-	b, _ := netip.AddrFromSlice(lastIPBytes)
-	return b
+	return false
 }
 
-// getSubnetBits calculates the available subnet bits for a given network address and mask bits based on the network class.
-// returns an integer representing the number of subnet bits.
-func (n network) getSubnetBits() int {
-	firstOctet := n.NetworkAddr.AsSlice()[0]
-	switch {
-	case firstOctet < 128:
-		return n.MaskBits - 8
-	case firstOctet < 192:
-		return n.MaskBits - 16
-	case firstOctet < 224:
-		return n.MaskBits - 24
-	case firstOctet < 240:
-		return n.MaskBits - 32
-	default:
-		return n.MaskBits - 40
-	}
+// sampled reports whether any of the flags selecting a subset of the subnets are set.
+func sampled(cmd *cobra.Command) bool {
+	return cmd.Flags().Changed("sample") || cmd.Flags().Changed("first") || cmd.Flags().Changed("last")
 }
 
-// getSubnetMask calculates the subnet mask given the number of mask bits and the mask size.
-// returns the subnet mask as a netip.Addr.
-func (n network) getSubnetMask() netip.Addr {
-	var maskBytes = make([]byte, n.MaskSize/8)
-	for i := 0; i < len(maskBytes); i++ {
-		for j := 0; j < 8; j++ {
-			if n.MaskBits > 0 {
-				maskBytes[i] |= 1 << uint(7-j)
-				n.MaskBits--
-			}
+// sampleSubnets populates n.Subnets with the subnets of size bits selected by --sample, --first, and --last, without
+// generating the rest of the split.
+func sampleSubnets(n *subnet.Network, bits int) error {
+	one := big.NewInt(1)
+	if sampleEvery != "" {
+		every, ok := new(big.Int).SetString(strings.TrimPrefix(sampleEvery, "every="), 10)
+		if !ok || every.Sign() <= 0 {
+			return fmt.Errorf("invalid sample %q, expected every=N", sampleEvery)
 		}
+		subnets, err := n.SubnetsOf(bits, new(big.Int), every, 0)
+		n.Subnets = subnets
+		return err
+	}
+	if sampleFirst < 0 || sampleLast < 0 {
+		return fmt.Errorf("--first and --last must not be negative")
 	}
-	subnetMask, _ := netip.AddrFromSlice(maskBytes)
-	return subnetMask
-}
-
-// getSubnets calculates the number of subnets that will fit in a supernet using the provided subnet mask bits.
-// returns a slice of network structs contained in a supernet.
-func (n *network) getSubnets(subnetMaskBits int) {
-	// get the number of subnets of size 'subnetMaskBits' that will fit in the supernet
-	numSubnets := int(math.Pow(2, float64(subnetMaskBits-n.MaskBits)))
 
-	for i := 0; i < numSubnets; i++ {
-		if i == 0 {
-			n.Subnets = append(n.Subnets, getNetworkDetails(fmt.Sprintf("%s/%d", n.NetworkAddr, subnetMaskBits)))
-		} else {
-			n.Subnets = append(n.Subnets, getNetworkDetails(fmt.Sprintf("%s/%d", n.Subnets[i-1].BroadcastAddr.Next(), subnetMaskBits)))
+	subnets, err := n.SubnetsOf(bits, new(big.Int), one, sampleFirst)
+	if err != nil {
+		return err
+	}
+	if sampleLast > 0 {
+		// start the last subnets after the first ones so none are listed twice
+		offset := new(big.Int).Sub(n.SubnetCount(bits), big.NewInt(int64(sampleLast)))
+		if first := big.NewInt(int64(len(subnets))); offset.Cmp(first) < 0 {
+			offset = first
+		}
+		last, err := n.SubnetsOf(bits, offset, one, sampleLast)
+		if err != nil {
+			return err
 		}
+		subnets = append(subnets, last...)
 	}
+	n.Subnets = subnets
+	return nil
 }
 
-// printNetwork prints information about an IP network to stdout.
-func (n network) printNetwork() {
-	// Use the message package to format large numbers with commas
-	p := message.NewPrinter(language.English)
-
-	fmt.Println()
-	fmt.Println("               Network:", n.CIDR)
-	fmt.Println("    Host Address Range:", n.FirstHostIP, "-", n.LastHostIP)
-	fmt.Println("     Broadcast Address:", n.BroadcastAddr)
-	fmt.Println("           Subnet Mask:", n.SubnetMask)
-	p.Println("       Maximum Subnets:", n.MaxSubnets)
-	p.Println("         Maximum Hosts:", n.MaxHosts)
-}
-
-// printJSON will print a network struct in json format.
-func (n network) printNetworkJSON() {
-	netJSON, err := json.MarshalIndent(n, "", "  ")
-	if err != nil {
-		utils.Log.Fatal().Msg(err.Error())
+// splitLimit returns the largest split held in memory: --max-subnets, or with no limit, the most subnets a slice can
+// hold, so larger IPv6 splits are streamed instead of failing.
+func splitLimit() *big.Int {
+	if maxSubnets > 0 {
+		return big.NewInt(int64(maxSubnets))
 	}
-	fmt.Println(string(netJSON))
+	return big.NewInt(math.MaxInt)
 }
 
-// printSubnets uses the table package to print subnet information in a table.
-func (n network) printSubnets(color bool) {
-	p := message.NewPrinter(language.English)
-	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
-	if color {
-		t.SetStyle(table.StyleColoredBlackOnBlueWhite)
-	} else {
-		t.SetStyle(table.StyleRounded)
+// streamSubnets writes the subnets of size bits in n to stdout one at a time as they are generated, for ndjson output
+// and splits larger than --max-subnets. The profile's conventions are applied to each subnet, as is its utilization if planned is true.
+// returns an error if the format can't be streamed.
+func streamSubnets(f formatter.Formatter, n subnet.Network, bits int, allocated []netip.Prefix, planned bool) error {
+	sf, ok := f.(formatter.StreamFormatter)
+	if !ok {
+		return fmt.Errorf("%v contains %s /%d subnets, more than %s can be held in memory. use --format ndjson, tsv, or csv to stream them, --first, --last, or --sample to list some of them, or raise --max-subnets", n.CIDR, formatBigInt(n.SubnetCount(bits)), bits, formatBigInt(splitLimit()))
 	}
-	t.AppendHeader(table.Row{"#", "SUBNET", "FIRST IP", "LAST IP", "BROADCAST", "HOSTS"})
 
-	for i, s := range n.Subnets {
-		t.AppendRow([]interface{}{i + 1, s.CIDR, s.FirstHostIP, s.LastHostIP, s.BroadcastAddr, p.Sprint(s.MaxHosts)})
+	subnets, err := subnet.SplitSeq(n.CIDR, bits)
+	if err != nil {
+		return err
 	}
-
-	fmt.Printf("\n  %v contains %d /%d subnets:\n", n.CIDR, len(n.Subnets), n.Subnets[0].MaskBits)
-	t.Render()
+	return sf.FormatStream(os.Stdout, n, func(yield func(subnet.Network) bool) {
+		subnets(func(s subnet.Network) bool {
+			applyHostConventions(&s)
+			if allFields {
+				s.SetNumeric()
+			}
+			if planned {
+				s.SetUtilization(allocated)
+			}
+			return yield(s)
+		})
+	})
 }
 
-// getNetworkDetails takes a CIDR and returns a network struct with details about the network
-// returns a network struct containing network details.
-func getNetworkDetails(cidr string) network {
-	var n network
-	var err error
+// checkMartians warns about special-purpose blocks that should not be used in the requested routing context.
+// if strict is true, the first martian found is treated as a fatal error.
+func checkMartians(n subnet.Network, ctx subnet.Context, strict bool) {
+	for _, b := range subnet.Martians(n.CIDR, ctx) {
+		msg := fmt.Sprintf("%v overlaps %s %v (%s), which should not be used in a %s context", n.CIDR, b.Name, b.Prefix, b.RFC, ctx)
+		if strict {
+			utils.Log.Fatal().Msg(msg)
+		}
+		fmt.Fprintln(os.Stderr, "warning:", msg)
+	}
+}
 
-	// use netip package to confirm the provided input is a valid ipv4 or ipv6 CIDR
-	inputCIDR, err := netip.ParsePrefix(cidr)
+// newMeta returns the metadata describing the current invocation.
+func newMeta(cmd *cobra.Command) *formatter.Meta {
+	host, err := os.Hostname()
 	if err != nil {
-		utils.Log.Fatal().Msg(err.Error())
+		utils.Module("meta").Debug().Msgf("unable to determine hostname: %v", err)
+	}
+	now := time.Now().UTC()
+	return &formatter.Meta{
+		Tool:          "subnetCalc",
+		Version:       cmd.Root().Version,
+		SchemaVersion: formatter.SchemaVersion,
+		GeneratedAt:   &now,
+		Args:          os.Args[1:],
+		Host:          host,
 	}
+}
 
-	n.CIDR = netip.MustParsePrefix(fmt.Sprintf("%s/%d", inputCIDR.Masked().Addr(), inputCIDR.Bits()))
-	n.NetworkAddr = n.CIDR.Masked().Addr()
-	n.MaskBits = n.CIDR.Bits()
-	n.MaskSize = n.CIDR.Addr().BitLen()
-	n.SubnetMask = n.getSubnetMask()
-	n.BroadcastAddr = n.getBroadcastAddr()
-	n.FirstHostIP = n.NetworkAddr.Next()
-	n.LastHostIP = n.BroadcastAddr.Prev()
-	n.SubnetBits = n.getSubnetBits()
-	n.MaxSubnets = uint(math.Pow(2, float64(n.SubnetBits)))
-	n.MaxHosts = 1<<(n.MaskSize-n.MaskBits) - 2
-	return n
+// newFormatter returns the formatter for the named output format, configured from the command's flags.
+func newFormatter(cmd *cobra.Command, name string) (formatter.Formatter, error) {
+	if cmd.Flags().Changed("template") {
+		if cmd.Flags().Changed("format") && name != "template" {
+			return nil, fmt.Errorf("--template can't be combined with --format %s", name)
+		}
+		name = "template"
+	}
+	switch name {
+	case "table":
+		if color {
+			tableFormatter.Style = "color"
+		}
+		if width, ok := utils.Width(outputWidth); ok && !tableFormatter.Deterministic {
+			tableFormatter.Width = width
+		}
+		return tableFormatter, nil
+	case "json":
+		f := formatter.JSONFormatter{RenderOptions: tableFormatter.RenderOptions}
+		if withMeta {
+			f.Meta = newMeta(cmd)
+		}
+		return f, nil
+	case "ndjson":
+		return formatter.NDJSONFormatter{RenderOptions: tableFormatter.RenderOptions}, nil
+	case "tsv":
+		return formatter.TSVFormatter{RenderOptions: tableFormatter.RenderOptions, Columns: tableFormatter.Columns, NoHeader: noHeader}, nil
+	case "csv":
+		return formatter.CSVFormatter{RenderOptions: tableFormatter.RenderOptions, Columns: tableFormatter.Columns, NoHeader: noHeader}, nil
+	case "markdown":
+		return formatter.MarkdownFormatter{RenderOptions: tableFormatter.RenderOptions, Columns: tableFormatter.Columns}, nil
+	case "summary":
+		return formatter.SummaryFormatter{RenderOptions: tableFormatter.RenderOptions}, nil
+	case "msgpack":
+		return formatter.MessagePackFormatter{RenderOptions: tableFormatter.RenderOptions}, nil
+	case "pb":
+		return formatter.ProtobufFormatter{}, nil
+	case "prom":
+		return formatter.PromFormatter{RenderOptions: tableFormatter.RenderOptions}, nil
+	case "inetnum":
+		return formatter.InetnumFormatter{RenderOptions: tableFormatter.RenderOptions}, nil
+	case "template":
+		if outputTemplate == "" {
+			return nil, fmt.Errorf("--format template requires --template")
+		}
+		text := outputTemplate
+		if info, err := os.Stat(outputTemplate); err == nil && info.Mode().IsRegular() {
+			b, err := os.ReadFile(outputTemplate)
+			if err != nil {
+				return nil, err
+			}
+			text = string(b)
+		}
+		return formatter.TemplateFormatter{RenderOptions: tableFormatter.RenderOptions, Text: text}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected one of: %s", name, strings.Join(formatter.Formats, ", "))
+	}
 }
 
 var color bool
-var subnetMaskBits int
+var outputFormat string
+var withMeta bool
+
+// allFields adds the integer and hex forms of every address to the output.
+var allFields bool
+var strict bool
+var routingContext string
+var subnetSizes []int
+var planFile string
+var outputWidth int
+var noHeader bool
+var outputTemplate string
+var sampleEvery string
+var sampleFirst, sampleLast int
+var outputDir, fileNamePattern string
+var splitFiles bool
+var growthReserve int
+var maxSubnets int
+var assumePrefix int
+var tableFormatter formatter.TableFormatter
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:     "subnetCalc <CIDR>",
+	Use:     "subnetCalc <CIDR | address>",
 	Version: "v0.1.7",
 	Short:   "calculate subnet",
 	Long: `subnetCalc is a CLI application to calculate subnets when given an IP address and a subnet mask in CIDR notation. It
@@ -191,14 +239,68 @@ Examples:
   # Get network information for a CIDR:
   subnetCalc 10.12.34.56/19
 
+  # Describe a single address, and the /24 around it:
+  subnetCalc 10.1.2.3 --assume-prefix 24
+
   # Get network information for a CIDR and carve it up into subnets:
   subnetCalc 10.12.0.0/16 --subnet_size 18
 
   # Get network information for a CIDR, carve it up into subnets, and print the output in JSON format:
   subnetCalc 192.168.10.0/24 --subnet_size 26 --json
+
+  # Carve a network into a sequence of differently sized subnets:
+  subnetCalc 192.168.10.0/24 --subnet-size 26,26,27,28
+
+  # Carve subnets that can each double in size later without renumbering:
+  subnetCalc 10.0.0.0/22 --subnet-size 25,26,26 --growth-reserve 1
+
+  # Write each subnet with a custom text/template:
+  subnetCalc 192.168.10.0/24 --subnet_size 26 --template '{{range .Subnets}}{{.CIDR}} gw {{.FirstHostIP}}{{"\n"}}{{end}}'
+
+  # Preview a very large split by listing the first and last ten subnets:
+  subnetCalc 10.0.0.0/8 --subnet_size 30 --first 10 --last 10
+
+  # Stream every /64 in a /40, far more than fit in memory, as tab-separated values:
+  subnetCalc 2001:db8::/40 --subnet_size 64 --format tsv | head
+
+  # Print the subnets as tab-separated values for use with cut or awk:
+  subnetCalc 10.0.0.0/16 --subnet_size 20 --format tsv
+
+  # Describe a network in a plain English sentence:
+  subnetCalc 192.168.10.0/25 --format summary
+
+  # Write the subnets as a binary protocol buffer message, see proto/subnetcalc.proto:
+  subnetCalc 10.0.0.0/8 --subnet_size 16 --format pb > subnets.pb
+
+  # Print JSON wrapped in an envelope describing how it was generated:
+  subnetCalc 192.168.10.0/24 --json --with-meta
+
+  # Print the subnet table with ASCII borders between every row:
+  subnetCalc 192.168.10.0/24 --subnet_size 26 --style ascii --borders
+
+  # Replace the broadcast column with the wildcard mask and classification:
+  subnetCalc 10.0.0.0/22 --subnet_size 24 --columns subnet,first,last,wildcard,class,hosts
+
+  # Show how much of each subnet has been allocated in a plan:
+  subnetCalc 10.0.0.0/16 --subnet_size 20 --plan plan.json
+
+  # Write each subnet to its own JSON file, named after its position in the split:
+  subnetCalc 10.0.0.0/16 --subnet_size 24 --json --output-dir out/ --split-files --file-name 'vlan-{{.Index}}'
+
+  # Carve subnets following the conventions of the prod profile in the config file:
+  subnetCalc 10.20.0.0/16 --subnet_size 24 --profile prod
+
+  # Fail if a CIDR should never be announced on the public internet:
+  subnetCalc 172.16.0.0/12 --context wan --strict
 `,
 
-	PersistentPreRun: utils.SetLogLevel,
+	Args: cobra.ArbitraryArgs,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		utils.SetLogLevel(cmd, args)
+		if err := applyProfile(cmd); err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		// if no arguments are provided, print help
 		if len(args) == 0 {
@@ -210,27 +312,123 @@ Examples:
 			utils.Log.Fatal().Msg("too many arguments, expected CIDR notation")
 		}
 
-		// populate network struct with details of the provided CIDR
-		n := getNetworkDetails(args[0])
+		// populate network struct with details of the provided CIDR. a bare address is described on its own, as a
+		// single host or as part of the --assume-prefix network around it
+		n, err := subnet.NewNetwork(args[0])
+		if addr, addrErr := netip.ParseAddr(args[0]); err != nil && addrErr == nil {
+			addr = addr.WithZone("")
+			bits := addr.BitLen()
+			if cmd.Flags().Changed("assume-prefix") {
+				bits = assumePrefix
+			}
+			if bits < 0 || bits > addr.BitLen() {
+				utils.Log.Fatal().Msgf("invalid prefix length %d for %v", bits, addr)
+			}
+			n, err = subnet.NewNetworkFromPrefix(netip.PrefixFrom(addr, bits)), nil
+			info := subnet.NewAddressInfo(addr)
+			n.Address = &info
+		} else if cmd.Flags().Changed("assume-prefix") {
+			utils.Log.Fatal().Msg("--assume-prefix only applies to addresses given without a prefix")
+		}
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+
+		// IPv6 has no broadcast address, so make sure an explicitly requested broadcast column isn't misread
+		if n.NetworkAddr.Is6() && cmd.Flags().Changed("columns") && slices.Contains(tableFormatter.Columns, "broadcast") && !tableFormatter.LegacyBroadcast {
+			fmt.Fprintln(os.Stderr, "warning: IPv6 networks have no broadcast address, the broadcast column shows the last address of each subnet")
+		}
 
-		// if subnet_size flag is set, carve up the supernet into subnets of the requested size
+		// if context flag is set, check the network against the special-purpose blocks
+		if cmd.Flags().Changed("context") {
+			ctx := subnet.Context(routingContext)
+			if ctx != subnet.ContextWAN && ctx != subnet.ContextLAN {
+				utils.Log.Fatal().Msgf("invalid context %q, expected %q or %q", routingContext, subnet.ContextWAN, subnet.ContextLAN)
+			}
+			checkMartians(n, ctx, strict)
+		}
+		// if subnet_size flag is set, carve up the supernet into subnets of the requested size, or into a sequence of
+		// subnets when several sizes are given. splits larger than --max-subnets are streamed once the format is known
+		streamBits := 0
 		if cmd.Flags().Changed("subnet_size") {
-			// check if subnet mask bits are larger than the supernet's mask bits
-			if subnetMaskBits <= n.MaskBits {
-				utils.Log.Fatal().Msgf("subnet mask bits, %d, must be larger than the supernet's mask bits: %d", subnetMaskBits, n.MaskBits)
+			// populate n.Subnets with a slice of network structs containing subnet details
+			split := func() error { return n.SplitSizesWithGrowth(subnetSizes, growthReserve) }
+			if growthReserve > 0 {
+				if sampled(cmd) {
+					utils.Log.Fatal().Msg("--growth-reserve can't be combined with --sample, --first, or --last")
+				}
+				if !cmd.Flags().Changed("columns") {
+					tableFormatter.Columns = append(tableFormatter.Columns, "status", "label")
+				}
+			} else if len(subnetSizes) == 1 {
+				split = func() error { return n.Split(subnetSizes[0]) }
+				if sampled(cmd) {
+					split = func() error { return sampleSubnets(&n, subnetSizes[0]) }
+				} else if outputFormat == "ndjson" && outputDir == "" || n.SubnetCount(subnetSizes[0]).Cmp(splitLimit()) > 0 {
+					streamBits = subnetSizes[0]
+					split = func() error { return nil }
+				}
+			} else if sampled(cmd) {
+				utils.Log.Fatal().Msg("--sample, --first, and --last require a single subnet size")
 			}
-			// populate n.subnets with a slice of network structs containing subnet details
-			n.getSubnets(subnetMaskBits)
+			if err := split(); err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			// a bare lookup isn't a decision to use the network, but carving it into subnets is
+			checkPlatforms(n.CIDR)
+		}
+
+		// apply the selected profile's conventions before the host counts are summarized
+		applyConventions(&n, strict)
+		if allFields {
+			n.SetNumeric()
+		}
+		// statistics about a sample of the subnets would be misleading
+		if cmd.Flags().Changed("subnet_size") && !sampled(cmd) && streamBits == 0 {
+			n.SetStats()
 		}
 
-		// print the network details in the requested format
+		// if plan flag is set, report how much of the network and each subnet the plan has allocated
+		var allocated []netip.Prefix
+		if planFile != "" {
+			allocated = loadPlan(planFile).Allocated()
+			n.SetUtilization(allocated)
+			if !cmd.Flags().Changed("columns") {
+				tableFormatter.Columns = append(tableFormatter.Columns, "util")
+			}
+		}
+
+		// print the network details in the requested format. when piped and no format or table styling was requested,
+		// default to plain tab-separated values instead of the decorated table
 		if cmd.Flags().Changed("json") {
-			n.printNetworkJSON()
-		} else {
-			n.printNetwork()
-			if n.Subnets != nil {
-				n.printSubnets(color)
+			outputFormat = "json"
+		} else if outputDir == "" && !utils.IsTerminal(os.Stdout) && !tableRequested(cmd) && !cmd.Flags().Changed("template") {
+			outputFormat = "tsv"
+		}
+		f, err := newFormatter(cmd, outputFormat)
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		if streamBits > 0 {
+			if outputDir != "" {
+				utils.Log.Fatal().Msgf("%v contains more than %s /%d subnets, too many to write to --output-dir", n.CIDR, formatBigInt(splitLimit()), streamBits)
 			}
+			if err := streamSubnets(f, n, streamBits, allocated, planFile != ""); err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			return
+		}
+		if splitFiles && outputDir == "" {
+			utils.Log.Fatal().Msg("--split-files requires --output-dir")
+		}
+		if outputDir != "" {
+			if err := writeFiles(f, outputFormat, outputDir, fileNamePattern, n, splitFiles); err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			return
+		}
+		if err := f.Format(os.Stdout, n); err != nil {
+			utils.Log.Fatal().Msg(err.Error())
 		}
 	},
 }
@@ -247,6 +445,69 @@ func init() {
 	rootCmd.Flags().BoolVarP(&color, "color", "c", false, "output subnet table in color")
 	rootCmd.Flags().BoolP("json", "j", false, "output information for the requested CIDR in json format")
 	rootCmd.MarkFlagsMutuallyExclusive("color", "json")
-	rootCmd.Flags().IntVarP(&subnetMaskBits, "subnet_size", "s", 0, "number of subnet mask bits to be used in carving up the supernet")
+	rootCmd.Flags().StringVarP(&outputFormat, "format", "f", "table", "output format: "+strings.Join(formatter.Formats, ", "))
+	rootCmd.MarkFlagsMutuallyExclusive("format", "json")
+	rootCmd.Flags().StringVar(&outputTemplate, "template", "", "text/template, or a file holding one, to write the network and its subnets with")
+	rootCmd.MarkFlagsMutuallyExclusive("template", "json")
+	rootCmd.Flags().BoolVar(&allFields, "all-fields", false, "add the integer and hex forms of every address, for database and IPAM imports")
+	rootCmd.Flags().BoolVar(&withMeta, "with-meta", false, "wrap json output in an envelope with the tool version, timestamp, arguments, and host")
+	rootCmd.Flags().StringVar(&tableFormatter.Style, "style", "rounded", "subnet table style: "+strings.Join(formatter.TableStyleNames(), ", "))
+	rootCmd.MarkFlagsMutuallyExclusive("color", "style")
+	rootCmd.Flags().BoolVar(&tableFormatter.Borders, "borders", false, "draw borders between every row of the subnet table")
+	rootCmd.Flags().BoolVar(&tableFormatter.Zebra, "zebra", false, "shade every other row of the subnet table")
+	rootCmd.Flags().BoolVar(&tableFormatter.Depth, "depth-colors", false, "color subnet table rows by prefix depth and print a legend")
+	rootCmd.Flags().StringSliceVar(&tableFormatter.Columns, "columns", formatter.DefaultColumns, "subnet table, tsv, and csv columns: "+strings.Join(formatter.ColumnNames, ", "))
+	rootCmd.Flags().BoolVar(&noHeader, "no-header", false, "leave out the header row of tsv and csv output")
+	rootCmd.Flags().StringToIntVar(&tableFormatter.Widths, "column-widths", nil, "maximum width of subnet table columns, e.g. subnet=18,class=12")
+	rootCmd.Flags().IntVar(&outputWidth, "width", 0, "width to fit the subnet table to, overriding terminal detection and $COLUMNS")
+	rootCmd.Flags().BoolVar(&tableFormatter.ExpandIPv6, "expand-ipv6", false, "write IPv6 addresses in fully expanded form")
+	rootCmd.Flags().BoolVar(&tableFormatter.Binary, "binary", false, "add the network address, mask, and last address in binary, with the network and host bits split by |")
+	rootCmd.Flags().BoolVar(&tableFormatter.LegacyBroadcast, "legacy-broadcast", false, "label the last address of IPv6 networks and /31s as broadcast, and keep the broadcastAddr JSON key")
+	rootCmd.Flags().BoolVar(&tableFormatter.ASCII, "ascii", false, "only use ASCII characters, drawing table borders with +, -, and |")
+	rootCmd.Flags().BoolVar(&tableFormatter.Deterministic, "deterministic", false, "reproducible output for golden tests: ASCII borders, no colors, no terminal width, no timestamps")
+	rootCmd.Flags().StringVar(&routingContext, "context", "", "warn about prefixes that should not be used in this routing context: wan or lan")
+	rootCmd.Flags().BoolVar(&strict, "strict", false, "treat context and profile supernet warnings as errors")
+	rootCmd.Flags().StringVar(&planFile, "plan", "", "plan file whose allocations are used to report utilization")
+	rootCmd.Flags().IntVar(&assumePrefix, "assume-prefix", 0, "prefix length of the network around an address given without one, instead of a /32 or /128")
+	rootCmd.Flags().IntSliceVarP(&subnetSizes, "subnet_size", "s", nil, "number of subnet mask bits to be used in carving up the supernet, or a comma separated sequence of sizes")
+	rootCmd.Flags().IntVar(&growthReserve, "growth-reserve", 0, "reserve room for each subnet to double in size this many times, carving one subnet per size")
+	rootCmd.Flags().IntVar(&maxSubnets, "max-subnets", 1000000, "largest split to hold in memory, larger splits are streamed in formats that support it, 0 for no limit, which can exhaust memory")
+	rootCmd.Flags().StringVar(&sampleEvery, "sample", "", "only list every Nth subnet, e.g. every=64")
+	rootCmd.Flags().IntVar(&sampleFirst, "first", 0, "only list the first N subnets")
+	rootCmd.Flags().IntVar(&sampleLast, "last", 0, "only list the last N subnets, may be combined with --first")
+	rootCmd.MarkFlagsMutuallyExclusive("sample", "first")
+	rootCmd.MarkFlagsMutuallyExclusive("sample", "last")
+	rootCmd.Flags().SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		// accept --subnet-size, the spelling used by every other multi-word flag
+		if name == "subnet-size" {
+			name = "subnet_size"
+		}
+		return pflag.NormalizedName(name)
+	})
+	rootCmd.Flags().StringVar(&outputDir, "output-dir", "", "write the output to a file in this directory instead of stdout")
+	rootCmd.Flags().BoolVar(&splitFiles, "split-files", false, "with --output-dir, write each subnet to a file of its own")
+	rootCmd.Flags().StringVar(&fileNamePattern, "file-name", "{{.Addr}}_{{.Bits}}", "template for the names of files written to --output-dir, using .Index, .Addr, .Bits, .Label, and .VLAN")
 	rootCmd.PersistentFlags().CountP("verbose", "v", "increase verbosity")
+	rootCmd.PersistentFlags().String("config", "", "config file defining profiles (default subnetCalc/config.json in the user config directory)")
+	rootCmd.PersistentFlags().StringVar(&actor, "actor", "", "who to record in a plan's history as making changes (default $SUBNETCALC_ACTOR or the current user)")
+	rootCmd.PersistentFlags().BoolVar(&noHooks, "no-hooks", false, "don't run the profile's hooks after changing a plan file")
+	rootCmd.PersistentFlags().BoolVar(&noPlatformCheck, "no-platform-check", false, "don't warn when split or allocated networks overlap Docker, Kubernetes, and other platform default ranges")
+	rootCmd.PersistentFlags().BoolVar(&hookDryRun, "hook-dry-run", false, "print the hooks that would run after changing a plan file instead of running them")
+	rootCmd.PersistentFlags().String("profile", "", "profile from the config file whose conventions and flag defaults to apply")
+	rootCmd.PersistentFlags().String("log-level", "", "log level: "+strings.Join(utils.LogLevels, ", ")+", optionally followed by module filters, e.g. warn,routes=debug")
+
+	rootCmd.ValidArgsFunction = noCompletion
+	for name, fn := range map[string]func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective){
+		"subnet_size": completeSubnetSizes,
+		"format":      completeList(formatter.Formats, false),
+		"style":       completeList(formatter.TableStyleNames(), false),
+		"columns":     completeList(formatter.ColumnNames, true),
+		"log-level":   completeList(utils.LogLevels, false),
+		"profile":     completeProfiles,
+		"context":     completeList([]string{string(subnet.ContextWAN), string(subnet.ContextLAN)}, false),
+	} {
+		if err := rootCmd.RegisterFlagCompletionFunc(name, fn); err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+	}
 }
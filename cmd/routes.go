@@ -0,0 +1,259 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/netip"
+	"os/exec"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+// routeTypes are the optional route type keywords that may precede the destination in `ip route` output.
+var routeTypes = map[string]bool{
+	"unicast": true, "local": true, "broadcast": true, "multicast": true, "blackhole": true,
+	"unreachable": true, "prohibit": true, "throw": true, "nat": true, "anycast": true,
+}
+
+// routeReport holds the results of analyzing a routing table.
+type routeReport struct {
+	Routes     []netip.Prefix    `json:"routes"`
+	Aggregates []netip.Prefix    `json:"aggregates"`
+	Overlaps   []subnet.Overlap  `json:"overlaps"`
+	Coverage   map[string]string `json:"coverage"`
+}
+
+// parseRouteDestination converts the destination column of a routing table into a prefix.
+// netmask is used when the destination is a bare address, and gateway is used to determine the family of "default".
+func parseRouteDestination(dest, netmask, gateway string) (netip.Prefix, error) {
+	if dest == "default" {
+		if gw, err := netip.ParseAddr(gateway); err == nil && gw.Is6() {
+			return netip.MustParsePrefix("::/0"), nil
+		}
+		return netip.MustParsePrefix("0.0.0.0/0"), nil
+	}
+
+	addr, bits, hasBits := strings.Cut(dest, "/")
+	// drop IPv6 zone identifiers such as fe80::%lo0/64
+	addr, _, _ = strings.Cut(addr, "%")
+
+	// BSD netstat truncates IPv4 destinations, e.g. 10/8 or 192.168.1
+	octets := -1
+	if !strings.Contains(addr, ":") {
+		octets = strings.Count(addr, ".") + 1
+		for i := octets; i < 4; i++ {
+			addr += ".0"
+		}
+	}
+
+	a, err := netip.ParseAddr(addr)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	switch {
+	case hasBits:
+		return netip.ParsePrefix(fmt.Sprintf("%s/%s", a, bits))
+	case netmask != "":
+		m, err := netip.ParseAddr(netmask)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		bits, ok := subnet.MaskLen(m)
+		if !ok {
+			return netip.Prefix{}, fmt.Errorf("netmask %v is not contiguous", m)
+		}
+		return a.Prefix(bits)
+	case octets > 0 && octets < 4:
+		return a.Prefix(octets * 8)
+	default:
+		return a.Prefix(a.BitLen())
+	}
+}
+
+// parseRoutes extracts the destination prefixes from `ip route`, `ip -6 route`, or `netstat -rn` output.
+// lines that don't describe a route, such as headers, are skipped.
+func parseRoutes(r io.Reader) ([]netip.Prefix, error) {
+	var routes []netip.Prefix
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if routeTypes[fields[0]] && len(fields) > 1 {
+			fields = fields[1:]
+		}
+
+		var netmask, gateway string
+		for i, f := range fields {
+			if f == "via" && i+1 < len(fields) {
+				gateway = fields[i+1]
+			}
+		}
+		if len(fields) > 2 && gateway == "" {
+			gateway = fields[1]
+			// Linux netstat -rn: Destination Gateway Genmask Flags ...
+			if m, err := netip.ParseAddr(fields[2]); err == nil && m.Is4() && strings.Count(fields[0], ".") == 3 {
+				netmask = fields[2]
+			}
+		}
+
+		p, err := parseRouteDestination(fields[0], netmask, gateway)
+		if err != nil {
+			utils.Module("routes").Debug().Msgf("skipping line %q: %v", scanner.Text(), err)
+			continue
+		}
+		routes = append(routes, p.Masked())
+	}
+	return routes, scanner.Err()
+}
+
+// readRoutes reads the routing table from the output of a command or from a file.
+func readRoutes(from, file string) ([]netip.Prefix, error) {
+	if from != "" {
+		args := strings.Fields(from)
+		out, err := exec.Command(args[0], args[1:]...).Output()
+		if err != nil {
+			return nil, fmt.Errorf("running %q: %w", from, err)
+		}
+		return parseRoutes(bytes.NewReader(out))
+	}
+
+	f, err := openInput(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseRoutes(f)
+}
+
+// newRouteReport aggregates the routes, finds overlaps, and calculates the address space covered per family.
+// duplicate routes are collapsed, and default routes are ignored unless includeDefault is true because they overlap
+// every other route.
+func newRouteReport(routes []netip.Prefix, includeDefault bool) routeReport {
+	var unique, v4, v6 []netip.Prefix
+	seen := map[netip.Prefix]bool{}
+	for _, p := range routes {
+		if seen[p] || (p.Bits() == 0 && !includeDefault) {
+			continue
+		}
+		seen[p] = true
+		unique = append(unique, p)
+	}
+	routes = unique
+
+	for _, p := range routes {
+		if p.Addr().Is4() {
+			v4 = append(v4, p)
+		} else {
+			v6 = append(v6, p)
+		}
+	}
+
+	coverage := map[string]string{}
+	for family, prefixes := range map[string][]netip.Prefix{"IPv4": v4, "IPv6": v6} {
+		if len(prefixes) == 0 {
+			continue
+		}
+		covered := subnet.Coverage(prefixes)
+		space := subnet.AddressCount(netip.PrefixFrom(prefixes[0].Addr(), 0))
+		pct, _ := new(big.Float).Quo(new(big.Float).SetInt(covered), new(big.Float).SetInt(space)).Float64()
+		coverage[family] = fmt.Sprintf("%s addresses (%.4g%% of the address space)", formatBigInt(covered), pct*100)
+	}
+
+	return routeReport{
+		Routes:     routes,
+		Aggregates: subnet.Aggregate(routes),
+		Overlaps:   subnet.FindOverlaps(routes),
+		Coverage:   coverage,
+	}
+}
+
+// print writes the requested sections of the report to stdout.
+func (r routeReport) print(reports []string) {
+	fmt.Printf("\n  Parsed %d unique routes\n", len(r.Routes))
+	for _, report := range reports {
+		switch report {
+		case "aggregate":
+			fmt.Printf("\n  Aggregated to %d prefixes:\n", len(r.Aggregates))
+			for _, p := range r.Aggregates {
+				fmt.Println("    ", p)
+			}
+		case "overlap":
+			fmt.Printf("\n  Found %d overlapping routes:\n", len(r.Overlaps))
+			for _, o := range r.Overlaps {
+				fmt.Println("    ", o.Outer, "contains", o.Inner)
+			}
+		case "coverage":
+			fmt.Println("\n  Coverage:")
+			for _, family := range []string{"IPv4", "IPv6"} {
+				if c, ok := r.Coverage[family]; ok {
+					fmt.Printf("     %s: %s\n", family, c)
+				}
+			}
+		default:
+			utils.Log.Fatal().Msgf("unknown report %q, expected aggregate, overlap, or coverage", report)
+		}
+	}
+}
+
+var routesFrom string
+var routesFile string
+var routesReports []string
+var includeDefault bool
+
+// routesCmd represents the routes command
+var routesCmd = &cobra.Command{
+	Use:   "routes",
+	Short: "summarize a routing table",
+	Long: `Parse the output of 'ip route', 'ip -6 route', or 'netstat -rn', normalize the destination prefixes, and report how
+they aggregate, which routes overlap, and how much address space they cover.
+
+The routing table is read from the output of the --from command, the --file, or stdin.
+
+Examples:
+  # Summarize the local routing table:
+  subnetCalc routes --from 'ip route'
+
+  # Only report overlapping routes from a saved routing table in JSON format:
+  subnetCalc routes --file routes.txt --report overlap --json
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		routes, err := readRoutes(routesFrom, routesFile)
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		r := newRouteReport(routes, includeDefault)
+
+		if cmd.Flags().Changed("json") {
+			out, err := json.MarshalIndent(r, "", "  ")
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			fmt.Println(string(out))
+		} else {
+			r.print(routesReports)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(routesCmd)
+	routesCmd.Flags().StringVar(&routesFrom, "from", "", "command to run to obtain the routing table, e.g. 'ip route'")
+	routesCmd.Flags().StringVarP(&routesFile, "file", "f", "", "file containing the routing table, - for stdin")
+	routesCmd.MarkFlagsMutuallyExclusive("from", "file")
+	routesCmd.Flags().StringSliceVarP(&routesReports, "report", "r", []string{"aggregate", "overlap", "coverage"}, "reports to print: aggregate, overlap, coverage")
+	routesCmd.Flags().BoolVar(&includeDefault, "include-default", false, "include default routes in the reports")
+	routesCmd.Flags().BoolP("json", "j", false, "output the report in json format")
+}
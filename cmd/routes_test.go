@@ -0,0 +1,105 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRouteDestination(t *testing.T) {
+	tests := []struct {
+		dest, netmask, gateway string
+		want                   string
+		wantErr                bool
+	}{
+		{"default", "", "192.168.1.1", "0.0.0.0/0", false},
+		{"default", "", "fe80::1", "::/0", false},
+		{"10.0.0.0/8", "", "", "10.0.0.0/8", false},
+		{"192.168.1.0", "255.255.255.0", "0.0.0.0", "192.168.1.0/24", false},
+		{"10.1", "", "", "10.1.0.0/16", false},
+		{"127", "", "", "127.0.0.0/8", false},
+		{"192.168.1.5", "", "", "192.168.1.5/32", false},
+		{"fe80::/64", "", "", "fe80::/64", false},
+		{"fe80::1%eth0", "", "", "fe80::1/128", false},
+		{"10.0.0.0", "255.0.255.0", "", "", true},
+		{"link#1", "", "", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseRouteDestination(tt.dest, tt.netmask, tt.gateway)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseRouteDestination(%q, %q, %q) = %v, want an error", tt.dest, tt.netmask, tt.gateway, got)
+			}
+			continue
+		}
+		if err != nil || got != netip.MustParsePrefix(tt.want) {
+			t.Errorf("parseRouteDestination(%q, %q, %q) = %v, %v, want %s", tt.dest, tt.netmask, tt.gateway, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseRoutes(t *testing.T) {
+	tests := []struct {
+		name, table string
+		want        []string
+	}{
+		{"ip route", `default via 192.168.1.1 dev eth0 proto dhcp metric 100
+10.0.0.0/8 via 192.168.1.254 dev eth0
+192.168.1.0/24 dev eth0 proto kernel scope link src 192.168.1.10
+blackhole 10.9.0.0/16
+`, []string{"0.0.0.0/0", "10.0.0.0/8", "192.168.1.0/24", "10.9.0.0/16"}},
+		{"ip -6 route", `2001:db8::/64 dev eth0 proto kernel metric 256
+default via fe80::1 dev eth0 metric 1024
+`, []string{"2001:db8::/64", "::/0"}},
+		{"netstat -rn", `Kernel IP routing table
+Destination     Gateway         Genmask         Flags   MSS Window  irtt Iface
+0.0.0.0         192.168.1.1     0.0.0.0         UG        0 0          0 eth0
+192.168.1.0     0.0.0.0         255.255.255.0   U         0 0          0 eth0
+`, []string{"0.0.0.0/0", "192.168.1.0/24"}},
+		{"bsd netstat -rn", `Destination        Gateway            Flags
+default            192.168.1.1        UGScg
+127                127.0.0.1          UCS
+10.1/16            link#4             UCS
+`, []string{"0.0.0.0/0", "127.0.0.0/8", "10.1.0.0/16"}},
+	}
+	for _, tt := range tests {
+		got, err := parseRoutes(strings.NewReader(tt.table))
+		if want := mustParsePrefixes(tt.want); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: parseRoutes() = %v, %v, want %v", tt.name, got, err, want)
+		}
+	}
+}
+
+func TestNewRouteReport(t *testing.T) {
+	routes := mustParsePrefixes([]string{"0.0.0.0/0", "10.0.0.0/25", "10.0.0.128/25", "10.0.0.0/24", "10.0.0.0/25"})
+	r := newRouteReport(routes, false)
+	if want := mustParsePrefixes([]string{"10.0.0.0/25", "10.0.0.128/25", "10.0.0.0/24"}); !reflect.DeepEqual(r.Routes, want) {
+		t.Errorf("routes = %v, want the unique routes without the default %v", r.Routes, want)
+	}
+	if want := mustParsePrefixes([]string{"10.0.0.0/24"}); !reflect.DeepEqual(r.Aggregates, want) {
+		t.Errorf("aggregates = %v, want %v", r.Aggregates, want)
+	}
+	if len(r.Overlaps) != 2 {
+		t.Errorf("overlaps = %v, want the /24 containing each /25", r.Overlaps)
+	}
+	if _, ok := r.Coverage["IPv6"]; ok || !strings.HasPrefix(r.Coverage["IPv4"], "256 addresses") {
+		t.Errorf("coverage = %v, want 256 IPv4 addresses only", r.Coverage)
+	}
+
+	if r := newRouteReport(routes, true); len(r.Routes) != 4 || r.Aggregates[0] != netip.MustParsePrefix("0.0.0.0/0") {
+		t.Errorf("with the default route, routes = %v and aggregates = %v, want it included", r.Routes, r.Aggregates)
+	}
+}
+
+// mustParsePrefixes parses each of s as a prefix.
+func mustParsePrefixes(s []string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, len(s))
+	for i, p := range s {
+		prefixes[i] = netip.MustParsePrefix(p)
+	}
+	return prefixes
+}
@@ -0,0 +1,138 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// histogramWidth is the length of the bar drawn for the most common prefix length.
+const histogramWidth = 40
+
+// lengthCount is the number of prefixes of one length.
+type lengthCount struct {
+	Bits  int `json:"bits"`
+	Count int `json:"count"`
+}
+
+// familyStats summarizes the prefixes of one address family.
+type familyStats struct {
+	Family    string        `json:"family"`
+	Prefixes  int           `json:"prefixes"`
+	Addresses *big.Int      `json:"addresses"` // distinct addresses covered, counting overlapping space once
+	Lengths   []lengthCount `json:"lengths"`   // prefix length histogram, shortest first
+}
+
+// prefixStats summarizes a set of prefixes.
+type prefixStats struct {
+	Prefixes int           `json:"prefixes"`
+	Overlaps int           `json:"overlaps"` // pairs of prefixes sharing addresses
+	Families []familyStats `json:"families"`
+}
+
+// newPrefixStats returns the histogram, coverage, and overlap count of prefixes. Families without any prefixes are
+// left out.
+func newPrefixStats(prefixes []netip.Prefix) prefixStats {
+	s := prefixStats{Prefixes: len(prefixes), Overlaps: len(subnet.FindOverlaps(prefixes))}
+	for _, family := range []struct {
+		name   string
+		bitLen int
+	}{{"IPv4", 32}, {"IPv6", 128}} {
+		counts := make([]int, family.bitLen+1)
+		var members []netip.Prefix
+		for _, p := range prefixes {
+			if p.Addr().BitLen() == family.bitLen {
+				members = append(members, p)
+				counts[p.Bits()]++
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		f := familyStats{Family: family.name, Prefixes: len(members), Addresses: subnet.Coverage(members)}
+		for bits, count := range counts {
+			if count > 0 {
+				f.Lengths = append(f.Lengths, lengthCount{Bits: bits, Count: count})
+			}
+		}
+		s.Families = append(s.Families, f)
+	}
+	return s
+}
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "summarize a list of prefixes",
+	Long: `Summarize a list of prefixes, such as a dumped route table or IPAM export: a histogram of prefix lengths, the
+address space covered, the number of overlapping pairs, and the split between IPv4 and IPv6.
+
+Prefixes are read one per line from --file, or stdin. Blank lines and anything after a # are ignored.
+
+Examples:
+  # Get a first look at an IPAM export:
+  subnetCalc stats --file prefixes.txt
+
+  # Summarize the prefixes in a routing table as JSON:
+  ip -4 route | cut -d' ' -f1 | subnetCalc stats --json
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("file")
+		f, err := openInput(file)
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		prefixes, err := readPrefixes(f)
+		f.Close()
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+
+		s := newPrefixStats(prefixes)
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			printJSON(s)
+			return
+		}
+
+		fmt.Printf("\n  %d prefixes, %d overlapping pairs\n", s.Prefixes, s.Overlaps)
+		for _, family := range s.Families {
+			space := subnet.AddressCount(netip.PrefixFrom(netip.IPv4Unspecified(), 0))
+			if family.Family == "IPv6" {
+				space = subnet.AddressCount(netip.PrefixFrom(netip.IPv6Unspecified(), 0))
+			}
+			fmt.Printf("\n  %s: %d prefixes covering %s addresses, %s of the address space\n", family.Family, family.Prefixes, formatBigInt(family.Addresses), formatPercent(family.Addresses, space))
+
+			most := 0
+			for _, l := range family.Lengths {
+				most = max(most, l.Count)
+			}
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.SetStyle(table.StyleRounded)
+			t.AppendHeader(table.Row{"LENGTH", "PREFIXES", ""})
+			for _, l := range family.Lengths {
+				// every length present gets at least one mark, however rare
+				bar := strings.Repeat("#", max(1, l.Count*histogramWidth/most))
+				t.AppendRow(table.Row{fmt.Sprintf("/%d", l.Bits), l.Count, bar})
+			}
+			t.Render()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().StringP("file", "f", "", "file listing the prefixes to summarize, one per line, - for stdin")
+	statsCmd.Flags().BoolP("json", "j", false, "output the summary in json format")
+}
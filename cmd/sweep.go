@@ -0,0 +1,316 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"net"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// probeResult is the outcome of probing one address.
+type probeResult struct {
+	Addr  netip.Addr    `json:"addr"`
+	Alive bool          `json:"alive"`
+	RTT   time.Duration `json:"rtt,omitempty"` // nanoseconds
+}
+
+// probeFunc checks whether addr answers within timeout.
+// returns the round trip time, or an error if it doesn't.
+type probeFunc func(addr netip.Addr, timeout time.Duration) (time.Duration, error)
+
+// errNoReply is returned by probes that saw no answer before the timeout.
+var errNoReply = errors.New("no reply")
+
+// parseProbe returns the probe described by spec, either icmp or tcp:<port>.
+func parseProbe(spec string) (probeFunc, error) {
+	if spec == "icmp" {
+		return probeICMP, nil
+	}
+	if port, ok := strings.CutPrefix(spec, "tcp:"); ok {
+		if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+			return nil, fmt.Errorf("invalid tcp port %q", port)
+		}
+		return func(addr netip.Addr, timeout time.Duration) (time.Duration, error) {
+			return probeTCP(addr, port, timeout)
+		}, nil
+	}
+	return nil, fmt.Errorf("invalid probe %q, expected icmp or tcp:<port>", spec)
+}
+
+// probeTCP connects to port on addr. A refused connection still shows the host is up.
+// returns the time taken to connect or be refused.
+func probeTCP(addr netip.Addr, port string, timeout time.Duration) (time.Duration, error) {
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(addr.String(), port), timeout)
+	if err == nil {
+		conn.Close()
+		return time.Since(start), nil
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return time.Since(start), nil
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return 0, errNoReply
+	}
+	return 0, err
+}
+
+// probeICMP sends an echo request to addr and waits for the matching echo reply. Raw sockets are used, so this needs
+// root or CAP_NET_RAW.
+// returns the round trip time.
+func probeICMP(addr netip.Addr, timeout time.Duration) (time.Duration, error) {
+	network, request, reply := "ip4:icmp", byte(8), byte(0)
+	if addr.Is6() {
+		network, request, reply = "ip6:ipv6-icmp", 128, 129
+	}
+	conn, err := net.ListenPacket(network, "")
+	if err != nil {
+		return 0, fmt.Errorf("%w (icmp probes need root or CAP_NET_RAW, try --probe tcp:<port>)", err)
+	}
+	defer conn.Close()
+
+	id, seq := uint16(rand.Intn(1<<16)), uint16(rand.Intn(1<<16))
+	msg := make([]byte, 16)
+	msg[0] = request
+	binary.BigEndian.PutUint16(msg[4:], id)
+	binary.BigEndian.PutUint16(msg[6:], seq)
+	if addr.Is4() {
+		// the kernel fills in the ICMPv6 checksum, but not the ICMP one
+		binary.BigEndian.PutUint16(msg[2:], icmpChecksum(msg))
+	}
+
+	start := time.Now()
+	if _, err := conn.WriteTo(msg, &net.IPAddr{IP: addr.AsSlice()}); err != nil {
+		return 0, err
+	}
+	conn.SetReadDeadline(start.Add(timeout))
+	buf := make([]byte, 1500)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return 0, errNoReply
+			}
+			return 0, err
+		}
+		src, ok := netip.AddrFromSlice(from.(*net.IPAddr).IP)
+		if !ok || src.Unmap() != addr || n < 8 || buf[0] != reply {
+			continue
+		}
+		if binary.BigEndian.Uint16(buf[4:]) == id && binary.BigEndian.Uint16(buf[6:]) == seq {
+			return time.Since(start), nil
+		}
+	}
+}
+
+// icmpChecksum returns the internet checksum of msg.
+func icmpChecksum(msg []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(msg); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(msg[i:]))
+	}
+	if len(msg)%2 == 1 {
+		sum += uint32(msg[len(msg)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return ^uint16(sum)
+}
+
+// hostAddrs returns the addresses in prefix that can be assigned to hosts, leaving out the network and broadcast
+// addresses of IPv4 prefixes larger than a /31.
+func hostAddrs(prefix netip.Prefix) []netip.Addr {
+	count := int(subnet.AddressCount(prefix).Int64())
+	addrs := make([]netip.Addr, 0, count)
+	for a, i := prefix.Addr(), 0; i < count; a, i = a.Next(), i+1 {
+		addrs = append(addrs, a)
+	}
+	if prefix.Addr().Is4() && prefix.Bits() < 31 {
+		addrs = addrs[1 : len(addrs)-1]
+	}
+	return addrs
+}
+
+// rateLimited calls fn for each address, starting at most rate calls per second, and waits for all of them to return.
+func rateLimited(addrs []netip.Addr, rate int, fn func(i int, a netip.Addr)) {
+	tick := time.NewTicker(max(time.Second/time.Duration(rate), 1))
+	defer tick.Stop()
+
+	var wg sync.WaitGroup
+	for i, a := range addrs {
+		if i > 0 {
+			<-tick.C
+		}
+		wg.Add(1)
+		go func(i int, a netip.Addr) {
+			defer wg.Done()
+			fn(i, a)
+		}(i, a)
+	}
+	wg.Wait()
+}
+
+// markAlive sets the alive label of every allocation in p overlapping the probed addresses to yes if any of them
+// answered, or no if none did.
+// returns the number of allocations labelled.
+func markAlive(p *subnet.Plan, results []probeResult) int {
+	marked := 0
+	for i := range p.Allocations {
+		a := &p.Allocations[i]
+		probed, alive := false, false
+		for _, r := range results {
+			if a.CIDR.Contains(r.Addr) {
+				probed = true
+				alive = alive || r.Alive
+			}
+		}
+		if !probed {
+			continue
+		}
+		if a.Labels == nil {
+			a.Labels = map[string]string{}
+		}
+		a.Labels["alive"] = "no"
+		if alive {
+			a.Labels["alive"] = "yes"
+		}
+		marked++
+	}
+	return marked
+}
+
+// sweepCmd represents the sweep command
+var sweepCmd = &cobra.Command{
+	Use:   "sweep <CIDR>",
+	Short: "probe the hosts in a network to see which are alive",
+	Long: `Probe every host address in a network with an ICMP echo request, or a TCP connection to a port, and report which
+hosts answer. A refused TCP connection counts as alive, since the host itself replied.
+
+This sends traffic to every address in the network, so only sweep networks you are responsible for. Probes are
+limited to --rate per second, and networks with more than --max-addresses addresses are refused. ICMP probes use raw
+sockets and need root or CAP_NET_RAW; TCP probes don't.
+
+With --plan, every allocation overlapping the swept network is labelled alive=yes if any of its probed addresses
+answered, or alive=no if none did, turning the plan into a quick audit of what is actually in use.
+
+Examples:
+  # Find the live hosts on a LAN:
+  sudo subnetCalc sweep 192.168.1.0/24
+
+  # Check which allocations in a plan have anything listening for SSH:
+  subnetCalc sweep 10.0.8.0/22 --probe tcp:22 --rate 100 --plan plan.json
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		prefix, err := netip.ParsePrefix(args[0])
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		prefix = prefix.Masked()
+
+		flags := cmd.Flags()
+		spec, _ := flags.GetString("probe")
+		rate, _ := flags.GetInt("rate")
+		timeout, _ := flags.GetDuration("timeout")
+		limit, _ := flags.GetInt("max-addresses")
+		planFile, _ := flags.GetString("plan")
+		all, _ := flags.GetBool("all")
+		probe, err := parseProbe(spec)
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		if rate < 1 {
+			utils.Log.Fatal().Msgf("rate must be positive, got %d", rate)
+		}
+		if count := subnet.AddressCount(prefix); count.Cmp(big.NewInt(int64(limit))) > 0 {
+			utils.Log.Fatal().Msgf("%v has %s addresses, more than --max-addresses %d", prefix, formatBigInt(count), limit)
+		}
+
+		addrs := hostAddrs(prefix)
+		results := make([]probeResult, len(addrs))
+		errs := make([]error, len(addrs))
+		rateLimited(addrs, rate, func(i int, a netip.Addr) {
+			rtt, err := probe(a, timeout)
+			results[i] = probeResult{Addr: a, Alive: err == nil, RTT: rtt}
+			if err != nil && !errors.Is(err, errNoReply) {
+				errs[i] = err
+			}
+		})
+
+		alive, failed := 0, 0
+		for i, r := range results {
+			if r.Alive {
+				alive++
+			}
+			if errs[i] != nil {
+				failed++
+				utils.Module("sweep").Debug().Msgf("%v: %v", r.Addr, errs[i])
+			}
+		}
+		if failed == len(results) {
+			utils.Log.Fatal().Msgf("every probe failed: %v", errs[0])
+		}
+		if failed > 0 {
+			fmt.Fprintf(os.Stderr, "warning: %d probes failed, use --log-level sweep=debug to see why\n", failed)
+		}
+
+		if planFile != "" {
+			p := loadPlan(planFile)
+			if markAlive(&p, results) > 0 {
+				savePlan(p, planFile)
+			}
+		}
+
+		if asJSON, _ := flags.GetBool("json"); asJSON {
+			printJSON(results)
+			return
+		}
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.SetStyle(table.StyleRounded)
+		t.AppendHeader(table.Row{"ADDRESS", "STATUS", "RTT"})
+		for _, r := range results {
+			switch {
+			case r.Alive:
+				t.AppendRow(table.Row{r.Addr, "alive", r.RTT.Round(time.Microsecond)})
+			case all:
+				t.AppendRow(table.Row{r.Addr, "dead", ""})
+			}
+		}
+		t.AppendFooter(table.Row{"", fmt.Sprintf("%d of %d alive", alive, len(results)), ""})
+		t.Render()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sweepCmd)
+	sweepCmd.Flags().String("probe", "icmp", "how to probe each host, icmp or tcp:<port>")
+	sweepCmd.Flags().Int("rate", 50, "maximum number of probes started per second")
+	sweepCmd.Flags().Duration("timeout", time.Second, "time to wait for each host to answer")
+	sweepCmd.Flags().Int("max-addresses", 4096, "largest number of addresses to probe")
+	sweepCmd.Flags().StringP("plan", "p", "", "label the allocations in this plan file alive=yes or alive=no")
+	sweepCmd.Flags().BoolP("all", "a", false, "list the hosts that didn't answer too")
+	sweepCmd.Flags().BoolP("json", "j", false, "output the results in json format")
+	sweepCmd.RegisterFlagCompletionFunc("probe", completeList([]string{"icmp", "tcp:22", "tcp:80", "tcp:443"}, false))
+	sweepCmd.MarkFlagFilename("plan", "json")
+}
@@ -0,0 +1,216 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+// templateDir returns the directory user-defined plan templates are read from: templates beside the config file.
+func templateDir(cmd *cobra.Command) string {
+	path, _ := cmd.Flags().GetString("config")
+	if path == "" {
+		path = defaultConfigPath()
+	}
+	if path == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(path), "templates")
+}
+
+// loadTemplates returns the built-in plan templates and those defined as <name>.json files in dir, which replace
+// built-in templates of the same name. A missing directory only leaves the built-in templates.
+func loadTemplates(dir string) (map[string]subnet.Template, error) {
+	templates := map[string]subnet.Template{}
+	for name, t := range subnet.PlanTemplates {
+		templates[name] = t
+	}
+	if dir == "" {
+		return templates, nil
+	}
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return templates, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		name, ok := strings.CutSuffix(f.Name(), ".json")
+		if !ok || f.IsDir() {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var t subnet.Template
+		if err := json.Unmarshal(b, &t); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name(), err)
+		}
+		templates[name] = t
+	}
+	return templates, nil
+}
+
+// templateNames returns the names of the templates in sorted order.
+func templateNames(templates map[string]subnet.Template) []string {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readHierarchy returns the top level block of the hierarchy described by the JSON file name, - for stdin.
+func readHierarchy(name string) (subnet.Block, error) {
+	var root subnet.Block
+	f, err := openInput(name)
+	if err != nil {
+		return root, err
+	}
+	defer f.Close()
+	d := json.NewDecoder(f)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&root); err != nil {
+		return root, fmt.Errorf("%s: %w", name, err)
+	}
+	return root, nil
+}
+
+// planNewCmd represents the plan new command
+var planNewCmd = &cobra.Command{
+	Use:   "new <plan.json>",
+	Short: "create a plan from a template",
+	Long: `Create a plan for a number of sites from a template. Every site is given its own aligned block of the supernet, so it
+can be summarized as a single route, holding one allocation for each of the template's tiers, named site<n>-<tier>.
+
+Built-in templates:
+  3-tier      mgmt /26, servers /24, users /23
+  branch      mgmt /27, users /24, voice /25, guest /25
+  datacenter  oob /24, mgmt /24, servers /21, storage /23
+
+Templates can be defined, or the built-in ones replaced, by adding <name>.json files to the templates directory beside
+the config file, such as {"description": "...", "tiers": [{"name": "mgmt", "size": 26, "vlan": 10}]}. Use
+--list-templates to see every template available.
+
+For addressing with more levels, --from reads a hierarchy of nested blocks instead, each with a name and either a size
+or a fixed cidr. The top level block needs a cidr, which becomes the plan's supernet. Blocks holding other blocks are
+allocated as containers, and every allocation is named after the path to its block. A count repeats a block, numbering
+the copies, and a level labels a block and everything inside it, so allocations can be found by region or site:
+
+  {"name": "corp", "cidr": "10.16.0.0/12", "blocks": [
+    {"name": "us-east", "level": "region", "size": 16, "blocks": [
+      {"name": "nyc", "count": 2, "level": "site", "size": 20, "blocks": [
+        {"name": "users", "size": 24, "vlan": 100},
+        {"name": "voice", "size": 24, "vlan": 200}]}]}]}
+
+Examples:
+  # Plan four sites of management, server, and user networks:
+  subnetCalc plan new plan.json --template 3-tier --site 4 --supernet 10.20.0.0/16
+
+  # List the available templates:
+  subnetCalc plan new --list-templates
+
+  # Plan regions, sites, and VLANs from a hierarchy:
+  subnetCalc plan new plan.json --from hierarchy.json
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		templates, err := loadTemplates(templateDir(cmd))
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		flags := cmd.Flags()
+		if list, _ := flags.GetBool("list-templates"); list {
+			for _, name := range templateNames(templates) {
+				t := templates[name]
+				var tiers []string
+				for _, tier := range t.Tiers {
+					tiers = append(tiers, fmt.Sprintf("%s /%d", tier.Name, tier.Size))
+				}
+				fmt.Printf("%-12s %s\n%-12s %s\n", name, t.Description, "", strings.Join(tiers, ", "))
+			}
+			return
+		}
+		if len(args) == 0 {
+			utils.Log.Fatal().Msg("a plan file is required")
+		}
+
+		name, _ := flags.GetString("name")
+		force, _ := flags.GetBool("force")
+		if _, err := os.Stat(args[0]); err == nil && !force {
+			utils.Log.Fatal().Msgf("%s already exists, use --force to replace it", args[0])
+		}
+
+		var p subnet.Plan
+		if from, _ := flags.GetString("from"); from != "" {
+			root, err := readHierarchy(from)
+			if err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			if p, err = subnet.NewPlanFromHierarchy(root); err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+			if name != "" {
+				p.Name = name
+			}
+		} else {
+			templateName, _ := flags.GetString("template")
+			sites, _ := flags.GetInt("site")
+			supernetFlag, _ := flags.GetString("supernet")
+			t, ok := templates[templateName]
+			if !ok {
+				utils.Log.Fatal().Msgf("unknown template %q, expected one of: %s", templateName, strings.Join(templateNames(templates), ", "))
+			}
+			if sites < 1 {
+				utils.Log.Fatal().Msgf("site count must be positive, got %d", sites)
+			}
+			supernet, err := netip.ParsePrefix(supernetFlag)
+			if err != nil {
+				utils.Log.Fatal().Msgf("--supernet: %v", err)
+			}
+			if p, err = subnet.NewPlanFromTemplate(name, supernet, t, sites); err != nil {
+				utils.Log.Fatal().Msg(err.Error())
+			}
+		}
+		savePlan(p, args[0])
+		for _, a := range p.Allocations {
+			fmt.Println(strings.TrimRight(fmt.Sprintf("%-20v %-10s %s", a.CIDR, a.Status, a.Name), " "))
+		}
+	},
+}
+
+func init() {
+	planCmd.AddCommand(planNewCmd)
+	planNewCmd.Flags().StringP("template", "t", "3-tier", "template to create the plan from")
+	planNewCmd.Flags().Int("site", 1, "number of sites to plan")
+	planNewCmd.Flags().String("supernet", "10.0.0.0/8", "network to plan the sites in")
+	planNewCmd.Flags().StringP("name", "n", "", "name of the plan")
+	planNewCmd.Flags().Bool("force", false, "replace an existing plan file")
+	planNewCmd.Flags().Bool("list-templates", false, "list the available templates and exit")
+	planNewCmd.Flags().String("from", "", "create the plan from a json hierarchy of blocks instead of a template, - for stdin")
+	planNewCmd.MarkFlagsMutuallyExclusive("from", "template")
+	planNewCmd.MarkFlagsMutuallyExclusive("from", "site")
+	planNewCmd.MarkFlagsMutuallyExclusive("from", "supernet")
+	planNewCmd.RegisterFlagCompletionFunc("template", func(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		templates, err := loadTemplates(templateDir(cmd))
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return templateNames(templates), cobra.ShellCompDirectiveNoFileComp
+	})
+}
@@ -0,0 +1,97 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/JakeTRogers/subnetCalc/formatter"
+	"github.com/spf13/cobra"
+)
+
+// build metadata injected at build time, see .goreleaser.yaml:
+//
+//	go build -ldflags "-X github.com/JakeTRogers/subnetCalc/cmd.version=v1.2.3 -X github.com/JakeTRogers/subnetCalc/cmd.commit=abc123"
+var (
+	version string // overrides the version in rootCmd when set
+	commit  string
+	date    string
+)
+
+// buildInfo describes the running binary.
+type buildInfo struct {
+	Version       string `json:"version"`
+	Commit        string `json:"commit"`
+	Date          string `json:"date"`
+	GoVersion     string `json:"goVersion"`
+	Platform      string `json:"platform"`
+	SchemaVersion int    `json:"schemaVersion"`
+}
+
+// newBuildInfo returns the build metadata, falling back to the version control details recorded by the go toolchain
+// when they weren't injected with ldflags.
+func newBuildInfo() buildInfo {
+	info := buildInfo{
+		Version:       rootCmd.Version,
+		Commit:        commit,
+		Date:          date,
+		GoVersion:     runtime.Version(),
+		Platform:      runtime.GOOS + "/" + runtime.GOARCH,
+		SchemaVersion: formatter.SchemaVersion,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.Date == "":
+				info.Date = s.Value
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.Date == "" {
+		info.Date = "unknown"
+	}
+	return info
+}
+
+// versionCmd represents the version command
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "print the version and build details",
+	Long: `Print the version of subnetCalc along with the git commit and date it was built from, the Go version, and the JSON
+schema version of its output.
+
+Examples:
+  # Print the version details for automation:
+  subnetCalc version --json
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		info := newBuildInfo()
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			printJSON(info)
+			return
+		}
+		fmt.Println("subnetCalc", info.Version)
+		fmt.Println("  commit:        ", info.Commit)
+		fmt.Println("  built:         ", info.Date)
+		fmt.Println("  go version:    ", info.GoVersion)
+		fmt.Println("  platform:      ", info.Platform)
+		fmt.Println("  schema version:", info.SchemaVersion)
+	},
+}
+
+func init() {
+	if version != "" {
+		rootCmd.Version = version
+	}
+	rootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().BoolP("json", "j", false, "output the version details in json format")
+}
@@ -0,0 +1,206 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"math/bits"
+	"math/rand"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/JakeTRogers/subnetCalc/utils"
+	"github.com/spf13/cobra"
+)
+
+// difficulties lists the accepted --difficulty values, easiest first.
+var difficulties = []string{"easy", "medium", "hard"}
+
+// exercise is one worksheet problem and its answers, in the order they are asked for.
+type exercise struct {
+	Question string
+	Answers  [][2]string // label and value
+}
+
+// privateBlocks are the RFC 1918 networks exercises draw their addresses from.
+var privateBlocks = []netip.Prefix{
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+}
+
+// randomHost returns a random address from one of the private blocks.
+func randomHost(r *rand.Rand) netip.Addr {
+	block := privateBlocks[r.Intn(len(privateBlocks))]
+	b := block.Addr().As4()
+	host := r.Uint32() & (1<<(32-block.Bits()) - 1)
+	for i := 0; i < 4; i++ {
+		b[3-i] |= byte(host >> (8 * i))
+	}
+	return netip.AddrFrom4(b)
+}
+
+// hostExercise asks for the details of the network a host is in. Harder exercises use any prefix length, sometimes
+// give the mask in dotted decimal form, and ask for more details.
+func hostExercise(r *rand.Rand, difficulty string) exercise {
+	minBits := 24
+	if difficulty != "easy" {
+		minBits = 16
+	}
+	host := randomHost(r)
+	p := netip.PrefixFrom(host, minBits+r.Intn(30-minBits+1))
+	n := subnet.NewNetworkFromPrefix(p)
+
+	given := p.String()
+	if difficulty != "easy" && r.Intn(2) == 0 {
+		given = fmt.Sprintf("%v with mask %v", host, n.SubnetMask)
+	}
+	asked := "network address, broadcast address, and number of usable hosts"
+	if difficulty != "easy" {
+		asked = "network address, broadcast address, number of usable hosts, and first and last usable host"
+	}
+	e := exercise{
+		Question: fmt.Sprintf("A host is configured as %s. Find its %s.", given, asked),
+		Answers: [][2]string{
+			{"network", n.NetworkAddr.String()},
+			{"broadcast", n.BroadcastAddr.String()},
+			{"usable hosts", formatBigInt(n.MaxHosts)},
+		},
+	}
+	if difficulty != "easy" {
+		e.Answers = append(e.Answers, [2]string{"first host", n.FirstHostIP.String()}, [2]string{"last host", n.LastHostIP.String()})
+	}
+	return e
+}
+
+// splitExercise asks for the prefix length that fits a number of hosts per subnet, how many such subnets a network
+// holds, and the range of one of them.
+func splitExercise(r *rand.Rand) exercise {
+	super := netip.PrefixFrom(randomHost(r), 16+r.Intn(9)).Masked()
+	// the subnets are at least one bit longer than the network, and at most a /30
+	sizeBits := 2 + r.Intn(32-super.Bits()-3)
+	hosts := 1<<(sizeBits-1) - 1 + r.Intn(1<<(sizeBits-1))
+	bitLen := 32 - bits.Len(uint(hosts+1))
+	n := subnet.NewNetworkFromPrefix(super)
+	count := n.SubnetCount(bitLen)
+	nth := 1 + r.Intn(int(min(count.Int64(), 16)))
+	subnets, _ := n.SubnetsOf(bitLen, big.NewInt(int64(nth-1)), big.NewInt(1), 1)
+	s := subnets[0]
+
+	return exercise{
+		Question: fmt.Sprintf("Divide %v into equal subnets of at least %d usable hosts each, using as few addresses as "+
+			"possible. Find the prefix length, the number of subnets, and the range of usable hosts in subnet number %d.", super, hosts, nth),
+		Answers: [][2]string{
+			{"prefix length", fmt.Sprintf("/%d", bitLen)},
+			{"subnets", formatBigInt(count)},
+			{fmt.Sprintf("subnet %d", nth), fmt.Sprintf("%v, hosts %v to %v", s.CIDR, s.FirstHostIP, s.LastHostIP)},
+		},
+	}
+}
+
+// newExercises returns count exercises of the given difficulty. Hard worksheets mix in subnet splitting.
+func newExercises(r *rand.Rand, count int, difficulty string) []exercise {
+	exercises := make([]exercise, count)
+	for i := range exercises {
+		if difficulty == "hard" && i%2 == 1 {
+			exercises[i] = splitExercise(r)
+		} else {
+			exercises[i] = hostExercise(r, difficulty)
+		}
+	}
+	return exercises
+}
+
+// writeWorksheet writes the exercises as a Markdown worksheet with room for working.
+func writeWorksheet(w io.Writer, exercises []exercise, difficulty string, seed int64) {
+	fmt.Fprintf(w, "# Subnetting Worksheet (%s)\n\nName: ______________________  Date: ____________\n", difficulty)
+	for i, e := range exercises {
+		fmt.Fprintf(w, "\n%d. %s\n\n", i+1, e.Question)
+		for _, a := range e.Answers {
+			fmt.Fprintf(w, "   - %s: ______________________\n", a[0])
+		}
+	}
+	fmt.Fprintf(w, "\n<sub>seed %d</sub>\n", seed)
+}
+
+// writeAnswerKey writes the answers to the exercises as Markdown.
+func writeAnswerKey(w io.Writer, exercises []exercise, difficulty string, seed int64) {
+	fmt.Fprintf(w, "# Answer Key (%s, seed %d)\n", difficulty, seed)
+	for i, e := range exercises {
+		fmt.Fprintf(w, "\n%d. %s\n\n", i+1, e.Question)
+		for _, a := range e.Answers {
+			fmt.Fprintf(w, "   - %s: **%s**\n", a[0], a[1])
+		}
+	}
+}
+
+// worksheetCmd represents the worksheet command
+var worksheetCmd = &cobra.Command{
+	Use:   "worksheet",
+	Short: "generate subnetting exercises and an answer key",
+	Long: `Generate a printable worksheet of subnetting exercises in Markdown, with a separate answer key.
+
+Easy exercises ask for the network, broadcast, and host count of a /24 to /30 network. Medium exercises use any prefix
+from /16 to /30, sometimes give the mask in dotted decimal form, and also ask for the first and last host. Hard
+worksheets add exercises on dividing a network into subnets of a given size.
+
+The answer key is written to --answer-key, or after a page break at the end of the worksheet. Pass the seed printed at
+the bottom of a worksheet to --seed to generate the same exercises again.
+
+Examples:
+  # Ten medium exercises, with the answers in their own file:
+  subnetCalc worksheet --count 10 --difficulty medium --answer-key answers.md > worksheet.md
+
+  # Convert to PDF with pandoc:
+  subnetCalc worksheet --difficulty hard | pandoc -o worksheet.pdf
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := cmd.Flags()
+		count, _ := flags.GetInt("count")
+		difficulty, _ := flags.GetString("difficulty")
+		seed, _ := flags.GetInt64("seed")
+		keyFile, _ := flags.GetString("answer-key")
+		if count < 1 {
+			utils.Log.Fatal().Msgf("count must be positive, got %d", count)
+		}
+		if !slices.Contains(difficulties, difficulty) {
+			utils.Log.Fatal().Msgf("invalid difficulty %q, expected one of: %s", difficulty, strings.Join(difficulties, ", "))
+		}
+		if !flags.Changed("seed") {
+			seed = time.Now().UnixNano()
+		}
+
+		exercises := newExercises(rand.New(rand.NewSource(seed)), count, difficulty)
+		writeWorksheet(os.Stdout, exercises, difficulty, seed)
+		if keyFile == "" {
+			fmt.Print("\n<div style=\"page-break-before: always\"></div>\n\n")
+			writeAnswerKey(os.Stdout, exercises, difficulty, seed)
+			return
+		}
+		f, err := os.Create(keyFile)
+		if err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+		writeAnswerKey(f, exercises, difficulty, seed)
+		if err := f.Close(); err != nil {
+			utils.Log.Fatal().Msg(err.Error())
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(worksheetCmd)
+	worksheetCmd.Flags().IntP("count", "c", 10, "number of exercises")
+	worksheetCmd.Flags().StringP("difficulty", "d", "medium", "difficulty of the exercises: "+strings.Join(difficulties, ", "))
+	worksheetCmd.Flags().Int64("seed", 0, "seed for the random exercises, to generate the same worksheet again")
+	worksheetCmd.Flags().StringP("answer-key", "a", "", "write the answer key to this file instead of after the worksheet")
+	worksheetCmd.RegisterFlagCompletionFunc("difficulty", completeList(difficulties, false))
+}
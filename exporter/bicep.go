@@ -0,0 +1,54 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package exporter
+
+import (
+	"io"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// bicepString quotes s as a Bicep string literal.
+func bicepString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// Bicep writes the plan's allocations as an Azure Bicep subnet array, deployed as child resources of an existing VNet
+// whose name is passed in the parameter named after opts.NetworkRef. Containers are left out, as are reserved and
+// deprecated allocations unless opts.IncludeReserved is set, and each dual-stack pair becomes one subnet with both
+// prefixes in addressPrefixes.
+func Bicep(w io.Writer, p subnet.Plan, opts Options) error {
+	subnets := deployables(forDeployment(p, opts))
+	ref := identifier(opts.NetworkRef, "VnetName")
+	ref = strings.ToLower(ref[:1]) + ref[1:]
+	out := &errWriter{w: w}
+
+	out.printf("param %s string\n\n", ref)
+	out.printf("var subnets = [\n")
+	ids := uniqueIdentifiers(subnets, "Subnet")
+	for i, d := range subnets {
+		name := d.Name
+		if name == "" {
+			name = ids[i]
+		}
+		out.printf("  {\n    name: %s\n    properties: {\n", bicepString(name))
+		if prefixes := d.prefixes(); len(prefixes) == 1 {
+			out.printf("      addressPrefix: %s\n", bicepString(opts.Prefix(prefixes[0])))
+		} else {
+			out.printf("      addressPrefixes: [\n")
+			for _, prefix := range prefixes {
+				out.printf("        %s\n", bicepString(opts.Prefix(prefix)))
+			}
+			out.printf("      ]\n")
+		}
+		out.printf("    }\n  }\n")
+	}
+	out.printf("]\n\n")
+	out.printf("resource vnet 'Microsoft.Network/virtualNetworks@2023-09-01' existing = {\n  name: %s\n}\n\n", ref)
+	out.printf("@batchSize(1)\n")
+	out.printf("resource vnetSubnets 'Microsoft.Network/virtualNetworks/subnets@2023-09-01' = [for s in subnets: {\n")
+	out.printf("  parent: vnet\n  name: s.name\n  properties: s.properties\n}]\n")
+	return out.err
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package exporter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBicepString(t *testing.T) {
+	tests := []struct {
+		s, want string
+	}{
+		{"users", "'users'"},
+		{"it's", `'it\'s'`},
+		{`a\b`, `'a\\b'`},
+	}
+	for _, tt := range tests {
+		if got := bicepString(tt.s); got != tt.want {
+			t.Errorf("bicepString(%q) = %s, want %s", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestBicep(t *testing.T) {
+	var b strings.Builder
+	if err := Bicep(&b, testPlan(), Options{NetworkRef: "AppVnet"}); err != nil {
+		t.Fatal(err)
+	}
+	want := `param appVnet string
+
+var subnets = [
+  {
+    name: 'users'
+    properties: {
+      addressPrefixes: [
+        '10.0.0.0/24'
+        '2001:db8::/64'
+      ]
+    }
+  }
+  {
+    name: 'web'
+    properties: {
+      addressPrefix: '10.0.1.0/24'
+    }
+  }
+]
+
+resource vnet 'Microsoft.Network/virtualNetworks@2023-09-01' existing = {
+  name: appVnet
+}
+
+@batchSize(1)
+resource vnetSubnets 'Microsoft.Network/virtualNetworks/subnets@2023-09-01' = [for s in subnets: {
+  parent: vnet
+  name: s.name
+  properties: s.properties
+}]
+`
+	if got := b.String(); got != want {
+		t.Errorf("Bicep() =\n%s\nwant:\n%s", got, want)
+	}
+}
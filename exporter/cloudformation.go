@@ -0,0 +1,72 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// CloudFormation writes the plan's allocations as AWS::EC2::Subnet resources in a CloudFormation YAML template. The
+// VPC is referenced through a template parameter named after opts.NetworkRef. Containers are left out, as are reserved
+// and deprecated allocations unless opts.IncludeReserved is set, and each dual-stack pair becomes one subnet with both
+// an IPv4 and an IPv6 CIDR block.
+func CloudFormation(w io.Writer, p subnet.Plan, opts Options) error {
+	subnets := deployables(forDeployment(p, opts))
+	ref := identifier(opts.NetworkRef, "VpcId")
+	out := &errWriter{w: w}
+
+	out.printf("AWSTemplateFormatVersion: '2010-09-09'\n")
+	if p.Name != "" {
+		out.printf("Description: %s\n", strconv.Quote(p.Name))
+	}
+	out.printf("Parameters:\n  %s:\n    Type: AWS::EC2::VPC::Id\n", ref)
+	out.printf("Resources:\n")
+	for i, id := range uniqueIdentifiers(subnets, "Subnet") {
+		d := subnets[i]
+		out.printf("  %s:\n    Type: AWS::EC2::Subnet\n    Properties:\n", id)
+		out.printf("      VpcId: !Ref %s\n", ref)
+		if d.V4.IsValid() {
+			out.printf("      CidrBlock: %s\n", d.V4)
+		}
+		if d.V6.IsValid() {
+			out.printf("      Ipv6CidrBlock: %s\n", opts.Prefix(d.V6))
+		}
+		if !d.V4.IsValid() {
+			out.printf("      Ipv6Native: true\n")
+		}
+		if d.Zone != "" {
+			out.printf("      AvailabilityZone: %s\n", d.Zone)
+		}
+		if d.Name != "" || d.ID != "" {
+			out.printf("      Tags:\n")
+		}
+		if d.Name != "" {
+			out.printf("        - Key: Name\n          Value: %s\n", strconv.Quote(d.Name))
+		}
+		if d.ID != "" {
+			out.printf("        - Key: %s\n          Value: %s\n", IDTag, strconv.Quote(d.ID))
+		}
+		if d.PairID != "" {
+			out.printf("        - Key: %s\n          Value: %s\n", PairTag, strconv.Quote(d.PairID))
+		}
+	}
+	return out.err
+}
+
+// errWriter remembers the first error returned by the underlying writer so templates can be written without checking
+// every call.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) printf(format string, a ...any) {
+	if e.err == nil {
+		_, e.err = fmt.Fprintf(e.w, format, a...)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package exporter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCloudFormation(t *testing.T) {
+	var b strings.Builder
+	if err := CloudFormation(&b, testPlan(), Options{NetworkRef: "app-vpc"}); err != nil {
+		t.Fatal(err)
+	}
+	want := `AWSTemplateFormatVersion: '2010-09-09'
+Description: "site"
+Parameters:
+  AppVpc:
+    Type: AWS::EC2::VPC::Id
+Resources:
+  UsersSubnet:
+    Type: AWS::EC2::Subnet
+    Properties:
+      VpcId: !Ref AppVpc
+      CidrBlock: 10.0.0.0/24
+      Ipv6CidrBlock: 2001:db8::/64
+      AvailabilityZone: az1
+      Tags:
+        - Key: Name
+          Value: "users"
+        - Key: subnetcalc-id
+          Value: "v4"
+        - Key: subnetcalc-pair-id
+          Value: "v6"
+  WebSubnet:
+    Type: AWS::EC2::Subnet
+    Properties:
+      VpcId: !Ref AppVpc
+      CidrBlock: 10.0.1.0/24
+      Tags:
+        - Key: Name
+          Value: "web"
+        - Key: subnetcalc-id
+          Value: "web"
+`
+	if got := b.String(); got != want {
+		t.Errorf("CloudFormation() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCloudFormationIPv6Only(t *testing.T) {
+	p := testPlan()
+	p.Allocations = p.Allocations[1:2]
+	p.Allocations[0].Pair = ""
+	var b strings.Builder
+	if err := CloudFormation(&b, p, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"VpcId: !Ref VpcId\n", "Ipv6CidrBlock: 2001:db8::/64\n      Ipv6Native: true\n"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("CloudFormation() of an IPv6 only subnet is missing %q:\n%s", want, b.String())
+		}
+	}
+	if strings.Contains(b.String(), "CidrBlock: 10.") {
+		t.Errorf("CloudFormation() of an IPv6 only subnet has an IPv4 CIDR block:\n%s", b.String())
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+
+// Package exporter renders subnetCalc plans as configuration for other tools.
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+	"unicode"
+
+	"github.com/JakeTRogers/subnetCalc/formatter"
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// IDTag is the tag or attribute name used to carry an allocation's stable ID into other tools.
+const IDTag = "subnetcalc-id"
+
+// PairTag is the tag used to carry the ID of the other allocation of a dual-stack pair exported as one subnet.
+const PairTag = "subnetcalc-pair-id"
+
+// Options holds the settings shared by all exporters.
+type Options struct {
+	// NetworkRef is the name of the parameter referencing the VPC or VNet the subnets belong to.
+	NetworkRef string
+	// IncludeReserved exports reserved and deprecated allocations to cloud templates along with allocated ones, e.g. to
+	// keep deprecated subnets in a stack until they are freed.
+	IncludeReserved bool
+	// RenderOptions controls how addresses are written.
+	formatter.RenderOptions
+}
+
+// Exporter writes a plan's allocations to w in a tool specific format.
+type Exporter func(w io.Writer, p subnet.Plan, opts Options) error
+
+// Exporters maps format names to their exporters.
+var Exporters = map[string]Exporter{
+	"bicep":          Bicep,
+	"cloudformation": CloudFormation,
+	"infoblox":       Infoblox,
+	"list":           List,
+}
+
+// identifier converts name into a PascalCase identifier containing only letters and digits.
+// returns fallback if name contains no usable characters.
+func identifier(name, fallback string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return fallback
+	}
+	return b.String()
+}
+
+// forDeployment returns the plan with only the allocations to create as subnets. Containers, which group allocations
+// rather than being networks of their own, are always left out. Reserved allocations, such as the blocks kept free for
+// growth, and deprecated ones are left out unless opts.IncludeReserved is set.
+func forDeployment(p subnet.Plan, opts Options) subnet.Plan {
+	var allocations []subnet.Allocation
+	for _, a := range p.Allocations {
+		switch a.Status {
+		case subnet.StatusContainer:
+			continue
+		case subnet.StatusReserved, subnet.StatusDeprecated:
+			if !opts.IncludeReserved {
+				continue
+			}
+		}
+		allocations = append(allocations, a)
+	}
+	p.Allocations = allocations
+	return p
+}
+
+// deployable is a subnet to create with a cloud template: an allocation merged with the partner in the other address
+// family it was planned with, if any, so a dual-stack pair becomes one subnet with both prefixes. The name, zone, and
+// ID are those of whichever allocation of the pair comes first in the plan.
+type deployable struct {
+	subnet.Allocation
+	V4, V6 netip.Prefix // the subnet's prefix in each family, invalid if it has none
+	PairID string       // ID of the merged partner, if any
+}
+
+// prefixes returns the subnet's prefixes, IPv4 first.
+func (d deployable) prefixes() []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, p := range []netip.Prefix{d.V4, d.V6} {
+		if p.IsValid() {
+			prefixes = append(prefixes, p)
+		}
+	}
+	return prefixes
+}
+
+// deployables returns the plan's allocations as subnets to create, merging each dual-stack pair whose allocations
+// name each other into one subnet.
+func deployables(p subnet.Plan) []deployable {
+	byID := map[string]subnet.Allocation{}
+	for _, a := range p.Allocations {
+		if a.ID != "" {
+			byID[a.ID] = a
+		}
+	}
+	var out []deployable
+	merged := map[string]bool{}
+	for _, a := range p.Allocations {
+		if a.ID != "" && merged[a.ID] {
+			continue
+		}
+		d := deployable{Allocation: a}
+		if a.CIDR.Addr().Is4() {
+			d.V4 = a.CIDR
+		} else {
+			d.V6 = a.CIDR
+		}
+		if partner, ok := byID[a.Pair]; ok && partner.Pair == a.ID && partner.CIDR.Addr().Is4() != a.CIDR.Addr().Is4() {
+			if partner.CIDR.Addr().Is4() {
+				d.V4 = partner.CIDR
+			} else {
+				d.V6 = partner.CIDR
+			}
+			d.PairID = partner.ID
+			merged[partner.ID] = true
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// uniqueIdentifiers returns an identifier for every subnet, adding numeric suffixes to duplicates.
+func uniqueIdentifiers(subnets []deployable, suffix string) []string {
+	ids := make([]string, len(subnets))
+	seen := map[string]int{}
+	for i, d := range subnets {
+		id := identifier(d.Name, "") + suffix
+		seen[id]++
+		if seen[id] > 1 {
+			id = fmt.Sprintf("%s%d", id, seen[id])
+		}
+		ids[i] = id
+	}
+	return ids
+}
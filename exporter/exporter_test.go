@@ -0,0 +1,94 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package exporter
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// testPlan returns a plan with a dual-stack pair named users, an IPv4 allocation named web, a container, a block
+// reserved for growth, and a deprecated allocation.
+func testPlan() subnet.Plan {
+	return subnet.Plan{
+		Name:      "site",
+		Supernets: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/16"), netip.MustParsePrefix("2001:db8::/48")},
+		Allocations: []subnet.Allocation{
+			{ID: "v4", CIDR: netip.MustParsePrefix("10.0.0.0/24"), Name: "users", Zone: "az1", Pair: "v6"},
+			{ID: "v6", CIDR: netip.MustParsePrefix("2001:db8::/64"), Name: "users", Zone: "az1", Pair: "v4"},
+			{ID: "web", CIDR: netip.MustParsePrefix("10.0.1.0/24"), Name: "web"},
+			{ID: "all", CIDR: netip.MustParsePrefix("10.0.0.0/20"), Name: "all", Status: subnet.StatusContainer},
+			{ID: "growth", CIDR: netip.MustParsePrefix("10.0.2.0/23"), Name: "web", Status: subnet.StatusReserved},
+			{ID: "old", CIDR: netip.MustParsePrefix("10.0.4.0/24"), Name: "old", Status: subnet.StatusDeprecated},
+		},
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	tests := []struct {
+		name, fallback, want string
+	}{
+		{"users", "X", "Users"},
+		{"app-db 01", "X", "AppDb01"},
+		{"vpc_id", "X", "VpcId"},
+		{"--", "X", "X"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if got := identifier(tt.name, tt.fallback); got != tt.want {
+			t.Errorf("identifier(%q, %q) = %q, want %q", tt.name, tt.fallback, got, tt.want)
+		}
+	}
+}
+
+func TestForDeployment(t *testing.T) {
+	tests := []struct {
+		opts Options
+		want []string
+	}{
+		{Options{}, []string{"v4", "v6", "web"}},
+		{Options{IncludeReserved: true}, []string{"v4", "v6", "web", "growth", "old"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, a := range forDeployment(testPlan(), tt.opts).Allocations {
+			got = append(got, a.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("forDeployment(IncludeReserved: %t) = %v, want %v", tt.opts.IncludeReserved, got, tt.want)
+		}
+	}
+}
+
+func TestDeployables(t *testing.T) {
+	v4, v6 := netip.MustParsePrefix("10.0.0.0/24"), netip.MustParsePrefix("2001:db8::/64")
+	tests := []struct {
+		name        string
+		allocations []subnet.Allocation
+		want        [][2]netip.Prefix
+	}{
+		{"pair", []subnet.Allocation{{ID: "a", CIDR: v4, Pair: "b"}, {ID: "b", CIDR: v6, Pair: "a"}},
+			[][2]netip.Prefix{{v4, v6}}},
+		{"ipv6 first", []subnet.Allocation{{ID: "b", CIDR: v6, Pair: "a"}, {ID: "a", CIDR: v4, Pair: "b"}},
+			[][2]netip.Prefix{{v4, v6}}},
+		{"one-sided pair", []subnet.Allocation{{ID: "a", CIDR: v4, Pair: "b"}, {ID: "b", CIDR: v6}},
+			[][2]netip.Prefix{{v4, {}}, {{}, v6}}},
+		{"missing partner", []subnet.Allocation{{ID: "a", CIDR: v4, Pair: "b"}}, [][2]netip.Prefix{{v4, {}}}},
+		{"same family", []subnet.Allocation{{ID: "a", CIDR: v4, Pair: "b"}, {ID: "b", CIDR: netip.MustParsePrefix("10.0.1.0/24"), Pair: "a"}},
+			[][2]netip.Prefix{{v4, {}}, {netip.MustParsePrefix("10.0.1.0/24"), {}}}},
+		{"no ids", []subnet.Allocation{{CIDR: v4}, {CIDR: v6}}, [][2]netip.Prefix{{v4, {}}, {{}, v6}}},
+	}
+	for _, tt := range tests {
+		var got [][2]netip.Prefix
+		for _, d := range deployables(subnet.Plan{Allocations: tt.allocations}) {
+			got = append(got, [2]netip.Prefix{d.V4, d.V6})
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: deployables() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
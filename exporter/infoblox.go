@@ -0,0 +1,75 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// Infoblox writes the plan's allocations in the CSV layout used by Infoblox bulk network imports. Allocation names
+// become comments, and labels and allocation IDs become extensible attribute columns. IPv4 and IPv6 networks use
+// separate header rows.
+func Infoblox(w io.Writer, p subnet.Plan, opts Options) error {
+	var keys []string
+	seen := map[string]bool{}
+	hasIDs := false
+	for _, a := range p.Allocations {
+		hasIDs = hasIDs || a.ID != ""
+		for k := range a.Labels {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	cw := csv.NewWriter(w)
+	for _, v4 := range []bool{true, false} {
+		headerWritten := false
+		for _, a := range p.Allocations {
+			if a.CIDR.Addr().Is4() != v4 {
+				continue
+			}
+			if !headerWritten {
+				header := []string{"header-network", "address*", "netmask*", "comment"}
+				if !v4 {
+					header = []string{"header-ipv6network", "address*", "cidr*", "comment"}
+				}
+				for _, k := range keys {
+					header = append(header, "EA-"+k)
+				}
+				if hasIDs {
+					header = append(header, "EA-"+IDTag)
+				}
+				if err := cw.Write(header); err != nil {
+					return err
+				}
+				headerWritten = true
+			}
+
+			row := []string{"ipv6network", opts.Addr(a.CIDR.Addr()), fmt.Sprint(a.CIDR.Bits()), a.Name}
+			if v4 {
+				mask := subnet.MaskFromBits(a.CIDR.Bits(), 32)
+				row = []string{"network", a.CIDR.Addr().String(), mask.String(), a.Name}
+			}
+			for _, k := range keys {
+				row = append(row, a.Labels[k])
+			}
+			if hasIDs {
+				row = append(row, a.ID)
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
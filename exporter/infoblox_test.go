@@ -0,0 +1,53 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package exporter
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/JakeTRogers/subnetCalc/formatter"
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+func TestInfoblox(t *testing.T) {
+	tests := []struct {
+		name        string
+		allocations []subnet.Allocation
+		opts        Options
+		want        string
+	}{
+		{"ipv4", []subnet.Allocation{
+			{CIDR: netip.MustParsePrefix("10.0.0.0/24"), Name: "web"},
+			{CIDR: netip.MustParsePrefix("10.0.1.0/31"), Name: "link, east"},
+		}, Options{}, `header-network,address*,netmask*,comment
+network,10.0.0.0,255.255.255.0,web
+network,10.0.1.0,255.255.255.254,"link, east"
+`},
+		{"labels and ids", []subnet.Allocation{
+			{ID: "v6", CIDR: netip.MustParsePrefix("2001:db8::/64"), Name: "users", Labels: map[string]string{"site": "nyc"}},
+			{ID: "v4", CIDR: netip.MustParsePrefix("10.0.0.0/20"), Name: "users", Labels: map[string]string{"env": "prod"}},
+		}, Options{}, `header-network,address*,netmask*,comment,EA-env,EA-site,EA-subnetcalc-id
+network,10.0.0.0,255.255.240.0,users,prod,,v4
+header-ipv6network,address*,cidr*,comment,EA-env,EA-site,EA-subnetcalc-id
+ipv6network,2001:db8::,64,users,,nyc,v6
+`},
+		{"expanded ipv6", []subnet.Allocation{{CIDR: netip.MustParsePrefix("2001:db8::/48")}},
+			Options{RenderOptions: formatter.RenderOptions{ExpandIPv6: true}}, `header-ipv6network,address*,cidr*,comment
+ipv6network,2001:0db8:0000:0000:0000:0000:0000:0000,48,
+`},
+		{"empty", nil, Options{}, ""},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		if err := Infoblox(&b, subnet.Plan{Allocations: tt.allocations}, tt.opts); err != nil {
+			t.Errorf("%s: Infoblox() error = %v", tt.name, err)
+			continue
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("%s: Infoblox() =\n%s\nwant:\n%s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// List writes the plan's allocations as a plain text list, one prefix per line followed by its name, key=value fields
+// for its ID, status, zone, VLAN, pair, and labels, and its note as a # comment. importer.ReadList reads the list back
+// into the same allocations.
+func List(w io.Writer, p subnet.Plan, opts Options) error {
+	for _, a := range p.Allocations {
+		fields := []string{opts.Prefix(a.CIDR)}
+		if a.Name != "" {
+			fields = append(fields, a.Name)
+		}
+		vlan := ""
+		if a.VLAN != 0 {
+			vlan = fmt.Sprint(a.VLAN)
+		}
+		for _, kv := range [][2]string{{"id", a.ID}, {"status", a.Status}, {"zone", a.Zone}, {"vlan", vlan}, {"pair", a.Pair}} {
+			if kv[1] != "" {
+				fields = append(fields, kv[0]+"="+kv[1])
+			}
+		}
+		keys := make([]string, 0, len(a.Labels))
+		for k := range a.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fields = append(fields, k+"="+a.Labels[k])
+		}
+		if a.Note != "" {
+			fields = append(fields, "# "+a.Note)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(fields, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
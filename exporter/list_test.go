@@ -0,0 +1,60 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package exporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/JakeTRogers/subnetCalc/importer"
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+func TestList(t *testing.T) {
+	var b strings.Builder
+	if err := List(&b, testPlan(), Options{}); err != nil {
+		t.Fatal(err)
+	}
+	want := `10.0.0.0/24 users id=v4 zone=az1 pair=v6
+2001:db8::/64 users id=v6 zone=az1 pair=v4
+10.0.1.0/24 web id=web
+10.0.0.0/20 all id=all status=container
+10.0.2.0/23 web id=growth status=reserved
+10.0.4.0/24 old id=old status=deprecated
+`
+	if got := b.String(); got != want {
+		t.Fatalf("List() =\n%s\nwant:\n%s", got, want)
+	}
+
+	// reading the list back gives the same allocations
+	got, err := importer.ReadList(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := testPlan().Allocations; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadList(List()) = %+v, want %+v", got, want)
+	}
+}
+
+func TestListRoundTrip(t *testing.T) {
+	tests := []string{
+		"10.0.0.0/24 core net id=a1 status=reserved zone=az1 vlan=40 pair=b1 env=prod team=net # owned by netops\n",
+		"2001:db8::/64\n",
+		"10.0.1.0/24 vlan=7\n",
+	}
+	for _, list := range tests {
+		allocations, err := importer.ReadList(strings.NewReader(list))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b strings.Builder
+		if err := List(&b, subnet.Plan{Allocations: allocations}, Options{}); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != list {
+			t.Errorf("List(ReadList(%q)) = %q", list, b.String())
+		}
+	}
+}
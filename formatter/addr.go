@@ -0,0 +1,143 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package formatter
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// RenderOptions controls how values are converted to text by every formatter.
+type RenderOptions struct {
+	// ExpandIPv6 writes IPv6 addresses with every group present and zero padded, instead of the RFC 5952 canonical
+	// compressed form. Some vendor import formats reject compressed addresses.
+	ExpandIPv6 bool
+	// RawNumbers writes numbers without grouping digits with commas, for machine readable formats.
+	RawNumbers bool
+	// ASCII restricts output to ASCII characters, drawing tables with +, -, and | and replacing symbols such as the
+	// squares in the depth legend.
+	ASCII bool
+	// Deterministic produces byte-for-byte reproducible output for golden tests and documentation snippets: tables use
+	// ASCII borders without colors and ignore the terminal width, and JSON metadata omits the timestamp and host name.
+	Deterministic bool
+	// LegacyBroadcast labels the last address of IPv6 networks as their broadcast address, and writes it under the
+	// broadcastAddr key in JSON and MessagePack, for consumers written before IPv6 networks used the Last Address
+	// label and lastAddr key.
+	LegacyBroadcast bool
+	// Binary adds the network address, subnet mask, and last address in binary to the network details, with the
+	// boundary between the network and host bits marked, the way ipcalc shows them.
+	Binary bool
+}
+
+// lastAddrLabel returns the label for the last address of n: Broadcast Address for IPv4, and Last Address for networks
+// without a broadcast address, such as IPv6 networks and point-to-point links, unless LegacyBroadcast is set.
+func (o RenderOptions) lastAddrLabel(n subnet.Network) string {
+	if !n.HasBroadcast() && !o.LegacyBroadcast {
+		return "Last Address"
+	}
+	return "Broadcast Address"
+}
+
+// lastAddrKey returns the JSON and MessagePack key holding the last address of n.
+func (o RenderOptions) lastAddrKey(n subnet.Network) string {
+	if !n.HasBroadcast() && !o.LegacyBroadcast {
+		return "lastAddr"
+	}
+	return "broadcastAddr"
+}
+
+// Number returns the text form of n.
+func (o RenderOptions) Number(n uint) string {
+	if o.RawNumbers {
+		return fmt.Sprint(n)
+	}
+	return message.NewPrinter(language.English).Sprint(n)
+}
+
+// BigNumber formats an arbitrarily large integer with commas separating each group of three digits, unless raw numbers
+// are requested.
+func (o RenderOptions) BigNumber(n *big.Int) string {
+	digits := n.String()
+	if o.RawNumbers {
+		return digits
+	}
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
+
+// Percent formats a fraction from 0 to 1 as a percentage, without the percent sign when raw numbers are requested.
+// Unset fractions are left blank.
+func (o RenderOptions) Percent(fraction *float64) string {
+	if fraction == nil {
+		return ""
+	}
+	s := strconv.FormatFloat(*fraction*100, 'f', 1, 64)
+	if o.RawNumbers {
+		return s
+	}
+	return s + "%"
+}
+
+// Addr returns the text form of a. IPv6 addresses use the RFC 5952 canonical form unless ExpandIPv6 is set.
+func (o RenderOptions) Addr(a netip.Addr) string {
+	if o.ExpandIPv6 && a.Is6() && !a.Is4In6() {
+		return a.StringExpanded()
+	}
+	return a.String()
+}
+
+// BinaryAddr returns a in binary, with the octets of IPv4 addresses separated by dots and the 16 bit groups of IPv6
+// addresses by colons, and a | marking the boundary after the first bits network bits. The boundary isn't marked for
+// host prefixes, which have no host bits.
+func BinaryAddr(a netip.Addr, bits int) string {
+	groupBits := 8
+	sep := "."
+	if a.Is6() {
+		groupBits, sep = 16, ":"
+	}
+	var b strings.Builder
+	for i, bit := range a.AsSlice() {
+		for j := 7; j >= 0; j-- {
+			pos := i*8 + 7 - j
+			switch {
+			case pos == bits:
+				b.WriteByte('|')
+			case pos > 0 && pos%groupBits == 0:
+				b.WriteString(sep)
+			}
+			b.WriteByte('0' + bit>>j&1)
+		}
+	}
+	return b.String()
+}
+
+// binaryRows returns the labelled binary forms of n's network address, subnet mask, and last address.
+func (o RenderOptions) binaryRows(n subnet.Network) [][2]string {
+	return [][2]string{
+		{"Network", BinaryAddr(n.NetworkAddr, n.MaskBits)},
+		{"Subnet Mask", BinaryAddr(n.SubnetMask, n.MaskBits)},
+		{o.lastAddrLabel(n), BinaryAddr(n.BroadcastAddr, n.MaskBits)},
+	}
+}
+
+// Prefix returns the text form of p, formatting the address with Addr.
+func (o RenderOptions) Prefix(p netip.Prefix) string {
+	if !p.IsValid() {
+		return p.String()
+	}
+	return fmt.Sprintf("%s/%d", o.Addr(p.Addr()), p.Bits())
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package formatter
+
+import (
+	"fmt"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// Column describes a column of subnet details that can be included in tabular output.
+type Column struct {
+	Header string
+	Value  func(i int, n subnet.Network, o RenderOptions) string
+}
+
+// ipv6Headers replaces the headers of columns whose IPv4 names don't apply to networks without a broadcast address.
+var ipv6Headers = map[string]string{"BROADCAST": "LAST ADDRESS"}
+
+// header returns the header of column c in a table of n's subnets. A table with any subnet lacking a broadcast
+// address, such as a /31 or /32 among larger subnets, uses the header for networks without one on every row.
+func (o RenderOptions) header(c Column, n subnet.Network) string {
+	if h, ok := ipv6Headers[c.Header]; ok && !o.LegacyBroadcast && (!n.HasBroadcast() || anyWithoutBroadcast(n.Subnets)) {
+		return h
+	}
+	return c.Header
+}
+
+// anyWithoutBroadcast reports whether any subnet in subnets has no broadcast address.
+func anyWithoutBroadcast(subnets []subnet.Network) bool {
+	for _, s := range subnets {
+		if !s.HasBroadcast() {
+			return true
+		}
+	}
+	return false
+}
+
+// Columns maps the names accepted by --columns to column definitions.
+var Columns = map[string]Column{
+	"index": {"#", func(i int, n subnet.Network, _ RenderOptions) string {
+		if n.Index != nil {
+			return n.Index.String()
+		}
+		return fmt.Sprint(i + 1)
+	}},
+	"subnet": {"SUBNET", func(_ int, n subnet.Network, o RenderOptions) string { return o.Prefix(n.CIDR) }},
+	"first":  {"FIRST IP", func(_ int, n subnet.Network, o RenderOptions) string { return o.Addr(n.FirstHostIP) }},
+	"last":   {"LAST IP", func(_ int, n subnet.Network, o RenderOptions) string { return o.Addr(n.LastHostIP) }},
+	"range": {"RANGE", func(_ int, n subnet.Network, o RenderOptions) string {
+		return o.Addr(n.FirstHostIP) + " - " + o.shortAddr(n.CIDR, n.LastHostIP)
+	}},
+	"broadcast": {"BROADCAST", func(_ int, n subnet.Network, o RenderOptions) string { return o.Addr(n.BroadcastAddr) }},
+	"gateway": {"GATEWAY", func(_ int, n subnet.Network, o RenderOptions) string {
+		if n.Gateway == nil {
+			return ""
+		}
+		return o.Addr(*n.Gateway)
+	}},
+	"anycast": {"ANYCAST", func(_ int, n subnet.Network, o RenderOptions) string {
+		if n.Anycast == nil {
+			return ""
+		}
+		return o.Addr(*n.Anycast)
+	}},
+	"hosts": {"HOSTS", func(_ int, n subnet.Network, o RenderOptions) string {
+		return o.BigNumber(n.MaxHosts)
+	}},
+	"mask":     {"MASK", func(_ int, n subnet.Network, o RenderOptions) string { return o.Addr(n.SubnetMask) }},
+	"wildcard": {"WILDCARD", func(_ int, n subnet.Network, o RenderOptions) string { return o.Addr(n.WildcardMask) }},
+	"class":    {"CLASSIFICATION", func(_ int, n subnet.Network, o RenderOptions) string { return subnet.Classify(n.CIDR) }},
+	"label":    {"LABEL", func(_ int, n subnet.Network, o RenderOptions) string { return n.Label }},
+	"status":   {"STATUS", func(_ int, n subnet.Network, o RenderOptions) string { return n.Status }},
+	"vlan":     {"VLAN", func(_ int, n subnet.Network, o RenderOptions) string { return vlanString(n.VLAN) }},
+	"util":     {"UTILIZATION", func(_ int, n subnet.Network, o RenderOptions) string { return o.Percent(n.Utilization) }},
+}
+
+// DefaultColumns is the column set used when none is requested.
+var DefaultColumns = []string{"index", "subnet", "first", "last", "broadcast", "hosts"}
+
+// ColumnNames lists every available column in display order.
+var ColumnNames = []string{"index", "subnet", "first", "last", "range", "broadcast", "gateway", "anycast", "hosts", "mask", "wildcard", "class", "label", "status", "vlan", "util"}
+
+// vlanString formats a VLAN ID, leaving unset IDs blank.
+func vlanString(id int) string {
+	if id == 0 {
+		return ""
+	}
+	return fmt.Sprint(id)
+}
+
+// lookupColumns returns the column definitions for names, or DefaultColumns if names is empty.
+// returns an error if any name is unknown.
+func lookupColumns(names []string) ([]Column, error) {
+	if len(names) == 0 {
+		names = DefaultColumns
+	}
+	cols := make([]Column, 0, len(names))
+	for _, name := range names {
+		c, ok := Columns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		cols = append(cols, c)
+	}
+	return cols, nil
+}
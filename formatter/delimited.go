@@ -0,0 +1,152 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package formatter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// delimitedRows returns the header and a row per subnet for the requested columns. If the network hasn't been split,
+// a single row describing the network itself is returned. Numbers are never grouped with commas.
+func delimitedRows(n subnet.Network, names []string, o RenderOptions) ([]string, [][]string, error) {
+	cols, err := lookupColumns(names)
+	if err != nil {
+		return nil, nil, err
+	}
+	o.RawNumbers = true
+
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = o.header(c, n)
+	}
+
+	networks := n.Subnets
+	if len(networks) == 0 {
+		networks = []subnet.Network{n}
+	}
+	rows := make([][]string, len(networks))
+	for i, s := range networks {
+		rows[i] = make([]string, len(cols))
+		for j, c := range cols {
+			rows[i][j] = c.Value(i, s, o)
+		}
+	}
+	return header, rows, nil
+}
+
+// TSVFormatter writes a network's subnets as tab-separated values with a header row.
+type TSVFormatter struct {
+	RenderOptions
+	Columns  []string // names of the columns to include, defaults to DefaultColumns
+	NoHeader bool     // leave out the header row
+}
+
+// tsvEscaper replaces the characters that would break a tab-separated row.
+var tsvEscaper = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+
+// Format writes the subnets of n, or n itself if it hasn't been split, to w.
+func (f TSVFormatter) Format(w io.Writer, n subnet.Network) error {
+	header, rows, err := delimitedRows(n, f.Columns, f.RenderOptions)
+	if err != nil {
+		return err
+	}
+	if !f.NoHeader {
+		rows = append([][]string{header}, rows...)
+	}
+	for _, row := range rows {
+		if err := writeTSVRow(w, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FormatStream writes the header and then a row for each subnet of n yielded by subnets, without holding them in
+// memory.
+func (f TSVFormatter) FormatStream(w io.Writer, n subnet.Network, subnets subnet.Seq) error {
+	bw := bufio.NewWriter(w)
+	err := streamRows(n, f.Columns, f.RenderOptions, f.NoHeader, subnets, func(row []string) error {
+		return writeTSVRow(bw, row)
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// writeTSVRow writes the fields of row to w separated by tabs, replacing any characters that would break the row.
+func writeTSVRow(w io.Writer, row []string) error {
+	for i := range row {
+		row[i] = tsvEscaper.Replace(row[i])
+	}
+	_, err := fmt.Fprintln(w, strings.Join(row, "\t"))
+	return err
+}
+
+// CSVFormatter writes a network's subnets as comma-separated values with a header row, quoting fields as RFC 4180
+// requires, for importing into spreadsheets and IPAM tools.
+type CSVFormatter struct {
+	RenderOptions
+	Columns  []string // names of the columns to include, defaults to DefaultColumns
+	NoHeader bool     // leave out the header row
+}
+
+// Format writes the subnets of n, or n itself if it hasn't been split, to w.
+func (f CSVFormatter) Format(w io.Writer, n subnet.Network) error {
+	header, rows, err := delimitedRows(n, f.Columns, f.RenderOptions)
+	if err != nil {
+		return err
+	}
+	if !f.NoHeader {
+		rows = append([][]string{header}, rows...)
+	}
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+// FormatStream writes the header and then a row for each subnet of n yielded by subnets, without holding them in
+// memory.
+func (f CSVFormatter) FormatStream(w io.Writer, n subnet.Network, subnets subnet.Seq) error {
+	cw := csv.NewWriter(w)
+	if err := streamRows(n, f.Columns, f.RenderOptions, f.NoHeader, subnets, cw.Write); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// streamRows calls write with the header, unless noHeader is set, and then a row for each subnet of n yielded by
+// subnets. The row passed to write is reused for the next row.
+func streamRows(n subnet.Network, names []string, o RenderOptions, noHeader bool, subnets subnet.Seq, write func([]string) error) error {
+	cols, err := lookupColumns(names)
+	if err != nil {
+		return err
+	}
+	o.RawNumbers = true
+
+	row := make([]string, len(cols))
+	if !noHeader {
+		for i, c := range cols {
+			row[i] = o.header(c, n)
+		}
+		if err := write(row); err != nil {
+			return err
+		}
+	}
+	i := 0
+	subnets(func(s subnet.Network) bool {
+		for j, c := range cols {
+			row[j] = c.Value(i, s, o)
+		}
+		i++
+		err = write(row)
+		return err == nil
+	})
+	return err
+}
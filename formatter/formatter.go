@@ -0,0 +1,36 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+
+// Package formatter renders networks and their subnets for output.
+package formatter
+
+import (
+	"io"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// Formatter writes a network and its subnets to w.
+type Formatter interface {
+	Format(w io.Writer, n subnet.Network) error
+}
+
+// StreamFormatter is implemented by formatters that can write subnets one at a time as they are generated, for splits
+// too large to hold in memory.
+type StreamFormatter interface {
+	Formatter
+	// FormatStream writes the subnets of n yielded by subnets.
+	FormatStream(w io.Writer, n subnet.Network, subnets subnet.Seq) error
+}
+
+// MultiFormatter is implemented by formatters that need every network at once, such as formats that may only
+// describe each metric once per document.
+type MultiFormatter interface {
+	Formatter
+	// FormatAll writes all of the networks to w as a single document.
+	FormatAll(w io.Writer, networks []subnet.Network) error
+}
+
+// Formats lists the output formats accepted by --format.
+var Formats = []string{"table", "json", "ndjson", "tsv", "csv", "markdown", "summary", "msgpack", "pb", "prom", "inetnum", "template"}
@@ -0,0 +1,101 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package formatter
+
+import (
+	"bytes"
+	"flag"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata with the current output")
+
+// goldenNetworks are the networks every format is tested with, by the name used in their golden files.
+var goldenNetworks = []struct {
+	name  string
+	cidr  string
+	sizes []int
+}{
+	{"ipv4", "192.168.0.0/24", nil},
+	{"ipv4-split", "192.168.0.0/22", []int{24}},
+	{"ipv4-mixed", "10.0.0.0/28", []int{30, 31, 32}},
+	{"ipv6-split", "2001:db8::/62", []int{64}},
+}
+
+// goldenNetwork returns the network named by cidr, split into every subnet of a single size, or into one subnet of
+// each size if several are given, the way the main command splits it.
+func goldenNetwork(t *testing.T, cidr string, sizes []int) subnet.Network {
+	t.Helper()
+	n := subnet.NewNetworkFromPrefix(netip.MustParsePrefix(cidr))
+	var err error
+	switch len(sizes) {
+	case 0:
+	case 1:
+		err = n.Split(sizes[0])
+	default:
+		err = n.SplitSizes(sizes)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestGolden(t *testing.T) {
+	o := RenderOptions{Deterministic: true, ASCII: true}
+	formats := []struct {
+		ext string
+		f   Formatter
+	}{
+		{"table", TableFormatter{RenderOptions: o}},
+		{"json", JSONFormatter{RenderOptions: o}},
+		{"csv", CSVFormatter{RenderOptions: RenderOptions{Deterministic: true, ASCII: true, RawNumbers: true}}},
+		{"md", MarkdownFormatter{RenderOptions: o}},
+	}
+	for _, tt := range goldenNetworks {
+		n := goldenNetwork(t, tt.cidr, tt.sizes)
+		for _, format := range formats {
+			var b bytes.Buffer
+			if err := format.f.Format(&b, n); err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			checkGolden(t, filepath.Join("testdata", tt.name+"."+format.ext+".golden"), b.Bytes())
+		}
+	}
+}
+
+func TestGoldenJSONMeta(t *testing.T) {
+	f := JSONFormatter{
+		RenderOptions: RenderOptions{Deterministic: true, ExpandIPv6: true},
+		Meta:          &Meta{Tool: "subnetCalc", Version: "test", SchemaVersion: SchemaVersion, Args: []string{"2001:db8::/126", "-s", "127"}, Host: "build"},
+	}
+	var b bytes.Buffer
+	if err := f.Format(&b, goldenNetwork(t, "2001:db8::/126", []int{127, 127})); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, filepath.Join("testdata", "ipv6-meta.json.golden"), b.Bytes())
+}
+
+// checkGolden compares got with the contents of the golden file at path, or rewrites the file if -update is set.
+func checkGolden(t *testing.T, path string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v, run go test ./formatter -update to create it", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output doesn't match %s, run go test ./formatter -update if the change is intended\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package formatter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// InetnumFormatter writes a network and each of its subnets as whois-style objects, as kept by the regional internet
+// registries and internal registries modelled on them: inetnum objects in range form for IPv4, and inet6num objects
+// in prefix form for IPv6.
+type InetnumFormatter struct {
+	RenderOptions
+}
+
+// netname returns the netname for a network: its label in upper case with any character other than a letter, digit,
+// hyphen, or underscore replaced by a hyphen, or a name derived from its prefix if it has no label.
+func netname(n subnet.Network) string {
+	name := strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		}
+		return '-'
+	}, n.Label), "-")
+	if name == "" {
+		return "NET-" + strings.ToUpper(subnet.PrefixLabel(n.CIDR))
+	}
+	return name
+}
+
+// writeObject writes a single inetnum or inet6num object describing n, followed by a blank line.
+func (f InetnumFormatter) writeObject(w io.Writer, n subnet.Network) error {
+	attrs := [][2]string{{"inet6num", f.Prefix(n.CIDR)}}
+	if n.NetworkAddr.Is4() {
+		attrs = [][2]string{{"inetnum", fmt.Sprintf("%v - %v", n.NetworkAddr, n.BroadcastAddr)}}
+	}
+	attrs = append(attrs, [2]string{"netname", netname(n)})
+	for _, line := range strings.Split(n.Note, "\n") {
+		if line != "" {
+			attrs = append(attrs, [2]string{"descr", line})
+		}
+	}
+	if n.Status != "" {
+		attrs = append(attrs, [2]string{"remarks", "status " + n.Status})
+	}
+	if n.VLAN != 0 {
+		attrs = append(attrs, [2]string{"remarks", fmt.Sprintf("vlan %d", n.VLAN)})
+	}
+
+	var b strings.Builder
+	for _, a := range attrs {
+		fmt.Fprintf(&b, "%-16s%s\n", a[0]+":", a[1])
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Format writes an object for n and one for each of its subnets to w.
+func (f InetnumFormatter) Format(w io.Writer, n subnet.Network) error {
+	if err := f.writeObject(w, n); err != nil {
+		return err
+	}
+	for _, s := range n.Subnets {
+		if err := f.writeObject(w, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
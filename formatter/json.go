@@ -0,0 +1,106 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package formatter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// SchemaVersion is the version of the JSON document layout. It is incremented whenever fields are renamed or removed.
+const SchemaVersion = 2
+
+// Meta describes how a JSON document was generated, so archived output is self-describing.
+type Meta struct {
+	Tool          string     `json:"tool"`
+	Version       string     `json:"version"`
+	SchemaVersion int        `json:"schemaVersion"`
+	GeneratedAt   *time.Time `json:"generatedAt,omitempty"`
+	Args          []string   `json:"args"`
+	Host          string     `json:"host,omitempty"`
+}
+
+// jsonNetwork returns the JSON form of n and its subnets, with addresses formatted with the render options. The
+// options are applied before the network is encoded, never to text such as labels and notes.
+func (o RenderOptions) jsonNetwork(n subnet.Network) subnet.NetworkView {
+	return n.View(subnet.ViewOptions{Addr: o.Addr, Prefix: o.Prefix, LegacyBroadcast: o.LegacyBroadcast})
+}
+
+// JSONFormatter writes a network and its subnets as indented JSON.
+type JSONFormatter struct {
+	RenderOptions
+	// Meta, if set, wraps the network in an envelope alongside the generation metadata.
+	Meta *Meta
+}
+
+// Format writes n to w in JSON format.
+func (f JSONFormatter) Format(w io.Writer, n subnet.Network) error {
+	network := f.jsonNetwork(n)
+	var v any = network
+	if f.Meta != nil {
+		meta := *f.Meta
+		if f.Deterministic {
+			meta.GeneratedAt, meta.Host = nil, ""
+		}
+		v = struct {
+			Meta    *Meta              `json:"meta"`
+			Network subnet.NetworkView `json:"network"`
+		}{&meta, network}
+	}
+
+	netJSON, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(netJSON))
+	return err
+}
+
+// NDJSONFormatter writes each subnet of a network as a JSON object on its own line, so huge splits can be streamed
+// and processed line by line.
+type NDJSONFormatter struct {
+	RenderOptions
+}
+
+// Format writes a line for each subnet of n, or for n itself if it hasn't been split, to w.
+func (f NDJSONFormatter) Format(w io.Writer, n subnet.Network) error {
+	if len(n.Subnets) == 0 {
+		return f.writeLine(w, n)
+	}
+	for _, s := range n.Subnets {
+		if err := f.writeLine(w, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FormatStream writes a line for each subnet of n yielded by subnets, without holding them in memory.
+func (f NDJSONFormatter) FormatStream(w io.Writer, n subnet.Network, subnets subnet.Seq) error {
+	bw := bufio.NewWriter(w)
+	var err error
+	subnets(func(s subnet.Network) bool {
+		err = f.writeLine(bw, s)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// writeLine writes n as a single line of JSON.
+func (f NDJSONFormatter) writeLine(w io.Writer, n subnet.Network) error {
+	b, err := json.Marshal(f.jsonNetwork(n))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
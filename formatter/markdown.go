@@ -0,0 +1,91 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package formatter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// MarkdownFormatter writes a network as a Markdown heading and list of its details, followed by a table of its subnets.
+type MarkdownFormatter struct {
+	RenderOptions
+	Columns []string // names of the columns to include, defaults to DefaultColumns
+}
+
+// Format writes the network details and, if the network has been split, the subnet table to w.
+func (f MarkdownFormatter) Format(w io.Writer, n subnet.Network) error {
+	names := f.Columns
+	if len(names) == 0 {
+		names = DefaultColumns
+	}
+	cols, err := lookupColumns(names)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", f.Prefix(n.CIDR))
+	if n.Label != "" {
+		fmt.Fprintf(&b, "%s\n\n", n.Label)
+	}
+	if a := n.Address; a != nil {
+		fmt.Fprintf(&b, "- **Address:** %s\n", f.Addr(a.Addr))
+		fmt.Fprintf(&b, "- **Classification:** %s\n", addressClass(*a))
+		fmt.Fprintf(&b, "- **Reverse Name:** %s\n", a.ReverseName)
+		fmt.Fprintf(&b, "- **Integer:** %s\n", a.Integer)
+		fmt.Fprintf(&b, "- **Hex:** %s\n", a.Hex)
+	}
+	fmt.Fprintf(&b, "- **Host Address Range:** %s - %s\n", f.Addr(n.FirstHostIP), f.Addr(n.LastHostIP))
+	fmt.Fprintf(&b, "- **%s:** %s\n", f.lastAddrLabel(n), f.Addr(n.BroadcastAddr))
+	if n.Gateway != nil {
+		fmt.Fprintf(&b, "- **Gateway:** %s\n", f.Addr(*n.Gateway))
+	}
+	if n.Anycast != nil {
+		fmt.Fprintf(&b, "- **Subnet-Router Anycast:** %s\n", f.Addr(*n.Anycast))
+	}
+	fmt.Fprintf(&b, "- **Subnet Mask:** %s\n", f.Addr(n.SubnetMask))
+	fmt.Fprintf(&b, "- **Wildcard Mask:** %s\n", f.Addr(n.WildcardMask))
+	if n.GUA != nil && n.SubnetCount64 != nil {
+		fmt.Fprintf(&b, "- **Available /64s:** %s\n", f.BigNumber(n.SubnetCount64))
+		fmt.Fprintf(&b, "- **Structure:** %d bit global routing prefix, %d bit subnet ID, %d bit interface ID\n", n.GUA.RoutingPrefixBits, n.GUA.SubnetIDBits, n.GUA.InterfaceIDBits)
+	} else {
+		fmt.Fprintf(&b, "- **Maximum Hosts:** %s\n", f.BigNumber(n.MaxHosts))
+	}
+	if n.Utilization != nil {
+		fmt.Fprintf(&b, "- **Utilization:** %s\n", f.Percent(n.Utilization))
+	}
+	if x := n.Numeric; x != nil {
+		fmt.Fprintf(&b, "- **Integer Range:** %s - %s\n", x.Network.Integer, x.End.Integer)
+		fmt.Fprintf(&b, "- **Hex Range:** %s - %s\n", x.Network.Hex, x.End.Hex)
+	}
+	if f.Binary {
+		for _, r := range f.binaryRows(n) {
+			fmt.Fprintf(&b, "- **%s (binary):** `%s`\n", r[0], r[1])
+		}
+	}
+
+	if len(n.Subnets) > 0 {
+		t := table.NewWriter()
+		var header table.Row
+		for _, c := range cols {
+			header = append(header, f.header(c, n))
+		}
+		t.AppendHeader(header)
+		for i, s := range n.Subnets {
+			row := make(table.Row, len(cols))
+			for j, c := range cols {
+				row[j] = c.Value(i, s, f.RenderOptions)
+			}
+			t.AppendRow(row)
+		}
+		fmt.Fprintf(&b, "\n%s\n", t.RenderMarkdown())
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
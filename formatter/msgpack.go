@@ -0,0 +1,140 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package formatter
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"math/big"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// MessagePackFormatter writes a network and its subnets as MessagePack. The document mirrors the JSON output: a map
+// using the same keys, with addresses and prefixes encoded as strings.
+type MessagePackFormatter struct {
+	RenderOptions
+}
+
+// Format writes n to w in MessagePack format.
+func (f MessagePackFormatter) Format(w io.Writer, n subnet.Network) error {
+	_, err := w.Write(f.appendNetwork(nil, n))
+	return err
+}
+
+// clampUint64 returns n, or the largest uint64 if n doesn't fit, for fields that can't hold the host counts of large
+// IPv6 networks.
+func clampUint64(n *big.Int) uint64 {
+	if !n.IsUint64() {
+		return math.MaxUint64
+	}
+	return n.Uint64()
+}
+
+// appendNetwork appends n to b as a MessagePack map, omitting the same empty fields as the JSON output.
+func (f MessagePackFormatter) appendNetwork(b []byte, n subnet.Network) []byte {
+	fields := 11
+	for _, set := range []bool{n.Anycast != nil, n.Label != "", n.Status != "", n.VLAN != 0, len(n.Subnets) > 0} {
+		if set {
+			fields++
+		}
+	}
+
+	b = appendMsgpackMapHeader(b, fields)
+	b = appendMsgpackString(appendMsgpackString(b, "cidr"), f.Prefix(n.CIDR))
+	b = appendMsgpackString(appendMsgpackString(b, "firstIP"), f.Addr(n.FirstHostIP))
+	b = appendMsgpackString(appendMsgpackString(b, "lastIP"), f.Addr(n.LastHostIP))
+	b = appendMsgpackString(appendMsgpackString(b, "networkAddr"), f.Addr(n.NetworkAddr))
+	b = appendMsgpackString(appendMsgpackString(b, f.lastAddrKey(n)), f.Addr(n.BroadcastAddr))
+	if n.Anycast != nil {
+		b = appendMsgpackString(appendMsgpackString(b, "subnetRouterAnycast"), f.Addr(*n.Anycast))
+	}
+	b = appendMsgpackString(appendMsgpackString(b, "subnetMask"), f.Addr(n.SubnetMask))
+	b = appendMsgpackString(appendMsgpackString(b, "wildcardMask"), f.Addr(n.WildcardMask))
+	b = appendMsgpackInt(appendMsgpackString(b, "maskBits"), int64(n.MaskBits))
+	b = appendMsgpackInt(appendMsgpackString(b, "subnetBits"), int64(n.SubnetBits))
+	b = appendMsgpackUint(appendMsgpackString(b, "maxSubnets"), clampUint64(n.MaxSubnets))
+	b = appendMsgpackUint(appendMsgpackString(b, "maxHosts"), clampUint64(n.MaxHosts))
+	if n.Label != "" {
+		b = appendMsgpackString(appendMsgpackString(b, "label"), n.Label)
+	}
+	if n.Status != "" {
+		b = appendMsgpackString(appendMsgpackString(b, "status"), n.Status)
+	}
+	if n.VLAN != 0 {
+		b = appendMsgpackInt(appendMsgpackString(b, "vlan"), int64(n.VLAN))
+	}
+	if len(n.Subnets) > 0 {
+		b = appendMsgpackArrayHeader(appendMsgpackString(b, "subnets"), len(n.Subnets))
+		for _, s := range n.Subnets {
+			b = f.appendNetwork(b, s)
+		}
+	}
+	return b
+}
+
+// appendMsgpackHeader appends a type marker for a container or string of length n, using the fix format if the
+// length is below fixLimit and the 16 or 32 bit format otherwise.
+func appendMsgpackHeader(b []byte, n int, fix byte, fixLimit int, m16, m32 byte) []byte {
+	switch {
+	case n < fixLimit:
+		return append(b, fix|byte(n))
+	case n <= 0xFFFF:
+		return binary.BigEndian.AppendUint16(append(b, m16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, m32), uint32(n))
+	}
+}
+
+func appendMsgpackMapHeader(b []byte, n int) []byte {
+	return appendMsgpackHeader(b, n, 0x80, 16, 0xde, 0xdf)
+}
+
+func appendMsgpackArrayHeader(b []byte, n int) []byte {
+	return appendMsgpackHeader(b, n, 0x90, 16, 0xdc, 0xdd)
+}
+
+func appendMsgpackString(b []byte, s string) []byte {
+	if len(s) >= 32 && len(s) <= 0xFF {
+		b = append(b, 0xd9, byte(len(s)))
+	} else {
+		b = appendMsgpackHeader(b, len(s), 0xa0, 32, 0xda, 0xdb)
+	}
+	return append(b, s...)
+}
+
+// appendMsgpackUint appends v using the smallest unsigned integer format that holds it.
+func appendMsgpackUint(b []byte, v uint64) []byte {
+	switch {
+	case v < 0x80:
+		return append(b, byte(v))
+	case v <= 0xFF:
+		return append(b, 0xcc, byte(v))
+	case v <= 0xFFFF:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(v))
+	case v <= 0xFFFFFFFF:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xcf), v)
+	}
+}
+
+// appendMsgpackInt appends v using the smallest integer format that holds it.
+func appendMsgpackInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return appendMsgpackUint(b, uint64(v))
+	case v >= -32:
+		return append(b, byte(v))
+	case v >= -0x80:
+		return append(b, 0xd0, byte(v))
+	case v >= -0x8000:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(v))
+	case v >= -0x80000000:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v))
+	}
+}
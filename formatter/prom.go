@@ -0,0 +1,85 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package formatter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/big"
+	"net/netip"
+	"strconv"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// PromFormatter writes gauges describing the allocation of each network in the Prometheus text exposition format, for
+// the node_exporter textfile collector. Subnets with a status, such as a plan's allocations, count as allocated.
+type PromFormatter struct {
+	RenderOptions
+}
+
+// promMetric is a gauge written for every network.
+type promMetric struct {
+	name, help string
+	value      func(n subnet.Network) float64
+}
+
+// promMetrics lists the gauges in the order they are written.
+var promMetrics = []promMetric{
+	{"subnetcalc_subnet_utilization", "Fraction of the supernet's addresses that are allocated, from 0 to 1.", promUtilization},
+	{"subnetcalc_free_blocks", "Number of free CIDR blocks left in the supernet.", func(n subnet.Network) float64 {
+		return float64(len(subnet.Exclude(n.CIDR, promAllocated(n))))
+	}},
+	{"subnetcalc_allocated_hosts", "Number of usable host addresses in the supernet's allocated subnets.", func(n subnet.Network) float64 {
+		hosts := new(big.Int)
+		for _, s := range n.Subnets {
+			if s.Status == subnet.StatusAllocated {
+				hosts.Add(hosts, s.MaxHosts)
+			}
+		}
+		f, _ := new(big.Float).SetInt(hosts).Float64()
+		return f
+	}},
+}
+
+// promAllocated returns the prefixes of the subnets of n that have a status.
+func promAllocated(n subnet.Network) []netip.Prefix {
+	var allocated []netip.Prefix
+	for _, s := range n.Subnets {
+		if s.Status != "" {
+			allocated = append(allocated, s.CIDR)
+		}
+	}
+	return allocated
+}
+
+// promUtilization returns the utilization recorded for n, or the fraction of n covered by its allocated subnets.
+func promUtilization(n subnet.Network) float64 {
+	if n.Utilization != nil {
+		return *n.Utilization
+	}
+	return subnet.Utilization(n.CIDR, promAllocated(n))
+}
+
+// Format writes the gauges of n to w in Prometheus text format.
+func (f PromFormatter) Format(w io.Writer, n subnet.Network) error {
+	return f.FormatAll(w, []subnet.Network{n})
+}
+
+// FormatAll writes the gauges of every network to w, grouped by metric as the exposition format requires. Networks
+// that are allocations themselves, such as a plan's allocations outside every supernet, are skipped.
+func (f PromFormatter) FormatAll(w io.Writer, networks []subnet.Network) error {
+	bw := bufio.NewWriter(w)
+	for _, m := range promMetrics {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s gauge\n", m.name, m.help, m.name)
+		for _, n := range networks {
+			if n.Status != "" {
+				continue
+			}
+			fmt.Fprintf(bw, "%s{supernet=%q} %s\n", m.name, f.Prefix(n.CIDR), strconv.FormatFloat(m.value(n), 'g', -1, 64))
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,85 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package formatter
+
+import (
+	"io"
+	"net/netip"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// protobuf wire types
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+// ProtobufFormatter writes a network and its subnets as a binary subnetcalc.v1.Network protocol buffer message, as
+// described by proto/subnetcalc.proto.
+type ProtobufFormatter struct{}
+
+// Format writes n to w in protocol buffer wire format.
+func (ProtobufFormatter) Format(w io.Writer, n subnet.Network) error {
+	_, err := w.Write(appendNetworkProto(nil, n))
+	return err
+}
+
+// appendNetworkProto appends the encoded fields of n to b. Fields holding their zero value are omitted, as in proto3.
+func appendNetworkProto(b []byte, n subnet.Network) []byte {
+	b = appendProtoAddr(b, 1, n.NetworkAddr)
+	b = appendProtoUint(b, 2, uint64(n.MaskBits))
+	b = appendProtoAddr(b, 3, n.FirstHostIP)
+	b = appendProtoAddr(b, 4, n.LastHostIP)
+	b = appendProtoAddr(b, 5, n.BroadcastAddr)
+	b = appendProtoAddr(b, 6, n.SubnetMask)
+	// sint32 uses zigzag encoding so negative values stay small
+	b = appendProtoUint(b, 7, uint64(uint32((n.SubnetBits<<1)^(n.SubnetBits>>31))))
+	b = appendProtoUint(b, 8, clampUint64(n.MaxSubnets))
+	b = appendProtoUint(b, 9, clampUint64(n.MaxHosts))
+	b = appendProtoBytes(b, 10, []byte(n.Label))
+	b = appendProtoBytes(b, 11, []byte(n.Status))
+	b = appendProtoUint(b, 12, uint64(n.VLAN))
+	for _, s := range n.Subnets {
+		b = appendProtoBytes(b, 13, appendNetworkProto(nil, s))
+	}
+	b = appendProtoAddr(b, 14, n.WildcardMask)
+	return b
+}
+
+// appendVarint appends v to b using the base 128 varint encoding.
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+// appendProtoUint appends a varint field unless v is zero.
+func appendProtoUint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendVarint(b, uint64(field<<3|wireVarint))
+	return appendVarint(b, v)
+}
+
+// appendProtoBytes appends a length-delimited field unless v is empty.
+func appendProtoBytes(b []byte, field int, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = appendVarint(b, uint64(field<<3|wireBytes))
+	b = appendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// appendProtoAddr appends an address field in network byte order unless the address is invalid.
+func appendProtoAddr(b []byte, field int, a netip.Addr) []byte {
+	if !a.IsValid() {
+		return b
+	}
+	return appendProtoBytes(b, field, a.AsSlice())
+}
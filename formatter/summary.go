@@ -0,0 +1,78 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package formatter
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// SummaryFormatter writes one plain English sentence per network, suitable for chat responses.
+type SummaryFormatter struct {
+	RenderOptions
+}
+
+// classificationAdjectives maps classifications to the adjective used in a sentence, where they differ.
+var classificationAdjectives = map[string]string{
+	"private-use":    "private",
+	"global unicast": "global",
+}
+
+// article returns the indefinite article for word.
+func article(word string) string {
+	if strings.ContainsAny(word[:1], "aeiouAEIOU") {
+		return "an"
+	}
+	return "a"
+}
+
+// shortAddr returns a with the IPv4 octets fixed by prefix p removed, e.g. ".126" for 192.168.10.126 in
+// 192.168.10.0/25. IPv6 addresses and addresses in prefixes shorter than /8 are returned in full.
+func (o RenderOptions) shortAddr(p netip.Prefix, a netip.Addr) string {
+	fixed := p.Bits() / 8
+	if !a.Is4() || fixed == 0 {
+		return o.Addr(a)
+	}
+	if fixed > 3 {
+		fixed = 3
+	}
+	octets := strings.Split(a.String(), ".")
+	return "." + strings.Join(octets[fixed:], ".")
+}
+
+// Sentence describes n in a single plain English sentence.
+func (o RenderOptions) Sentence(n subnet.Network) string {
+	class := subnet.Classify(n.CIDR)
+	if adj, ok := classificationAdjectives[class]; ok {
+		class = adj
+	}
+	family := "IPv4"
+	if n.CIDR.Addr().Is6() {
+		family = "IPv6"
+	}
+
+	base := n.CIDR
+	s := fmt.Sprintf("%s is %s %s %s network with %s usable hosts", o.Prefix(n.CIDR), article(class), class, family, o.BigNumber(n.MaxHosts))
+	if n.MaxHosts.Sign() > 0 {
+		s += fmt.Sprintf(" from %s to %s", o.shortAddr(base, n.FirstHostIP), o.shortAddr(base, n.LastHostIP))
+	}
+	if n.HasBroadcast() {
+		s += fmt.Sprintf(", broadcast %s", o.shortAddr(base, n.BroadcastAddr))
+	}
+	return s + "."
+}
+
+// Format writes a sentence describing n followed by a sentence for each of its subnets.
+func (f SummaryFormatter) Format(w io.Writer, n subnet.Network) error {
+	for _, s := range append([]subnet.Network{n}, n.Subnets...) {
+		if _, err := fmt.Fprintln(w, f.Sentence(s)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package formatter
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// plainStyle is a table style without borders or column separators, similar to the output of `column -t`.
+var plainStyle = func() table.Style {
+	s := table.StyleDefault
+	s.Name = "StylePlain"
+	s.Options = table.OptionsNoBordersAndSeparators
+	return s
+}()
+
+// PrefixColors is the palette used to color rows by their depth below the network being split. Depths beyond the end
+// of the palette wrap around.
+var PrefixColors = []text.Colors{
+	{text.FgCyan},
+	{text.FgGreen},
+	{text.FgYellow},
+	{text.FgMagenta},
+	{text.FgBlue},
+	{text.FgRed},
+}
+
+// depthColor returns the palette entry for a subnet depth levels below its supernet.
+func depthColor(depth int) text.Colors {
+	if depth < 1 {
+		depth = 1
+	}
+	return PrefixColors[(depth-1)%len(PrefixColors)]
+}
+
+// TableStyles maps the names accepted by --style to table styles.
+var TableStyles = map[string]table.Style{
+	"ascii":   table.StyleDefault,
+	"bold":    table.StyleBold,
+	"color":   table.StyleColoredBlackOnBlueWhite,
+	"double":  table.StyleDouble,
+	"light":   table.StyleLight,
+	"plain":   plainStyle,
+	"rounded": table.StyleRounded,
+}
+
+// TableStyleNames returns the names of the available table styles in sorted order.
+func TableStyleNames() []string {
+	var names []string
+	for name := range TableStyles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TableFormatter writes a summary of a network followed by a table of its subnets.
+type TableFormatter struct {
+	RenderOptions
+	Style   string         // name of a style in TableStyles, defaults to rounded
+	Borders bool           // draw a border between every row
+	Zebra   bool           // shade every other row
+	Columns []string       // names of the columns to include, defaults to DefaultColumns
+	Widths  map[string]int // maximum width of each named column, longer values are wrapped
+	Depth   bool           // color rows by prefix depth and print a legend
+	Width   int            // width of the terminal, tables are adapted to fit when set
+}
+
+// depthColors reports whether rows are colored by prefix depth, which deterministic output never does.
+func (f TableFormatter) depthColors() bool {
+	return f.Depth && !f.Deterministic
+}
+
+// style returns the go-pretty style for the formatter's configuration.
+func (f TableFormatter) style() (table.Style, error) {
+	name := f.Style
+	if name == "" {
+		name = "rounded"
+	}
+	if f.Deterministic {
+		name = "ascii"
+	}
+	s, ok := TableStyles[name]
+	if !ok {
+		return s, fmt.Errorf("unknown table style %q", name)
+	}
+	s.Options.SeparateRows = f.Borders
+	if f.Zebra && !f.Deterministic {
+		s.Color.RowAlternate = text.Colors{text.BgHiBlack}
+	}
+	if f.ASCII {
+		s.Box = table.StyleBoxDefault
+	}
+	return s, nil
+}
+
+// addressClass returns the classification of a looked up address, followed by the special-purpose block it is in.
+func addressClass(a subnet.AddressInfo) string {
+	if a.Block == nil {
+		return a.Classification
+	}
+	return fmt.Sprintf("%s (%v, %s)", a.Classification, *a.Block, a.RFC)
+}
+
+// writeSummary writes information about an IP network to w. If width is set, the host address range is wrapped onto
+// a second line when it would not fit.
+func writeSummary(w io.Writer, n subnet.Network, o RenderOptions, width int) {
+	hostRange := fmt.Sprintf("    Host Address Range: %s - %s", o.Addr(n.FirstHostIP), o.Addr(n.LastHostIP))
+	if width > 0 && len(hostRange) > width {
+		hostRange = fmt.Sprintf("    Host Address Range: %s -\n                        %s", o.Addr(n.FirstHostIP), o.Addr(n.LastHostIP))
+	}
+
+	fmt.Fprintln(w)
+	if a := n.Address; a != nil {
+		fmt.Fprintln(w, "               Address:", o.Addr(a.Addr))
+		fmt.Fprintln(w, "        Classification:", addressClass(*a))
+		fmt.Fprintln(w, "          Reverse Name:", a.ReverseName)
+		fmt.Fprintln(w, "               Integer:", a.Integer)
+		fmt.Fprintln(w, "                   Hex:", a.Hex)
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, "               Network:", o.Prefix(n.CIDR))
+	fmt.Fprintln(w, hostRange)
+	fmt.Fprintf(w, "%22s: %s\n", o.lastAddrLabel(n), o.Addr(n.BroadcastAddr))
+	if n.Gateway != nil {
+		fmt.Fprintln(w, "               Gateway:", o.Addr(*n.Gateway))
+	}
+	if n.Anycast != nil {
+		fmt.Fprintln(w, " Subnet-Router Anycast:", o.Addr(*n.Anycast))
+	}
+	fmt.Fprintln(w, "           Subnet Mask:", o.Addr(n.SubnetMask))
+	fmt.Fprintln(w, "         Wildcard Mask:", o.Addr(n.WildcardMask))
+	fmt.Fprintln(w, "       Maximum Subnets:", o.BigNumber(n.MaxSubnets))
+	if n.GUA != nil && n.SubnetCount64 != nil {
+		// host counts are meaningless in IPv6 global unicast space, where every LAN is a /64
+		fmt.Fprintln(w, "        Available /64s:", o.BigNumber(n.SubnetCount64))
+		fmt.Fprintf(w, "             Structure: %d bit global routing prefix, %d bit subnet ID, %d bit interface ID\n", n.GUA.RoutingPrefixBits, n.GUA.SubnetIDBits, n.GUA.InterfaceIDBits)
+	} else {
+		fmt.Fprintln(w, "         Maximum Hosts:", o.BigNumber(n.MaxHosts))
+	}
+	if n.Utilization != nil {
+		fmt.Fprintln(w, "           Utilization:", o.Percent(n.Utilization))
+	}
+	if x := n.Numeric; x != nil {
+		fmt.Fprintf(w, "         Integer Range: %s - %s\n", x.Network.Integer, x.End.Integer)
+		fmt.Fprintf(w, "             Hex Range: %s - %s\n", x.Network.Hex, x.End.Hex)
+	}
+	if o.Binary {
+		fmt.Fprintf(w, "\n%22s\n", "Binary (network|host):")
+		for _, r := range o.binaryRows(n) {
+			fmt.Fprintf(w, "%22s: %s\n", r[0], r[1])
+		}
+	}
+}
+
+// dropOrder lists the columns removed, lowest priority first, when a table is too wide for the terminal.
+var dropOrder = []string{"anycast", "class", "util", "vlan", "status", "label", "wildcard", "mask", "index", "broadcast", "hosts"}
+
+// render returns the subnet table for the named columns.
+func (f TableFormatter) render(n subnet.Network, names []string, style table.Style) (string, error) {
+	cols, err := lookupColumns(names)
+	if err != nil {
+		return "", err
+	}
+
+	t := table.NewWriter()
+	t.SetStyle(style)
+
+	var header table.Row
+	var configs []table.ColumnConfig
+	for i, c := range cols {
+		header = append(header, f.header(c, n))
+		if width, ok := f.Widths[names[i]]; ok {
+			configs = append(configs, table.ColumnConfig{Number: i + 1, WidthMax: width})
+		}
+	}
+	t.AppendHeader(header)
+	t.SetColumnConfigs(configs)
+
+	for i, s := range n.Subnets {
+		row := make(table.Row, len(cols))
+		for j, c := range cols {
+			row[j] = c.Value(i, s, f.RenderOptions)
+			if f.depthColors() {
+				row[j] = depthColor(s.MaskBits - n.MaskBits).Sprint(row[j])
+			}
+		}
+		t.AppendRow(row)
+	}
+	return t.Render(), nil
+}
+
+// fits reports whether every line of a rendered table fits within width columns.
+func fits(rendered string, width int) bool {
+	for _, line := range strings.Split(rendered, "\n") {
+		if text.RuneWidthWithoutEscSequences(line) > width {
+			return false
+		}
+	}
+	return true
+}
+
+// responsiveColumns adapts the column list to the terminal width: the first and last address columns are merged into
+// an abbreviated range, then low priority columns are dropped until the table fits or only essential columns remain.
+func (f TableFormatter) responsiveColumns(n subnet.Network, names []string, style table.Style) (string, error) {
+	rendered, err := f.render(n, names, style)
+	if err != nil || f.Width <= 0 || fits(rendered, f.Width) {
+		return rendered, err
+	}
+
+	if i, j := slices.Index(names, "first"), slices.Index(names, "last"); i >= 0 && j >= 0 {
+		merged := append([]string{}, names[:i]...)
+		merged = append(merged, "range")
+		for _, name := range names[i+1:] {
+			if name != "last" {
+				merged = append(merged, name)
+			}
+		}
+		names = merged
+		if rendered, err = f.render(n, names, style); err != nil || fits(rendered, f.Width) {
+			return rendered, err
+		}
+	}
+
+	for _, drop := range dropOrder {
+		i := slices.Index(names, drop)
+		if i < 0 {
+			continue
+		}
+		names = append(names[:i:i], names[i+1:]...)
+		if rendered, err = f.render(n, names, style); err != nil || fits(rendered, f.Width) {
+			return rendered, err
+		}
+	}
+	return rendered, nil
+}
+
+// Format writes the network summary and, if the network has been split, the subnet table to w.
+func (f TableFormatter) Format(w io.Writer, n subnet.Network) error {
+	style, err := f.style()
+	if err != nil {
+		return err
+	}
+	names := f.Columns
+	if len(names) == 0 {
+		names = DefaultColumns
+	}
+	if _, err := lookupColumns(names); err != nil {
+		return err
+	}
+	if f.Deterministic {
+		f.Width = 0
+	}
+	writeSummary(w, n, f.RenderOptions, f.Width)
+	if len(n.Subnets) == 0 {
+		return nil
+	}
+
+	rendered, err := f.responsiveColumns(n, names, style)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !sameSize(n.Subnets):
+		fmt.Fprintf(w, "\n  %s contains %d subnets:\n", f.Prefix(n.CIDR), len(n.Subnets))
+	case n.Subnets[0].Index != nil:
+		// sampled subnets, report how many there are in total
+		bits := n.Subnets[0].MaskBits
+		fmt.Fprintf(w, "\n  %s contains %s /%d subnets, showing %d:\n", f.Prefix(n.CIDR), f.BigNumber(n.SubnetCount(bits)), bits, len(n.Subnets))
+	default:
+		fmt.Fprintf(w, "\n  %s contains %d /%d subnets:\n", f.Prefix(n.CIDR), len(n.Subnets), n.Subnets[0].MaskBits)
+	}
+	fmt.Fprintln(w, rendered)
+	if f.depthColors() {
+		writeDepthLegend(w, n, f.ASCII)
+	}
+	if n.Stats != nil {
+		writeStats(w, *n.Stats, f.RenderOptions)
+	}
+	return nil
+}
+
+// writeStats writes the statistics about a network's subnets to w.
+func writeStats(w io.Writer, s subnet.Stats, o RenderOptions) {
+	free := o.BigNumber(s.FreeAddresses) + " addresses"
+	if len(s.Free) == 1 {
+		free += fmt.Sprintf(" in %s", o.Prefix(s.Free[0]))
+	} else if len(s.Free) > 1 {
+		free += fmt.Sprintf(" in %d blocks, starting at %s", len(s.Free), o.Prefix(s.Free[0]))
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "               Subnets:", s.Subnets)
+	fmt.Fprintln(w, "    Total Usable Hosts:", o.BigNumber(s.UsableHosts))
+	fmt.Fprintln(w, "       Smallest Subnet:", o.Prefix(s.Smallest))
+	fmt.Fprintln(w, "        Largest Subnet:", o.Prefix(s.Largest))
+	fmt.Fprintln(w, "            Free Space:", free)
+}
+
+// sameSize reports whether every subnet has the same mask length.
+func sameSize(subnets []subnet.Network) bool {
+	for _, s := range subnets {
+		if s.MaskBits != subnets[0].MaskBits {
+			return false
+		}
+	}
+	return true
+}
+
+// writeDepthLegend writes the color used for each prefix length present in the network's subnets. If ascii is true
+// the colors are shown with # instead of squares.
+func writeDepthLegend(w io.Writer, n subnet.Network, ascii bool) {
+	swatch := "■"
+	if ascii {
+		swatch = "#"
+	}
+	seen := map[int]bool{}
+	var entries []string
+	for _, s := range n.Subnets {
+		if !seen[s.MaskBits] {
+			seen[s.MaskBits] = true
+			entries = append(entries, depthColor(s.MaskBits-n.MaskBits).Sprintf("%s /%d", swatch, s.MaskBits))
+		}
+	}
+	fmt.Fprintf(w, "  Legend: %s\n", strings.Join(entries, "  "))
+}
@@ -0,0 +1,44 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package formatter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// TemplateFormatter writes a network with a user-defined text/template. The template is executed with the
+// subnet.Network being written, so it can describe the network itself and range over its .Subnets.
+type TemplateFormatter struct {
+	RenderOptions
+	Text string // text of the template
+}
+
+// funcs returns the functions available to templates, formatting values the way the other formats do.
+func (f TemplateFormatter) funcs() template.FuncMap {
+	return template.FuncMap{
+		"addr":    f.Addr,
+		"prefix":  f.Prefix,
+		"number":  f.BigNumber,
+		"percent": f.Percent,
+		"add":     func(a, b int) int { return a + b },
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"join":    strings.Join,
+	}
+}
+
+// Format executes the template with n and writes the result to w.
+// returns an error if the template is invalid or refers to a field a network doesn't have.
+func (f TemplateFormatter) Format(w io.Writer, n subnet.Network) error {
+	tmpl, err := template.New("output").Funcs(f.funcs()).Option("missingkey=error").Parse(f.Text)
+	if err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl.Execute(w, n)
+}
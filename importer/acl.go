@@ -0,0 +1,156 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// aclHeader matches the line naming the ACL the following entries belong to, in a running config or in the output of
+// 'show access-lists'.
+var aclHeader = regexp.MustCompile(`(?i)^(?:ip access-list (standard|extended)|(standard|extended) ip access list) (\S+)`)
+
+// aclHitCount matches the hit counts 'show access-lists' appends to entries.
+var aclHitCount = regexp.MustCompile(`\s*\(\d+ matches\)\s*$`)
+
+// aclPortOperators are the operators that qualify a source or destination with ports, and the number of port
+// arguments each takes.
+var aclPortOperators = map[string]int{"eq": 1, "neq": 1, "lt": 1, "gt": 1, "range": 2}
+
+// standardACL reports whether a numbered ACL is a standard one, matching only source addresses.
+func standardACL(number string) bool {
+	n, err := strconv.Atoi(number)
+	return err == nil && (n >= 1 && n <= 99 || n >= 1300 && n <= 1999)
+}
+
+// readACLMatch reads the address and wildcard mask pair at the start of fields: any, host and an address, an address
+// and wildcard, or, in standard ACLs, a bare address.
+// returns the match and the number of fields read, or an error if fields doesn't start with an address.
+func readACLMatch(fields []string) (subnet.ACLMatch, int, error) {
+	any4, host4 := netip.MustParseAddr("0.0.0.0"), netip.MustParseAddr("255.255.255.255")
+	if len(fields) == 0 {
+		return subnet.ACLMatch{}, 0, fmt.Errorf("missing address")
+	}
+	switch fields[0] {
+	case "any":
+		return subnet.NewACLMatch(any4, host4), 1, nil
+	case "host":
+		if len(fields) < 2 {
+			return subnet.ACLMatch{}, 0, fmt.Errorf("missing host address")
+		}
+		addr, err := netip.ParseAddr(fields[1])
+		if err != nil || !addr.Is4() {
+			return subnet.ACLMatch{}, 0, fmt.Errorf("invalid host address %q", fields[1])
+		}
+		return subnet.NewACLMatch(addr, any4), 2, nil
+	}
+	addr, err := netip.ParseAddr(fields[0])
+	if err != nil || !addr.Is4() {
+		return subnet.ACLMatch{}, 0, fmt.Errorf("unsupported address %q", fields[0])
+	}
+	if len(fields) > 1 {
+		if wildcard, err := netip.ParseAddr(fields[1]); err == nil && wildcard.Is4() {
+			return subnet.NewACLMatch(addr, wildcard), 2, nil
+		}
+	}
+	return subnet.NewACLMatch(addr, any4), 1, nil
+}
+
+// readACLPorts reads the port qualifier at the start of fields, if there is one.
+// returns the qualifier and the number of fields read.
+func readACLPorts(fields []string) (string, int) {
+	if len(fields) == 0 {
+		return "", 0
+	}
+	n, ok := aclPortOperators[fields[0]]
+	if !ok || len(fields) <= n {
+		return "", 0
+	}
+	return strings.Join(fields[:n+1], " "), n + 1
+}
+
+// ReadACL reads the IPv4 entries of Cisco IOS access control lists, from a running config or the output of 'show
+// access-lists': numbered access-list lines, and the entries of named standard and extended ACLs, with or without
+// sequence numbers. Entries of named ACLs end at the first unindented line that isn't an entry. Remarks, hit counts,
+// and log keywords are ignored, as are other lines.
+// returns an error, with its line number, for a permit or deny entry whose addresses can't be read, such as one using
+// an object group.
+func ReadACL(r io.Reader) ([]subnet.ACLEntry, error) {
+	var entries []subnet.ACLEntry
+	name, standard := "", false
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		raw := scanner.Text()
+		text := strings.TrimSpace(aclHitCount.ReplaceAllString(raw, ""))
+		// 'show access-lists' writes standard entries as 10.1.1.0, wildcard bits 0.0.0.255
+		text = strings.ReplaceAll(text, ", wildcard bits ", " ")
+		if m := aclHeader.FindStringSubmatch(text); m != nil {
+			name, standard = m[3], strings.EqualFold(m[1]+m[2], "standard")
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) > 2 && fields[0] == "access-list" {
+			name, standard = fields[1], standardACL(fields[1])
+			fields = fields[2:]
+		} else if len(fields) > 1 && isNumber(fields[0]) {
+			// sequence number of a named ACL entry
+			fields = fields[1:]
+		}
+		action := len(fields) > 0 && (fields[0] == "permit" || fields[0] == "deny")
+		if !action && raw != "" && !unicode.IsSpace(rune(raw[0])) {
+			// an unindented line other than an entry ends a named ACL
+			name = ""
+		}
+		if name == "" || !action {
+			continue
+		}
+
+		e := subnet.ACLEntry{ACL: name, Line: line, Action: fields[0], Text: text}
+		rest := fields[1:]
+		if !standard {
+			if len(rest) == 0 {
+				return nil, fmt.Errorf("line %d: missing protocol", line)
+			}
+			e.Protocol, rest = rest[0], rest[1:]
+		}
+		source, n, err := readACLMatch(rest)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		e.Source, rest = source, rest[n:]
+		if !standard {
+			e.SourcePorts, n = readACLPorts(rest)
+			rest = rest[n:]
+			destination, n, err := readACLMatch(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			e.Destination, rest = &destination, rest[n:]
+		}
+		var options []string
+		for _, f := range rest {
+			if f != "log" && f != "log-input" {
+				options = append(options, f)
+			}
+		}
+		e.Options = strings.Join(options, " ")
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// isNumber reports whether s is a non-empty string of decimal digits, such as a sequence number.
+func isNumber(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package importer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// aclSummary returns e as "acl:line action protocol source ports destination options", with a match written as its
+// address and wildcard, so entries can be compared on one line.
+func aclSummary(e subnet.ACLEntry) string {
+	s := fmt.Sprintf("%s:%d %s %s %v/%v %s", e.ACL, e.Line, e.Action, e.Protocol, e.Source.Addr, e.Source.Wildcard,
+		e.SourcePorts)
+	if e.Destination != nil {
+		s += fmt.Sprintf(" %v/%v", e.Destination.Addr, e.Destination.Wildcard)
+	}
+	return strings.Join(strings.Fields(s+" "+e.Options), " ")
+}
+
+func TestReadACL(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		want    []string
+		wantErr bool
+	}{
+		{"numbered standard", `access-list 10 remark branch offices
+access-list 10 permit 10.1.0.0 0.0.255.255
+access-list 10 permit host 10.2.0.1
+access-list 10 deny any log
+access-list 1300 permit 192.0.2.0`,
+			[]string{"10:2 permit 10.1.0.0/0.0.255.255", "10:3 permit 10.2.0.1/0.0.0.0",
+				"10:4 deny 0.0.0.0/255.255.255.255", "1300:5 permit 192.0.2.0/0.0.0.0"}, false},
+		{"numbered extended", `access-list 101 permit tcp 10.0.0.0 0.0.0.255 range 1024 65535 any eq 443 log
+access-list 101 deny ip any host 192.0.2.1`,
+			[]string{"101:1 permit tcp 10.0.0.0/0.0.0.255 range 1024 65535 0.0.0.0/255.255.255.255 eq 443",
+				"101:2 deny ip 0.0.0.0/255.255.255.255 192.0.2.1/0.0.0.0"}, false},
+		{"named with sequence numbers", `hostname router1
+ip access-list extended WEB
+ 10 permit tcp any 10.0.1.0 0.0.0.255 eq www
+ 20 deny ip 10.0.0.0 0.0.255.0 any
+ remark end of list
+interface Gi0/1
+ permit ip any any`,
+			[]string{"WEB:3 permit tcp 0.0.0.0/255.255.255.255 10.0.1.0/0.0.0.255 eq www",
+				"WEB:4 deny ip 10.0.0.0/0.0.255.0 0.0.0.0/255.255.255.255"}, false},
+		{"show access-lists", `Standard IP access list MGMT
+    10 permit 10.1.1.0, wildcard bits 0.0.0.255 (12 matches)
+    20 deny   any (3 matches)
+Extended IP access list 110
+    10 permit udp host 10.0.0.5 any eq domain (40 matches)`,
+			[]string{"MGMT:2 permit 10.1.1.0/0.0.0.255", "MGMT:3 deny 0.0.0.0/255.255.255.255",
+				"110:5 permit udp 10.0.0.5/0.0.0.0 0.0.0.0/255.255.255.255 eq domain"}, false},
+		{"nothing", "interface Gi0/1\n ip address 10.0.0.1 255.255.255.0", nil, false},
+		{"object group", "ip access-list extended WEB\n 10 permit tcp object-group CLIENTS any eq 443", nil, true},
+		{"missing protocol", "access-list 101 permit", nil, true},
+		{"ipv6 host", "access-list 10 permit host 2001:db8::1", nil, true},
+	}
+	for _, tt := range tests {
+		entries, err := ReadACL(strings.NewReader(tt.doc))
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: ReadACL() = %v, want an error", tt.name, entries)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: ReadACL() error = %v", tt.name, err)
+			continue
+		}
+		var got []string
+		for _, e := range entries {
+			got = append(got, aclSummary(e))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ReadACL() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadACLError(t *testing.T) {
+	_, err := ReadACL(strings.NewReader("access-list 10 permit 10.0.0.0\naccess-list 10 permit web"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Errorf("ReadACL() error = %v, want one for line 2", err)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+type awsTag struct {
+	Key   string `json:"Key"`
+	Value string `json:"Value"`
+}
+
+type awsIPv6Association struct {
+	Ipv6CidrBlock string `json:"Ipv6CidrBlock"`
+}
+
+type awsCIDRAssociation struct {
+	CidrBlock string `json:"CidrBlock"`
+}
+
+// awsExport holds the parts of `aws ec2 describe-vpcs` and `aws ec2 describe-subnets` output used in a plan.
+type awsExport struct {
+	Vpcs []struct {
+		VpcId                       string               `json:"VpcId"`
+		CidrBlock                   string               `json:"CidrBlock"`
+		CidrBlockAssociationSet     []awsCIDRAssociation `json:"CidrBlockAssociationSet"`
+		Ipv6CidrBlockAssociationSet []awsIPv6Association `json:"Ipv6CidrBlockAssociationSet"`
+	} `json:"Vpcs"`
+	Subnets []struct {
+		SubnetId                    string               `json:"SubnetId"`
+		VpcId                       string               `json:"VpcId"`
+		CidrBlock                   string               `json:"CidrBlock"`
+		AvailabilityZone            string               `json:"AvailabilityZone"`
+		Ipv6CidrBlockAssociationSet []awsIPv6Association `json:"Ipv6CidrBlockAssociationSet"`
+		Tags                        []awsTag             `json:"Tags"`
+	} `json:"Subnets"`
+}
+
+// awsName returns the value of the Name tag, or fallback if there is none.
+func awsName(tags []awsTag, fallback string) string {
+	for _, t := range tags {
+		if t.Key == "Name" && t.Value != "" {
+			return t.Value
+		}
+	}
+	return fallback
+}
+
+// AWS imports the output of `aws ec2 describe-vpcs` and/or `aws ec2 describe-subnets`. VPC CIDR blocks become the
+// plan's supernets and subnets become allocations labeled with their VPC and subnet IDs.
+func AWS(r io.Reader, p *subnet.Plan) error {
+	var export awsExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return fmt.Errorf("parsing aws export: %w", err)
+	}
+
+	for _, vpc := range export.Vpcs {
+		cidrs := []string{vpc.CidrBlock}
+		for _, a := range vpc.CidrBlockAssociationSet {
+			cidrs = append(cidrs, a.CidrBlock)
+		}
+		for _, a := range vpc.Ipv6CidrBlockAssociationSet {
+			cidrs = append(cidrs, a.Ipv6CidrBlock)
+		}
+		for _, cidr := range cidrs {
+			if cidr == "" {
+				continue
+			}
+			prefix, err := netip.ParsePrefix(cidr)
+			if err != nil {
+				return fmt.Errorf("vpc %s: %w", vpc.VpcId, err)
+			}
+			addSupernet(p, prefix)
+		}
+	}
+
+	for _, s := range export.Subnets {
+		cidrs := []string{s.CidrBlock}
+		for _, a := range s.Ipv6CidrBlockAssociationSet {
+			cidrs = append(cidrs, a.Ipv6CidrBlock)
+		}
+		for _, cidr := range cidrs {
+			if cidr == "" {
+				continue
+			}
+			prefix, err := netip.ParsePrefix(cidr)
+			if err != nil {
+				return fmt.Errorf("subnet %s: %w", s.SubnetId, err)
+			}
+			p.Allocations = append(p.Allocations, subnet.Allocation{
+				CIDR: prefix.Masked(),
+				Name: awsName(s.Tags, s.SubnetId),
+				Zone: s.AvailabilityZone,
+				Labels: map[string]string{
+					"provider":  "aws",
+					"vpc-id":    s.VpcId,
+					"subnet-id": s.SubnetId,
+				},
+			})
+		}
+	}
+	return nil
+}
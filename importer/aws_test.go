@@ -0,0 +1,66 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package importer
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+func TestAWS(t *testing.T) {
+	tests := []struct {
+		name        string
+		doc         string
+		supernets   []string
+		allocations []subnet.Allocation
+		wantErr     bool
+	}{
+		{"vpcs", `{"Vpcs": [{"VpcId": "vpc-1", "CidrBlock": "10.0.0.0/16",
+			"CidrBlockAssociationSet": [{"CidrBlock": "10.0.0.0/16"}, {"CidrBlock": "10.1.0.0/16"}],
+			"Ipv6CidrBlockAssociationSet": [{"Ipv6CidrBlock": "2600:1f18::/56"}]}]}`,
+			[]string{"10.0.0.0/16", "10.1.0.0/16", "2600:1f18::/56"}, nil, false},
+		{"subnets", `{"Subnets": [
+			{"SubnetId": "subnet-1", "VpcId": "vpc-1", "CidrBlock": "10.0.1.0/24", "AvailabilityZone": "us-east-1a",
+				"Tags": [{"Key": "env", "Value": "prod"}, {"Key": "Name", "Value": "web"}],
+				"Ipv6CidrBlockAssociationSet": [{"Ipv6CidrBlock": "2600:1f18::/64"}]},
+			{"SubnetId": "subnet-2", "VpcId": "vpc-1", "CidrBlock": "10.0.2.0/24"}]}`,
+			nil, []subnet.Allocation{
+				{CIDR: netip.MustParsePrefix("10.0.1.0/24"), Name: "web", Zone: "us-east-1a",
+					Labels: map[string]string{"provider": "aws", "vpc-id": "vpc-1", "subnet-id": "subnet-1"}},
+				{CIDR: netip.MustParsePrefix("2600:1f18::/64"), Name: "web", Zone: "us-east-1a",
+					Labels: map[string]string{"provider": "aws", "vpc-id": "vpc-1", "subnet-id": "subnet-1"}},
+				{CIDR: netip.MustParsePrefix("10.0.2.0/24"), Name: "subnet-2",
+					Labels: map[string]string{"provider": "aws", "vpc-id": "vpc-1", "subnet-id": "subnet-2"}},
+			}, false},
+		{"ipv6 only subnet", `{"Subnets": [{"SubnetId": "subnet-3", "VpcId": "vpc-1",
+			"Ipv6CidrBlockAssociationSet": [{"Ipv6CidrBlock": "2600:1f18:0:1::/64"}]}]}`,
+			nil, []subnet.Allocation{{CIDR: netip.MustParsePrefix("2600:1f18:0:1::/64"), Name: "subnet-3",
+				Labels: map[string]string{"provider": "aws", "vpc-id": "vpc-1", "subnet-id": "subnet-3"}}}, false},
+		{"invalid vpc cidr", `{"Vpcs": [{"VpcId": "vpc-1", "CidrBlock": "10.0.0.0/33"}]}`, nil, nil, true},
+		{"invalid subnet cidr", `{"Subnets": [{"SubnetId": "subnet-1", "CidrBlock": "web"}]}`, nil, nil, true},
+		{"not json", `Vpcs:`, nil, nil, true},
+	}
+	for _, tt := range tests {
+		p, err := runImporter(AWS, tt.doc)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: AWS() = %+v, want an error", tt.name, p)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: AWS() error = %v", tt.name, err)
+			continue
+		}
+		if want := mustPrefixes(tt.supernets...); len(p.Supernets)+len(want) > 0 && !reflect.DeepEqual(p.Supernets, want) {
+			t.Errorf("%s: supernets = %v, want %v", tt.name, p.Supernets, want)
+		}
+		if !reflect.DeepEqual(p.Allocations, tt.allocations) {
+			t.Errorf("%s: allocations = %+v, want %+v", tt.name, p.Allocations, tt.allocations)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package importer
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// azureVNet holds the parts of `az network vnet show` and `az network vnet list` output used in a plan.
+type azureVNet struct {
+	Name          string `json:"name"`
+	Location      string `json:"location"`
+	ResourceGroup string `json:"resourceGroup"`
+	AddressSpace  struct {
+		AddressPrefixes []string `json:"addressPrefixes"`
+	} `json:"addressSpace"`
+	Subnets []struct {
+		Name            string   `json:"name"`
+		AddressPrefix   string   `json:"addressPrefix"`
+		AddressPrefixes []string `json:"addressPrefixes"`
+	} `json:"subnets"`
+}
+
+// Azure imports the output of `az network vnet show` or `az network vnet list`. VNet address spaces become the plan's
+// supernets and subnets become allocations labeled with their VNet and resource group.
+func Azure(r io.Reader, p *subnet.Plan) error {
+	var vnets []azureVNet
+	if err := decodeList(r, &vnets); err != nil {
+		return fmt.Errorf("parsing azure export: %w", err)
+	}
+
+	for _, vnet := range vnets {
+		for _, cidr := range vnet.AddressSpace.AddressPrefixes {
+			prefix, err := netip.ParsePrefix(cidr)
+			if err != nil {
+				return fmt.Errorf("vnet %s: %w", vnet.Name, err)
+			}
+			addSupernet(p, prefix)
+		}
+
+		for _, s := range vnet.Subnets {
+			cidrs := s.AddressPrefixes
+			if s.AddressPrefix != "" {
+				cidrs = append([]string{s.AddressPrefix}, cidrs...)
+			}
+			for _, cidr := range cidrs {
+				prefix, err := netip.ParsePrefix(cidr)
+				if err != nil {
+					return fmt.Errorf("subnet %s: %w", s.Name, err)
+				}
+				p.Allocations = append(p.Allocations, subnet.Allocation{
+					CIDR: prefix.Masked(),
+					Name: s.Name,
+					Zone: vnet.Location,
+					Labels: map[string]string{
+						"provider":       "azure",
+						"vnet":           vnet.Name,
+						"resource-group": vnet.ResourceGroup,
+					},
+				})
+			}
+		}
+	}
+	return nil
+}
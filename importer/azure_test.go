@@ -0,0 +1,63 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package importer
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+func TestAzure(t *testing.T) {
+	labels := map[string]string{"provider": "azure", "vnet": "hub", "resource-group": "net-rg"}
+	tests := []struct {
+		name        string
+		doc         string
+		supernets   []netip.Prefix
+		allocations []subnet.Allocation
+		wantErr     bool
+	}{
+		{"vnet list", `[{"name": "hub", "location": "eastus", "resourceGroup": "net-rg",
+			"addressSpace": {"addressPrefixes": ["10.0.0.0/16", "fd00:db8::/48"]},
+			"subnets": [{"name": "web", "addressPrefix": "10.0.1.0/24"},
+				{"name": "app", "addressPrefixes": ["10.0.2.0/24", "fd00:db8:0:2::/64"]}]}]`,
+			mustPrefixes("10.0.0.0/16", "fd00:db8::/48"), []subnet.Allocation{
+				{CIDR: netip.MustParsePrefix("10.0.1.0/24"), Name: "web", Zone: "eastus", Labels: labels},
+				{CIDR: netip.MustParsePrefix("10.0.2.0/24"), Name: "app", Zone: "eastus", Labels: labels},
+				{CIDR: netip.MustParsePrefix("fd00:db8:0:2::/64"), Name: "app", Zone: "eastus", Labels: labels},
+			}, false},
+		{"single vnet", `{"name": "hub", "location": "eastus", "resourceGroup": "net-rg",
+			"addressSpace": {"addressPrefixes": ["10.0.0.0/16"]},
+			"subnets": [{"name": "gw", "addressPrefix": "10.0.0.0/27", "addressPrefixes": ["fd00:db8::/64"]}]}`,
+			mustPrefixes("10.0.0.0/16"), []subnet.Allocation{
+				{CIDR: netip.MustParsePrefix("10.0.0.0/27"), Name: "gw", Zone: "eastus", Labels: labels},
+				{CIDR: netip.MustParsePrefix("fd00:db8::/64"), Name: "gw", Zone: "eastus", Labels: labels},
+			}, false},
+		{"invalid address space", `{"name": "hub", "addressSpace": {"addressPrefixes": ["10.0.0.0"]}}`, nil, nil, true},
+		{"invalid subnet", `{"name": "hub", "subnets": [{"name": "web", "addressPrefix": "10.0.1.0/240"}]}`, nil, nil,
+			true},
+		{"not json", `hub`, nil, nil, true},
+	}
+	for _, tt := range tests {
+		p, err := runImporter(Azure, tt.doc)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: Azure() = %+v, want an error", tt.name, p)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Azure() error = %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(p.Supernets, tt.supernets) {
+			t.Errorf("%s: supernets = %v, want %v", tt.name, p.Supernets, tt.supernets)
+		}
+		if !reflect.DeepEqual(p.Allocations, tt.allocations) {
+			t.Errorf("%s: allocations = %+v, want %+v", tt.name, p.Allocations, tt.allocations)
+		}
+	}
+}
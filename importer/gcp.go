@@ -0,0 +1,72 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package importer
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+	"path"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// gcpSubnet holds the parts of `gcloud compute networks subnets list --format=json` output used in a plan.
+type gcpSubnet struct {
+	Name              string `json:"name"`
+	Network           string `json:"network"`
+	Region            string `json:"region"`
+	IPCidrRange       string `json:"ipCidrRange"`
+	InternalIPv6      string `json:"internalIpv6Prefix"`
+	ExternalIPv6      string `json:"externalIpv6Prefix"`
+	SecondaryIPRanges []struct {
+		RangeName   string `json:"rangeName"`
+		IPCidrRange string `json:"ipCidrRange"`
+	} `json:"secondaryIpRanges"`
+}
+
+// GCP imports the output of `gcloud compute networks subnets list --format=json`. VPC networks in GCP don't have an
+// address space of their own, so only allocations are added. Secondary ranges are imported as separate allocations
+// named after the range.
+func GCP(r io.Reader, p *subnet.Plan) error {
+	var subnets []gcpSubnet
+	if err := decodeList(r, &subnets); err != nil {
+		return fmt.Errorf("parsing gcp export: %w", err)
+	}
+
+	for _, s := range subnets {
+		add := func(name, cidr string) error {
+			if cidr == "" {
+				return nil
+			}
+			prefix, err := netip.ParsePrefix(cidr)
+			if err != nil {
+				return fmt.Errorf("subnet %s: %w", s.Name, err)
+			}
+			p.Allocations = append(p.Allocations, subnet.Allocation{
+				CIDR: prefix.Masked(),
+				Name: name,
+				// network and region are resource URLs, only the last element is interesting
+				Zone: path.Base(s.Region),
+				Labels: map[string]string{
+					"provider": "gcp",
+					"network":  path.Base(s.Network),
+				},
+			})
+			return nil
+		}
+
+		for _, cidr := range []string{s.IPCidrRange, s.InternalIPv6, s.ExternalIPv6} {
+			if err := add(s.Name, cidr); err != nil {
+				return err
+			}
+		}
+		for _, r := range s.SecondaryIPRanges {
+			if err := add(r.RangeName, r.IPCidrRange); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package importer
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+func TestGCP(t *testing.T) {
+	labels := map[string]string{"provider": "gcp", "network": "prod"}
+	tests := []struct {
+		name        string
+		doc         string
+		allocations []subnet.Allocation
+		wantErr     bool
+	}{
+		{"subnet list", `[{"name": "web", "ipCidrRange": "10.10.0.0/20",
+			"network": "https://www.googleapis.com/compute/v1/projects/p/global/networks/prod",
+			"region": "https://www.googleapis.com/compute/v1/projects/p/regions/us-central1",
+			"internalIpv6Prefix": "fd20:a:b:0:0:0:0:0/64",
+			"secondaryIpRanges": [{"rangeName": "pods", "ipCidrRange": "10.20.0.0/14"}]}]`,
+			[]subnet.Allocation{
+				{CIDR: netip.MustParsePrefix("10.10.0.0/20"), Name: "web", Zone: "us-central1", Labels: labels},
+				{CIDR: netip.MustParsePrefix("fd20:a:b::/64"), Name: "web", Zone: "us-central1", Labels: labels},
+				{CIDR: netip.MustParsePrefix("10.20.0.0/14"), Name: "pods", Zone: "us-central1", Labels: labels},
+			}, false},
+		{"single subnet", `{"name": "db", "ipCidrRange": "10.10.16.0/24", "network": "prod", "region": "europe-west1",
+			"externalIpv6Prefix": "2600:1900:4000:1::/64"}`,
+			[]subnet.Allocation{
+				{CIDR: netip.MustParsePrefix("10.10.16.0/24"), Name: "db", Zone: "europe-west1", Labels: labels},
+				{CIDR: netip.MustParsePrefix("2600:1900:4000:1::/64"), Name: "db", Zone: "europe-west1", Labels: labels},
+			}, false},
+		{"invalid range", `{"name": "web", "ipCidrRange": "10.10.0.0"}`, nil, true},
+		{"invalid secondary range", `{"name": "web", "secondaryIpRanges": [{"rangeName": "pods", "ipCidrRange": "pods"}]}`,
+			nil, true},
+		{"not json", `web`, nil, true},
+	}
+	for _, tt := range tests {
+		p, err := runImporter(GCP, tt.doc)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: GCP() = %+v, want an error", tt.name, p)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: GCP() error = %v", tt.name, err)
+			continue
+		}
+		// gcp exports have no network ranges of their own, only subnets
+		if len(p.Supernets) != 0 {
+			t.Errorf("%s: supernets = %v, want none", tt.name, p.Supernets)
+		}
+		if !reflect.DeepEqual(p.Allocations, tt.allocations) {
+			t.Errorf("%s: allocations = %+v, want %+v", tt.name, p.Allocations, tt.allocations)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+
+// Package importer converts the network inventories exported by cloud provider CLIs, and hand-maintained prefix lists,
+// into subnetCalc plans.
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/netip"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// Importer parses a provider export and adds its networks to a plan.
+type Importer func(r io.Reader, p *subnet.Plan) error
+
+// Importers maps provider names to their importers.
+var Importers = map[string]Importer{
+	"aws":   AWS,
+	"azure": Azure,
+	"gcp":   GCP,
+	"list":  List,
+}
+
+// decodeList decodes either a JSON array or a single JSON object into v, which must point to a slice.
+func decodeList[T any](r io.Reader, v *[]T) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '{' {
+		var single T
+		if err := json.Unmarshal(b, &single); err != nil {
+			return err
+		}
+		*v = append(*v, single)
+		return nil
+	}
+	return json.Unmarshal(b, v)
+}
+
+// addSupernet adds a prefix to the plan's supernets unless it is already present.
+func addSupernet(p *subnet.Plan, prefix netip.Prefix) {
+	prefix = prefix.Masked()
+	for _, s := range p.Supernets {
+		if s == prefix {
+			return
+		}
+	}
+	p.Supernets = append(p.Supernets, prefix)
+}
@@ -0,0 +1,69 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package importer
+
+import (
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// runImporter imports doc into an empty plan with imp.
+// returns the plan and the importer's error.
+func runImporter(imp Importer, doc string) (subnet.Plan, error) {
+	var p subnet.Plan
+	err := imp(strings.NewReader(doc), &p)
+	return p, err
+}
+
+// mustPrefixes parses each of s as a prefix.
+func mustPrefixes(s ...string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, len(s))
+	for i, p := range s {
+		prefixes[i] = netip.MustParsePrefix(p)
+	}
+	return prefixes
+}
+
+func TestAddSupernet(t *testing.T) {
+	var p subnet.Plan
+	for _, s := range []string{"10.0.0.0/16", "10.0.1.2/16", "2001:db8::/48", "10.0.0.0/8"} {
+		addSupernet(&p, netip.MustParsePrefix(s))
+	}
+	want := mustPrefixes("10.0.0.0/16", "2001:db8::/48", "10.0.0.0/8")
+	if !reflect.DeepEqual(p.Supernets, want) {
+		t.Errorf("supernets = %v, want %v", p.Supernets, want)
+	}
+}
+
+func TestDecodeList(t *testing.T) {
+	tests := []struct {
+		name, doc string
+		want      []int
+		wantErr   bool
+	}{
+		{"array", `[{"n": 1}, {"n": 2}]`, []int{1, 2}, false},
+		{"single object", ` {"n": 3}`, []int{3}, false},
+		{"empty array", `[]`, nil, false},
+		{"invalid", `{"n": `, nil, true},
+	}
+	for _, tt := range tests {
+		var got []struct{ N int }
+		err := decodeList(strings.NewReader(tt.doc), &got)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: decodeList() error = %v, want an error %t", tt.name, err, tt.wantErr)
+			continue
+		}
+		var ns []int
+		for _, v := range got {
+			ns = append(ns, v.N)
+		}
+		if !reflect.DeepEqual(ns, tt.want) {
+			t.Errorf("%s: decodeList() = %v, want %v", tt.name, ns, tt.want)
+		}
+	}
+}
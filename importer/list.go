@@ -0,0 +1,83 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+// ReadList reads a hand-maintained list of prefixes, one per line, keeping the metadata written alongside them. The
+// first whitespace or comma separated field of a line is the prefix, or a bare address read as a single host prefix.
+// id=, status=, zone=, vlan=, and pair= fields set those fields of the allocation, other key=value fields become
+// labels, and the remaining fields are joined into its name. Anything after a # becomes its note. Blank and
+// comment-only lines are skipped.
+func ReadList(r io.Reader) ([]subnet.Allocation, error) {
+	var allocations []subnet.Allocation
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text, comment, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.FieldsFunc(text, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+		if len(fields) == 0 {
+			continue
+		}
+		p, err := netip.ParsePrefix(fields[0])
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(fields[0])
+			if addrErr != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			p = netip.PrefixFrom(addr, addr.BitLen())
+		}
+
+		a := subnet.Allocation{CIDR: p.Masked(), Note: strings.TrimSpace(comment)}
+		var name []string
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			switch {
+			case !ok:
+				name = append(name, field)
+			case key == "id":
+				a.ID = value
+			case key == "status":
+				a.Status = value
+			case key == "zone":
+				a.Zone = value
+			case key == "pair":
+				a.Pair = value
+			case key == "vlan":
+				vlan, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid vlan %q", line, value)
+				}
+				a.VLAN = vlan
+			default:
+				if a.Labels == nil {
+					a.Labels = map[string]string{}
+				}
+				a.Labels[key] = value
+			}
+		}
+		a.Name = strings.Join(name, " ")
+		allocations = append(allocations, a)
+	}
+	return allocations, scanner.Err()
+}
+
+// List imports a plain text list of prefixes, as read by ReadList, as allocations.
+func List(r io.Reader, p *subnet.Plan) error {
+	allocations, err := ReadList(r)
+	if err != nil {
+		return fmt.Errorf("parsing list: %w", err)
+	}
+	p.Allocations = append(p.Allocations, allocations...)
+	return nil
+}
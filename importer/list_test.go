@@ -0,0 +1,60 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package importer
+
+import (
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/JakeTRogers/subnetCalc/subnet"
+)
+
+func TestReadList(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    []subnet.Allocation
+		wantErr string
+	}{
+		{"prefixes", "10.0.0.0/24\n\n2001:db8::/64\n", []subnet.Allocation{
+			{CIDR: netip.MustParsePrefix("10.0.0.0/24")}, {CIDR: netip.MustParsePrefix("2001:db8::/64")}}, ""},
+		{"addresses and host bits", "192.0.2.1\n10.0.0.5/24\n", []subnet.Allocation{
+			{CIDR: netip.MustParsePrefix("192.0.2.1/32")}, {CIDR: netip.MustParsePrefix("10.0.0.0/24")}}, ""},
+		{"names and comments", "# the core\n10.0.0.0/24 core net  # owned by netops\n10.0.1.0/24,web\n", []subnet.Allocation{
+			{CIDR: netip.MustParsePrefix("10.0.0.0/24"), Name: "core net", Note: "owned by netops"},
+			{CIDR: netip.MustParsePrefix("10.0.1.0/24"), Name: "web"}}, ""},
+		{"fields and labels", "10.0.0.0/24 db id=a1 status=reserved zone=az1 vlan=40 pair=b1 env=prod\n",
+			[]subnet.Allocation{{ID: "a1", CIDR: netip.MustParsePrefix("10.0.0.0/24"), Name: "db", Status: "reserved",
+				Zone: "az1", VLAN: 40, Pair: "b1", Labels: map[string]string{"env": "prod"}}}, ""},
+		{"invalid prefix", "10.0.0.0/24\nweb 10.0.1.0/24\n", nil, "line 2"},
+		{"invalid vlan", "10.0.0.0/24 vlan=forty\n", nil, `line 1: invalid vlan "forty"`},
+	}
+	for _, tt := range tests {
+		got, err := ReadList(strings.NewReader(tt.list))
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: ReadList() = %+v, %v, want an error containing %q", tt.name, got, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ReadList() = %+v, %v, want %+v", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	p := subnet.Plan{Allocations: []subnet.Allocation{{CIDR: netip.MustParsePrefix("10.0.0.0/24"), Name: "existing"}}}
+	if err := List(strings.NewReader("10.0.1.0/24 web\n"), &p); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Allocations) != 2 || p.Allocations[1].Name != "web" || len(p.Supernets) != 0 {
+		t.Errorf("List() made %+v, want the web allocation appended and no supernets", p)
+	}
+	if err := List(strings.NewReader("web\n"), &p); err == nil || !strings.Contains(err.Error(), "parsing list") {
+		t.Errorf("List() of an invalid list = %v, want a parsing error", err)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package importer
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/netip"
+	"strings"
+	"unicode"
+)
+
+// Neighbor is an entry of an ARP or NDP neighbor cache.
+type Neighbor struct {
+	Addr      netip.Addr `json:"addr"`
+	MAC       string     `json:"mac"`
+	Interface string     `json:"interface,omitempty"`
+	State     string     `json:"state,omitempty"`
+}
+
+// ReadNeighbors reads a neighbor cache in the format printed by 'ip neigh', 'arp -an', or the Windows 'arp -a'.
+// Entries without a link-layer address, such as failed or incomplete resolutions, and lines that aren't entries are
+// skipped.
+func ReadNeighbors(r io.Reader) ([]Neighbor, error) {
+	var neighbors []Neighbor
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		var n Neighbor
+		for i, field := range fields {
+			next := ""
+			if i+1 < len(fields) {
+				next = fields[i+1]
+			}
+			switch {
+			case !n.Addr.IsValid():
+				// 'ip neigh' and Windows start with the address, 'arp -an' wraps it in parentheses
+				if a, err := netip.ParseAddr(strings.Trim(field, "()")); err == nil {
+					n.Addr = a.WithZone("")
+				}
+			case field == "lladdr" || field == "at":
+				n.MAC = next
+			case field == "dev" || field == "on":
+				n.Interface = next
+			case n.MAC == "" && i == 1:
+				n.MAC = field
+			}
+		}
+		// 'ip neigh' ends each entry with its state, such as REACHABLE or STALE
+		if last := fields[len(fields)-1]; strings.ToUpper(last) == last && unicode.IsUpper(rune(last[0])) {
+			n.State = last
+		}
+		mac, err := parseMAC(n.MAC)
+		if !n.Addr.IsValid() || err != nil {
+			continue
+		}
+		n.MAC = mac.String()
+		neighbors = append(neighbors, n)
+	}
+	return neighbors, scanner.Err()
+}
+
+// parseMAC parses a MAC address separated by colons or dashes, allowing the single digit octets printed by BSD arp.
+func parseMAC(s string) (net.HardwareAddr, error) {
+	octets := strings.FieldsFunc(s, func(r rune) bool { return r == ':' || r == '-' })
+	for i, o := range octets {
+		if len(o) == 1 {
+			octets[i] = "0" + o
+		}
+	}
+	return net.ParseMAC(strings.Join(octets, ":"))
+}
@@ -0,0 +1,146 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"math/big"
+	"net/netip"
+)
+
+// ACLMatch is an address and wildcard mask pair from an access control list entry. Addresses match if they agree with
+// Addr in every bit the wildcard leaves clear.
+type ACLMatch struct {
+	Addr     netip.Addr   `json:"addr"`
+	Wildcard netip.Addr   `json:"wildcard"`
+	Prefix   netip.Prefix `json:"prefix,omitempty"` // the pair as a prefix, if the wildcard is contiguous
+}
+
+// NewACLMatch returns the match for addr and wildcard, with its prefix set if the wildcard is contiguous.
+func NewACLMatch(addr, wildcard netip.Addr) ACLMatch {
+	m := ACLMatch{Addr: addr, Wildcard: wildcard}
+	if bits, ok := MaskLen(MaskFromWildcard(wildcard)); ok {
+		m.Prefix = netip.PrefixFrom(addr, bits).Masked()
+	}
+	return m
+}
+
+// Contiguous reports whether the wildcard is the inverse of a subnet mask, so the match is a prefix.
+func (m ACLMatch) Contiguous() bool {
+	return m.Prefix.IsValid()
+}
+
+// Contains reports whether every address o matches is also matched by m. Non-contiguous wildcards are compared bit by
+// bit, so this works for any pair.
+func (m ACLMatch) Contains(o ACLMatch) bool {
+	if m.Addr.BitLen() != o.Addr.BitLen() {
+		return false
+	}
+	care := u128(m.Wildcard).xor(hostMask(0, m.Addr.BitLen()))
+	// o may only vary in bits m ignores, and the bits m cares about must agree
+	return u128(o.Wildcard).and(care) == uint128{} && u128(m.Addr).xor(u128(o.Addr)).and(care) == uint128{}
+}
+
+// ACLEntry is a permit or deny entry of an access control list.
+type ACLEntry struct {
+	ACL         string    `json:"acl"`
+	Line        int       `json:"line"`
+	Action      string    `json:"action"`
+	Protocol    string    `json:"protocol,omitempty"` // empty for standard ACLs
+	Source      ACLMatch  `json:"source"`
+	SourcePorts string    `json:"sourcePorts,omitempty"`
+	Destination *ACLMatch `json:"destination,omitempty"` // nil for standard ACLs
+	Options     string    `json:"options,omitempty"`     // destination ports and other qualifiers, compared verbatim
+	Text        string    `json:"text"`
+}
+
+// Shadows reports whether e matches every packet later matches, so an entry after e in the same ACL is never reached.
+// Ports and other qualifiers are compared as written, so e only shadows entries with the same ones unless it has none.
+func (e ACLEntry) Shadows(later ACLEntry) bool {
+	switch {
+	case e.ACL != later.ACL:
+		return false
+	case e.Protocol != later.Protocol && e.Protocol != "ip":
+		return false
+	case e.SourcePorts != "" && e.SourcePorts != later.SourcePorts:
+		return false
+	case e.Options != "" && e.Options != later.Options:
+		return false
+	case !e.Source.Contains(later.Source):
+		return false
+	case e.Destination == nil || later.Destination == nil:
+		return e.Destination == nil && later.Destination == nil
+	}
+	return e.Destination.Contains(*later.Destination)
+}
+
+// ACLShadow is an entry that is never reached because an earlier entry matches everything it does. It is Redundant
+// if both entries have the same action, and a conflict that silently loses its action otherwise.
+type ACLShadow struct {
+	Entry     ACLEntry `json:"entry"`
+	By        ACLEntry `json:"by"`
+	Redundant bool     `json:"redundant"`
+}
+
+// ACLCoverage is the set of source addresses matched by an ACL's permit entries, aggregated into the fewest prefixes.
+type ACLCoverage struct {
+	ACL       string         `json:"acl"`
+	Permitted []netip.Prefix `json:"permitted"`
+	Addresses *big.Int       `json:"addresses"`
+}
+
+// ACLOverlap is a pair of overlapping source prefixes within an ACL.
+type ACLOverlap struct {
+	ACL string `json:"acl"`
+	Overlap
+}
+
+// ACLAudit is the result of auditing the entries of one or more ACLs.
+type ACLAudit struct {
+	Entries       int           `json:"entries"`
+	NonContiguous []ACLEntry    `json:"nonContiguous,omitempty"`
+	Coverage      []ACLCoverage `json:"coverage"`
+	Overlaps      []ACLOverlap  `json:"overlaps,omitempty"`
+	Shadowed      []ACLShadow   `json:"shadowed,omitempty"`
+}
+
+// AuditACL audits ACL entries, in the order they are evaluated: entries with non-contiguous wildcards, which can't be
+// written as prefixes, the sources of each ACL's permit entries, overlapping source prefixes, and entries shadowed by an
+// earlier one. Coverage ignores deny entries and non-contiguous sources, and overlaps ignore "any".
+func AuditACL(entries []ACLEntry) ACLAudit {
+	audit := ACLAudit{Entries: len(entries)}
+	var names []string
+	permitted := map[string][]netip.Prefix{}
+	sources := map[string][]netip.Prefix{}
+	for i, e := range entries {
+		if _, ok := sources[e.ACL]; !ok {
+			names = append(names, e.ACL)
+			sources[e.ACL] = nil
+		}
+		if !e.Source.Contiguous() || (e.Destination != nil && !e.Destination.Contiguous()) {
+			audit.NonContiguous = append(audit.NonContiguous, e)
+		}
+		if p := e.Source.Prefix; p.IsValid() {
+			if e.Action == "permit" {
+				permitted[e.ACL] = append(permitted[e.ACL], p)
+			}
+			if p.Bits() > 0 {
+				sources[e.ACL] = append(sources[e.ACL], p)
+			}
+		}
+		for _, earlier := range entries[:i] {
+			if earlier.Shadows(e) {
+				audit.Shadowed = append(audit.Shadowed, ACLShadow{Entry: e, By: earlier, Redundant: earlier.Action == e.Action})
+				break
+			}
+		}
+	}
+	for _, name := range names {
+		aggregated := Aggregate(permitted[name])
+		audit.Coverage = append(audit.Coverage, ACLCoverage{ACL: name, Permitted: aggregated, Addresses: Coverage(aggregated)})
+		for _, o := range FindOverlaps(sources[name]) {
+			audit.Overlaps = append(audit.Overlaps, ACLOverlap{ACL: name, Overlap: o})
+		}
+	}
+	return audit
+}
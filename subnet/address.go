@@ -0,0 +1,94 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strings"
+)
+
+// AddressInfo describes a single address given without a prefix.
+type AddressInfo struct {
+	Addr           netip.Addr    `json:"addr"`
+	Classification string        `json:"classification"`
+	Block          *netip.Prefix `json:"block,omitempty"` // most specific special-purpose block containing the address
+	RFC            string        `json:"rfc,omitempty"`
+	ReverseName    string        `json:"reverseName"`
+	Integer        *big.Int      `json:"integer"`
+	Hex            string        `json:"hex"`
+}
+
+// NewAddressInfo returns the classification, reverse DNS name, and integer forms of address a.
+func NewAddressInfo(a netip.Addr) AddressInfo {
+	a = a.WithZone("")
+	info := AddressInfo{
+		Addr:           a,
+		Classification: Classify(netip.PrefixFrom(a, a.BitLen())),
+		ReverseName:    ReverseName(a),
+		Integer:        u128(a).big(),
+		Hex:            hexString(a),
+	}
+	if b := specialBlockFor(netip.PrefixFrom(a, a.BitLen())); b != nil {
+		info.Block, info.RFC = &b.Prefix, b.RFC
+	}
+	return info
+}
+
+// hexString returns a as a single hex number, such as 0x0a010203 for 10.1.2.3.
+func hexString(a netip.Addr) string {
+	return fmt.Sprintf("0x%x", a.AsSlice())
+}
+
+// NumericAddr is an address as a decimal integer and as hex, for importing into databases and IPAM tools that store
+// addresses as numbers.
+type NumericAddr struct {
+	Integer *big.Int `json:"integer"`
+	Hex     string   `json:"hex"`
+}
+
+// NewNumericAddr returns the integer and hex forms of a.
+func NewNumericAddr(a netip.Addr) NumericAddr {
+	return NumericAddr{Integer: u128(a).big(), Hex: hexString(a)}
+}
+
+// NumericForms holds the integer and hex forms of a network's addresses. End is the last address of the network, its
+// broadcast address if it has one.
+type NumericForms struct {
+	Network   NumericAddr `json:"network"`
+	FirstHost NumericAddr `json:"firstHost"`
+	LastHost  NumericAddr `json:"lastHost"`
+	End       NumericAddr `json:"end"`
+	Mask      NumericAddr `json:"mask"`
+}
+
+// SetNumeric records the integer and hex forms of the addresses of the network and each of its subnets in n.Numeric.
+// Call it after any conventions that move the host range have been applied.
+func (n *Network) SetNumeric() {
+	n.Numeric = &NumericForms{
+		Network:   NewNumericAddr(n.NetworkAddr),
+		FirstHost: NewNumericAddr(n.FirstHostIP),
+		LastHost:  NewNumericAddr(n.LastHostIP),
+		End:       NewNumericAddr(n.BroadcastAddr),
+		Mask:      NewNumericAddr(n.SubnetMask),
+	}
+	for i := range n.Subnets {
+		n.Subnets[i].SetNumeric()
+	}
+}
+
+// ReverseName returns the name looked up for a PTR record of address a, under in-addr.arpa for IPv4 and ip6.arpa for
+// IPv6.
+func ReverseName(a netip.Addr) string {
+	if a.Is4() {
+		return reverseName(a, 4)
+	}
+	b := a.As16()
+	labels := make([]string, 0, 33)
+	for i := len(b) - 1; i >= 0; i-- {
+		labels = append(labels, fmt.Sprintf("%x", b[i]&0xf), fmt.Sprintf("%x", b[i]>>4))
+	}
+	return strings.Join(append(labels, "ip6.arpa"), ".")
+}
@@ -0,0 +1,110 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"math/big"
+	"net/netip"
+)
+
+// NextFree returns the first prefix of size bits inside pool that doesn't overlap any of the used prefixes.
+// returns false if the pool has no room left for a prefix of that size.
+func NextFree(pool netip.Prefix, bits int, used []netip.Prefix) (netip.Prefix, bool) {
+	pool = pool.Masked()
+	if bits < pool.Bits() || bits > pool.Addr().BitLen() {
+		return netip.Prefix{}, false
+	}
+
+	var inUse Trie[struct{}]
+	for _, u := range used {
+		inUse.Insert(u, struct{}{})
+	}
+
+	candidate, _ := pool.Addr().Prefix(bits)
+	for pool.Contains(candidate.Addr()) {
+		// a used prefix containing the candidate blocks every candidate inside it, so skip past the largest one.
+		// otherwise, a used prefix inside the candidate only blocks the candidate
+		var blocker netip.Prefix
+		inUse.Supernets(candidate, func(p netip.Prefix, _ struct{}) bool {
+			blocker = p
+			return true
+		})
+		if !blocker.IsValid() && inUse.Overlaps(candidate) {
+			blocker = candidate
+		}
+		if !blocker.IsValid() {
+			return candidate, true
+		}
+		next := lastAddr(blocker).Next()
+		if !next.IsValid() {
+			break
+		}
+		candidate, _ = next.Prefix(bits)
+	}
+	return netip.Prefix{}, false
+}
+
+// Exclude returns the smallest set of prefixes covering the addresses in supernet that aren't in any of the excluded
+// prefixes, in address order.
+func Exclude(supernet netip.Prefix, excluded []netip.Prefix) []netip.Prefix {
+	var t Trie[struct{}]
+	for _, e := range excluded {
+		t.Insert(e, struct{}{})
+	}
+	return exclude(supernet.Masked(), &t)
+}
+
+// exclude returns the prefixes covering the addresses in supernet that aren't in the excluded trie.
+func exclude(supernet netip.Prefix, excluded *Trie[struct{}]) []netip.Prefix {
+	if !excluded.Overlaps(supernet) {
+		return []netip.Prefix{supernet}
+	}
+	covered := false
+	excluded.Supernets(supernet, func(netip.Prefix, struct{}) bool {
+		covered = true
+		return false
+	})
+	if covered {
+		return nil
+	}
+
+	// split the supernet in half and exclude from each half
+	lower := netip.PrefixFrom(supernet.Addr(), supernet.Bits()+1)
+	upper := netip.PrefixFrom(lastAddr(lower).Next(), supernet.Bits()+1)
+	return append(exclude(lower, excluded), exclude(upper, excluded)...)
+}
+
+// Buddy returns the sibling of prefix p: the other half of the block one bit shorter that contains it.
+// returns false for a prefix of length 0, which has no sibling.
+func Buddy(p netip.Prefix) (netip.Prefix, bool) {
+	p = p.Masked()
+	if p.Bits() == 0 {
+		return netip.Prefix{}, false
+	}
+	sibling, _ := u128(p.Addr()).xor(uint128{lo: 1}.lsh(uint(p.Addr().BitLen() - p.Bits()))).addr(p.Addr().BitLen())
+	return netip.PrefixFrom(sibling, p.Bits()), true
+}
+
+// Largest returns the largest of the prefixes, preferring the lowest address on a tie.
+// returns false if prefixes is empty.
+func Largest(prefixes []netip.Prefix) (netip.Prefix, bool) {
+	var largest netip.Prefix
+	for _, p := range prefixes {
+		if !largest.IsValid() || p.Bits() < largest.Bits() || p.Bits() == largest.Bits() && p.Addr().Less(largest.Addr()) {
+			largest = p
+		}
+	}
+	return largest, largest.IsValid()
+}
+
+// FitCount returns how many prefixes of size bits fit in the free prefixes.
+func FitCount(free []netip.Prefix, bits int) *big.Int {
+	count := new(big.Int)
+	for _, f := range free {
+		if f.Bits() <= bits && bits <= f.Addr().BitLen() {
+			count.Add(count, new(big.Int).Lsh(big.NewInt(1), uint(bits-f.Bits())))
+		}
+	}
+	return count
+}
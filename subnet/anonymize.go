@@ -0,0 +1,137 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// Mapping replaces the addresses in From with addresses in To.
+type Mapping struct {
+	From netip.Prefix
+	To   netip.Prefix
+}
+
+// ParseMapping parses a mapping in "from=to" notation, e.g. "10.0.0.0/8=198.51.100.0/24".
+// returns an error if either prefix is invalid or they are of different address families.
+func ParseMapping(s string) (Mapping, error) {
+	from, to, ok := strings.Cut(s, "=")
+	if !ok {
+		return Mapping{}, fmt.Errorf("invalid mapping %q, expected from=to", s)
+	}
+	var m Mapping
+	var err error
+	if m.From, err = netip.ParsePrefix(strings.TrimSpace(from)); err != nil {
+		return Mapping{}, err
+	}
+	if m.To, err = netip.ParsePrefix(strings.TrimSpace(to)); err != nil {
+		return Mapping{}, err
+	}
+	if m.From.Addr().Is4() != m.To.Addr().Is4() {
+		return Mapping{}, fmt.Errorf("mapping %q mixes IPv4 and IPv6 prefixes", s)
+	}
+	m.From, m.To = m.From.Masked(), m.To.Masked()
+	return m, nil
+}
+
+// maxCachedFlips is the number of flip decisions an Anonymizer keeps before starting over, which bounds its memory on
+// long inputs. Each address can add a decision for every one of its host bits, up to 128 for an IPv6 address.
+const maxCachedFlips = 1 << 16
+
+// Anonymizer rewrites addresses into replacement prefixes with a keyed prefix-preserving mapping: two addresses that
+// share their first n bits are rewritten to addresses that share their first n bits, so subnet relationships survive.
+// When the replacement prefix is smaller than the prefix it replaces, relationships are only preserved within blocks
+// the size of the replacement, and addresses from different blocks may be rewritten to the same address.
+type Anonymizer struct {
+	key      []byte
+	mappings []Mapping
+	flips    map[netip.Prefix]bool
+}
+
+// NewAnonymizer returns an anonymizer for the mappings. The same key always produces the same rewritten addresses.
+func NewAnonymizer(key []byte, mappings []Mapping) *Anonymizer {
+	return &Anonymizer{key: key, mappings: mappings, flips: map[netip.Prefix]bool{}}
+}
+
+// flip returns the keyed pseudo-random bit deciding whether the bit following prefix p is inverted.
+func (a *Anonymizer) flip(p netip.Prefix) bool {
+	if f, ok := a.flips[p]; ok {
+		return f
+	}
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(p.String()))
+	f := mac.Sum(nil)[0]&1 == 1
+	if len(a.flips) >= maxCachedFlips {
+		// decisions only depend on the key, so dropping them costs time, not consistency
+		clear(a.flips)
+	}
+	a.flips[p] = f
+	return f
+}
+
+// Addr returns the rewritten address, using the most specific mapping containing addr.
+// returns false if no mapping contains addr.
+func (a *Anonymizer) Addr(addr netip.Addr) (netip.Addr, bool) {
+	var m Mapping
+	for _, candidate := range a.mappings {
+		if candidate.From.Contains(addr) && (!m.From.IsValid() || candidate.From.Bits() > m.From.Bits()) {
+			m = candidate
+		}
+	}
+	if !m.From.IsValid() {
+		return netip.Addr{}, false
+	}
+
+	// invert each host bit depending on the bits before it, which maps the host bits to a permutation of themselves
+	out := addr.AsSlice()
+	for i := m.From.Bits(); i < addr.BitLen(); i++ {
+		prefix, _ := addr.Prefix(i)
+		if a.flip(prefix) {
+			out[i/8] ^= 1 << uint(7-i%8)
+		}
+	}
+
+	// copy as many of the low bits as fit into the replacement prefix. when the replacement is larger, the bits
+	// between the two prefix lengths are left zero
+	result := m.To.Addr().AsSlice()
+	for i := max(m.To.Bits(), m.From.Bits()); i < len(out)*8; i++ {
+		mask := byte(1 << uint(7-i%8))
+		result[i/8] = result[i/8]&^mask | out[i/8]&mask
+	}
+	rewritten, _ := netip.AddrFromSlice(result)
+	return rewritten, true
+}
+
+// Text returns s with every address inside a mapping rewritten. Network addresses followed by their prefix length are
+// masked to it, so they remain network addresses.
+func (a *Anonymizer) Text(s string) string {
+	return addrPattern.ReplaceAllStringFunc(s, func(match string) string {
+		text, length, hasLength := strings.Cut(match, "/")
+		addr, err := netip.ParseAddr(text)
+		if err != nil {
+			return match
+		}
+		rewritten, ok := a.Addr(addr)
+		if !ok {
+			return match
+		}
+		if !hasLength {
+			return rewritten.String()
+		}
+		bits, err := strconv.Atoi(length)
+		if err != nil {
+			return match
+		}
+		if network, err := addr.Prefix(bits); err != nil || network.Addr() != addr {
+			return rewritten.String() + "/" + length // an interface address, keep it a host address
+		}
+		p, _ := rewritten.Prefix(bits)
+		return p.String()
+	})
+}
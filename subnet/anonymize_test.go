@@ -0,0 +1,146 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"math/rand"
+	"net/netip"
+	"testing"
+)
+
+// testAnonymizer returns an anonymizer with a fixed key for the mappings, each in from=to notation.
+func testAnonymizer(t *testing.T, specs ...string) *Anonymizer {
+	t.Helper()
+	var mappings []Mapping
+	for _, spec := range specs {
+		m, err := ParseMapping(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mappings = append(mappings, m)
+	}
+	return NewAnonymizer([]byte("test key"), mappings)
+}
+
+// randomAddr returns a random address inside p.
+func randomAddr(r *rand.Rand, p netip.Prefix) netip.Addr {
+	b := p.Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		if r.Intn(2) == 1 {
+			b[i/8] |= 1 << uint(7-i%8)
+		}
+	}
+	a, _ := netip.AddrFromSlice(b)
+	return a
+}
+
+func TestAnonymizerPrefixPreserving(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping string
+	}{
+		{"same size", "10.0.0.0/16=198.51.0.0/16"},
+		{"larger replacement", "192.168.1.0/24=198.18.0.0/15"},
+		{"ipv6", "2001:db8:1234::/48=3fff::/20"},
+	}
+	r := rand.New(rand.NewSource(6))
+	for _, tt := range tests {
+		a := testAnonymizer(t, tt.mapping)
+		m := a.mappings[0]
+		for i := 0; i < 200; i++ {
+			x, y := randomAddr(r, m.From), randomAddr(r, m.From)
+			if i%2 == 0 {
+				// share a random number of host bits too, so long common prefixes are covered
+				p, _ := x.Prefix(m.From.Bits() + r.Intn(x.BitLen()-m.From.Bits()+1))
+				y = randomAddr(r, p)
+			}
+			rx, okx := a.Addr(x)
+			ry, oky := a.Addr(y)
+			if !okx || !oky || !m.To.Contains(rx) || !m.To.Contains(ry) {
+				t.Fatalf("%s: Addr(%v), Addr(%v) = %v, %t, %v, %t, want addresses in %v", tt.name, x, y, rx, okx, ry, oky, m.To)
+			}
+			if got, want := CommonPrefixLen(rx, ry), CommonPrefixLen(x, y); got != want {
+				t.Fatalf("%s: %v and %v share %d bits, but are rewritten to %v and %v sharing %d", tt.name, x, y, want, rx, ry, got)
+			}
+		}
+		// the same key gives the same mapping
+		want, _ := a.Addr(m.From.Addr())
+		if got, _ := testAnonymizer(t, tt.mapping).Addr(m.From.Addr()); got != want {
+			t.Errorf("%s: Addr(%v) with the same key = %v, want %v", tt.name, m.From.Addr(), got, want)
+		}
+	}
+}
+
+func TestAnonymizerSmallerReplacement(t *testing.T) {
+	tests := []struct {
+		name     string
+		mapping  string
+		source   string
+		distinct int
+	}{
+		// each /24 block is a permutation of the whole replacement, so two blocks collide on every address
+		{"two blocks", "10.0.0.0/8=198.51.100.0/24", "10.0.0.0/23", 256},
+		{"one block", "10.0.0.0/8=198.51.100.0/24", "10.7.3.0/24", 256},
+		{"ipv6", "2001:db8::/32=3fff::/124", "2001:db8::/123", 16},
+	}
+	for _, tt := range tests {
+		a := testAnonymizer(t, tt.mapping)
+		to := a.mappings[0].To
+		source := netip.MustParsePrefix(tt.source)
+		seen := map[netip.Addr]bool{}
+		for addr := source.Addr(); source.Contains(addr); addr = addr.Next() {
+			got, ok := a.Addr(addr)
+			if !ok || !to.Contains(got) {
+				t.Fatalf("%s: Addr(%v) = %v, %t, want an address in %v", tt.name, addr, got, ok, to)
+			}
+			seen[got] = true
+		}
+		if len(seen) != tt.distinct {
+			t.Errorf("%s: %v was rewritten to %d distinct addresses, want %d", tt.name, source, len(seen), tt.distinct)
+		}
+	}
+}
+
+func TestAnonymizerText(t *testing.T) {
+	a := testAnonymizer(t, "10.0.0.0/8=198.51.100.0/24", "2001:db8:1234::/48=3fff::/20")
+	rewrite := func(s string) string {
+		addr, _ := a.Addr(netip.MustParseAddr(s))
+		return addr.String()
+	}
+	tests := []struct {
+		name, in, want string
+	}{
+		{"time", "up 12:34:56 since 2023-01-02", "up 12:34:56 since 2023-01-02"},
+		{"mac", "hw 00:1a:2b:3c:4d:5e dev eth0", "hw 00:1a:2b:3c:4d:5e dev eth0"},
+		{"version", "version 1.2.3.4.5", "version 1.2.3.4.5"},
+		{"unmapped", "dns 192.0.2.53 and fe80::1", "dns 192.0.2.53 and fe80::1"},
+		{"ipv4 host", "peer 10.1.2.3 up", "peer " + rewrite("10.1.2.3") + " up"},
+		{"ipv6 host", "peer 2001:db8:1234::5", "peer " + rewrite("2001:db8:1234::5")},
+		{"interface address", "ip 10.1.2.3/16", "ip " + rewrite("10.1.2.3") + "/16"},
+	}
+	for _, tt := range tests {
+		if got := a.Text(tt.in); got != tt.want {
+			t.Errorf("%s: Text(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+
+	// a network address followed by its length stays a network address of that length
+	for _, in := range []string{"10.1.0.0/16", "10.1.2.0/24", "2001:db8:1234:5600::/56"} {
+		got, err := netip.ParsePrefix(a.Text(in))
+		if err != nil || got != got.Masked() || got.Bits() != netip.MustParsePrefix(in).Bits() {
+			t.Errorf("Text(%q) = %v, %v, want a network address with the same length", in, got, err)
+		}
+	}
+}
+
+func TestAnonymizerFlipsBounded(t *testing.T) {
+	a := testAnonymizer(t, "2001:db8::/32=3fff::/20")
+	r := rand.New(rand.NewSource(7))
+	for i := 0; i < 2000; i++ {
+		a.Addr(randomAddr(r, a.mappings[0].From))
+		if len(a.flips) > maxCachedFlips {
+			t.Fatalf("after %d addresses the anonymizer holds %d flips, want at most %d", i+1, len(a.flips), maxCachedFlips)
+		}
+	}
+}
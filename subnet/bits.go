@@ -0,0 +1,101 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// masks4 and masks6 hold the subnet mask for every prefix length of each family, so masks aren't rebuilt bit by bit
+// for every subnet of a large split.
+var masks4, masks6 = maskTable(32), maskTable(128)
+
+// maskTable returns the subnet masks for prefix lengths 0 through bitLen.
+func maskTable(bitLen int) []netip.Addr {
+	table := make([]netip.Addr, bitLen+1)
+	for bits := range table {
+		table[bits] = buildMask(bits, bitLen)
+	}
+	return table
+}
+
+// buildMask returns the subnet mask with the first maskBits of bitLen bits set.
+func buildMask(maskBits, bitLen int) netip.Addr {
+	mask, _ := hostMask(0, bitLen).xor(hostMask(maskBits, bitLen)).addr(bitLen)
+	return mask
+}
+
+// MaskFromBits returns the subnet mask with the first maskBits of bitLen bits set, e.g. 255.255.240.0 for 20 and 32.
+func MaskFromBits(maskBits, bitLen int) netip.Addr {
+	maskBits = max(0, min(maskBits, bitLen))
+	switch bitLen {
+	case 32:
+		return masks4[maskBits]
+	case 128:
+		return masks6[maskBits]
+	default:
+		return netip.Addr{}
+	}
+}
+
+// ApplyMask returns addr with every bit that is zero in mask cleared, the way a router matches an address against a
+// netmask. Masks need not be contiguous.
+// returns an error if addr and mask are of different address families.
+func ApplyMask(addr, mask netip.Addr) (netip.Addr, error) {
+	if addr.BitLen() != mask.BitLen() {
+		return netip.Addr{}, fmt.Errorf("address %v and mask %v are of different address families", addr, mask)
+	}
+	masked, _ := u128(addr).and(u128(mask)).addr(addr.BitLen())
+	return masked, nil
+}
+
+// CommonPrefixLen returns the number of leading bits a and b have in common, or 0 if they are of different address
+// families.
+func CommonPrefixLen(a, b netip.Addr) int {
+	if a.BitLen() != b.BitLen() {
+		return 0
+	}
+	// IPv4 addresses occupy the low 32 bits, so discount the high bits they always share
+	return u128(a).xor(u128(b)).leadingZeros() - (128 - a.BitLen())
+}
+
+// MaskFromWildcard returns the subnet mask matching an ACL wildcard mask by inverting every bit, e.g. 255.255.255.0 for
+// 0.0.0.255. Wildcards need not be contiguous. Inverting every bit is its own inverse, so the same calculation also
+// converts a subnet mask to its wildcard.
+func MaskFromWildcard(wildcard netip.Addr) netip.Addr {
+	mask, _ := u128(wildcard).xor(hostMask(0, wildcard.BitLen())).addr(wildcard.BitLen())
+	return mask
+}
+
+// CalculateWildcardMask returns the ACL wildcard mask matching a subnet mask; see MaskFromWildcard.
+func CalculateWildcardMask(mask netip.Addr) netip.Addr {
+	return MaskFromWildcard(mask)
+}
+
+// MaskLen returns the number of leading one bits in a subnet mask.
+// returns false if the mask isn't contiguous, such as 255.0.255.0.
+func MaskLen(mask netip.Addr) (int, bool) {
+	ones := CommonPrefixLen(mask, MaskFromBits(mask.BitLen(), mask.BitLen()))
+	return ones, mask == MaskFromBits(ones, mask.BitLen())
+}
+
+// InferPrefix returns the smallest prefix containing every one of addrs, such as 10.0.4.0/22 for 10.0.4.1 and
+// 10.0.7.254.
+// returns an error if addrs is empty or mixes address families.
+func InferPrefix(addrs []netip.Addr) (netip.Prefix, error) {
+	if len(addrs) == 0 {
+		return netip.Prefix{}, fmt.Errorf("no addresses to infer a prefix from")
+	}
+	first := addrs[0].Unmap()
+	bits := first.BitLen()
+	for _, a := range addrs[1:] {
+		a = a.Unmap()
+		if a.BitLen() != first.BitLen() {
+			return netip.Prefix{}, fmt.Errorf("addresses %v and %v are of different address families", first, a)
+		}
+		bits = min(bits, CommonPrefixLen(first, a))
+	}
+	return netip.PrefixFrom(first, bits).Masked(), nil
+}
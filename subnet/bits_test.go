@@ -0,0 +1,151 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestMaskFromBits(t *testing.T) {
+	tests := []struct {
+		bits, bitLen int
+		want         string
+	}{
+		{0, 32, "0.0.0.0"},
+		{1, 32, "128.0.0.0"},
+		{20, 32, "255.255.240.0"},
+		{31, 32, "255.255.255.254"},
+		{32, 32, "255.255.255.255"},
+		{33, 32, "255.255.255.255"},
+		{-1, 32, "0.0.0.0"},
+		{0, 128, "::"},
+		{64, 128, "ffff:ffff:ffff:ffff::"},
+		{127, 128, "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe"},
+		{128, 128, "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+	}
+	for _, tt := range tests {
+		if got := MaskFromBits(tt.bits, tt.bitLen); got != netip.MustParseAddr(tt.want) {
+			t.Errorf("MaskFromBits(%d, %d) = %v, want %s", tt.bits, tt.bitLen, got, tt.want)
+		}
+	}
+	if got := MaskFromBits(8, 64); got.IsValid() {
+		t.Errorf("MaskFromBits(8, 64) = %v, want an invalid address", got)
+	}
+}
+
+func TestApplyMask(t *testing.T) {
+	tests := []struct {
+		addr, mask, want string
+		wantErr          bool
+	}{
+		{"10.1.2.3", "0.0.0.0", "0.0.0.0", false},
+		{"10.1.2.3", "255.255.255.0", "10.1.2.0", false},
+		{"10.1.2.3", "255.255.255.255", "10.1.2.3", false},
+		{"10.1.2.3", "255.0.255.0", "10.0.2.0", false},
+		{"2001:db8::1", "::", "::", false},
+		{"2001:db8:1:2::1", "ffff:ffff:ffff::", "2001:db8:1::", false},
+		{"2001:db8::1", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "2001:db8::1", false},
+		{"10.1.2.3", "ffff::", "", true},
+		{"2001:db8::1", "255.255.255.0", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ApplyMask(netip.MustParseAddr(tt.addr), netip.MustParseAddr(tt.mask))
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ApplyMask(%s, %s) = %v, want an error", tt.addr, tt.mask, got)
+			}
+			continue
+		}
+		if err != nil || got != netip.MustParseAddr(tt.want) {
+			t.Errorf("ApplyMask(%s, %s) = %v, %v, want %s", tt.addr, tt.mask, got, err, tt.want)
+		}
+	}
+}
+
+func TestCommonPrefixLen(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"10.0.0.0", "10.0.0.0", 32},
+		{"10.0.0.0", "10.0.0.1", 31},
+		{"10.0.4.1", "10.0.7.254", 22},
+		{"0.0.0.0", "128.0.0.0", 0},
+		{"0.0.0.0", "255.255.255.255", 0},
+		{"2001:db8::", "2001:db8::", 128},
+		{"2001:db8::", "2001:db8::1", 127},
+		{"2001:db8::", "2001:db8:8000::", 32},
+		{"::", "8000::", 0},
+		{"10.0.0.0", "::ffff:10.0.0.0", 0},
+		{"10.0.0.0", "2001:db8::", 0},
+	}
+	for _, tt := range tests {
+		if got := CommonPrefixLen(netip.MustParseAddr(tt.a), netip.MustParseAddr(tt.b)); got != tt.want {
+			t.Errorf("CommonPrefixLen(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMaskFromWildcard(t *testing.T) {
+	tests := []struct {
+		wildcard, want string
+	}{
+		{"0.0.0.0", "255.255.255.255"},
+		{"0.0.0.255", "255.255.255.0"},
+		{"0.0.15.255", "255.255.240.0"},
+		{"255.255.255.255", "0.0.0.0"},
+		{"0.255.0.255", "255.0.255.0"},
+		{"0.0.0.254", "255.255.255.1"},
+		{"::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+		{"::ffff:ffff:ffff:ffff", "ffff:ffff:ffff:ffff::"},
+		{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "::"},
+	}
+	for _, tt := range tests {
+		if got := MaskFromWildcard(netip.MustParseAddr(tt.wildcard)); got != netip.MustParseAddr(tt.want) {
+			t.Errorf("MaskFromWildcard(%s) = %v, want %s", tt.wildcard, got, tt.want)
+		}
+	}
+}
+
+func TestMaskFromWildcardRoundTrip(t *testing.T) {
+	for _, bitLen := range []int{32, 128} {
+		for bits := 0; bits <= bitLen; bits++ {
+			mask := MaskFromBits(bits, bitLen)
+			wildcard := CalculateWildcardMask(mask)
+			if got := MaskFromWildcard(wildcard); got != mask {
+				t.Errorf("MaskFromWildcard(%v) = %v, want %v", wildcard, got, mask)
+			}
+			if got, ok := MaskLen(MaskFromWildcard(wildcard)); !ok || got != bits {
+				t.Errorf("MaskLen of the mask for wildcard %v = %d, %t, want %d, true", wildcard, got, ok, bits)
+			}
+		}
+	}
+}
+
+func TestMaskLen(t *testing.T) {
+	tests := []struct {
+		mask string
+		want int
+		ok   bool
+	}{
+		{"0.0.0.0", 0, true},
+		{"128.0.0.0", 1, true},
+		{"255.255.240.0", 20, true},
+		{"255.255.255.255", 32, true},
+		{"255.0.255.0", 8, false},
+		{"0.0.0.255", 0, false},
+		{"255.255.255.1", 24, false},
+		{"::", 0, true},
+		{"ffff:ffff:ffff:ffff::", 64, true},
+		{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", 128, true},
+		{"ffff::ffff", 16, false},
+	}
+	for _, tt := range tests {
+		got, ok := MaskLen(netip.MustParseAddr(tt.mask))
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("MaskLen(%s) = %d, %t, want %d, %t", tt.mask, got, ok, tt.want, tt.ok)
+		}
+	}
+}
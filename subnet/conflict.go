@@ -0,0 +1,115 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// Sides of a collision report.
+const (
+	SideA      = "a"
+	SideB      = "b"
+	SideEither = "either" // both plans use the same prefix
+)
+
+// Conflict is a pair of overlapping allocations from two plans.
+type Conflict struct {
+	A      Allocation   `json:"a"`
+	B      Allocation   `json:"b"`
+	Shared netip.Prefix `json:"shared"` // the smaller of the two prefixes, which lies inside the other
+}
+
+// NATCandidate is an allocation whose addresses would have to be translated for two plans to be joined.
+type NATCandidate struct {
+	Prefix netip.Prefix `json:"prefix"`
+	Side   string       `json:"side"`
+	Name   string       `json:"name,omitempty"` // name of the allocation, or of both for either side
+}
+
+// Collisions lists where two address plans use the same addresses.
+type Collisions struct {
+	Supernets     []Overlap      `json:"supernets,omitempty"` // overlapping supernets, Outer from plan a if equal
+	Conflicts     []Conflict     `json:"conflicts,omitempty"`
+	NATCandidates []NATCandidate `json:"natCandidates,omitempty"`
+}
+
+// Empty reports whether the plans don't share any addresses.
+func (c Collisions) Empty() bool {
+	return len(c.Supernets)+len(c.Conflicts) == 0
+}
+
+// sharedPrefix returns the smaller of two overlapping prefixes, and the side it belongs to.
+func sharedPrefix(a, b netip.Prefix) (netip.Prefix, string) {
+	switch {
+	case a.Bits() > b.Bits():
+		return a, SideA
+	case b.Bits() > a.Bits():
+		return b, SideB
+	}
+	return a, SideEither
+}
+
+// FindCollisions returns the supernets and allocations of plans a and b that overlap. Containers are skipped, as the
+// allocations inside them are compared instead. The smaller prefix of every conflicting pair is suggested as a NAT
+// candidate, as translating it renumbers the fewest addresses, unless a larger candidate on the same side covers it.
+func FindCollisions(a, b Plan) Collisions {
+	var c Collisions
+	for _, sa := range a.Supernets {
+		for _, sb := range b.Supernets {
+			if sa.Overlaps(sb) {
+				inner, side := sharedPrefix(sa, sb)
+				outer := sa
+				if side == SideA {
+					outer = sb
+				}
+				c.Supernets = append(c.Supernets, Overlap{Outer: outer, Inner: inner})
+			}
+		}
+	}
+
+	candidates := map[NATCandidate]bool{}
+	for _, x := range a.Allocations {
+		if statusOf(x) == StatusContainer {
+			continue
+		}
+		for _, y := range b.Allocations {
+			if statusOf(y) == StatusContainer || !x.CIDR.Overlaps(y.CIDR) {
+				continue
+			}
+			shared, side := sharedPrefix(x.CIDR, y.CIDR)
+			c.Conflicts = append(c.Conflicts, Conflict{A: x, B: y, Shared: shared})
+			name := x.Name
+			switch {
+			case side == SideB:
+				name = y.Name
+			case side == SideEither && y.Name != x.Name:
+				name = strings.Trim(x.Name+" / "+y.Name, " /")
+			}
+			candidates[NATCandidate{Prefix: shared, Side: side, Name: name}] = true
+		}
+	}
+
+	for n := range candidates {
+		covered := false
+		for other := range candidates {
+			if other != n && other.Side == n.Side && other.Prefix.Bits() < n.Prefix.Bits() && other.Prefix.Contains(n.Prefix.Addr()) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			c.NATCandidates = append(c.NATCandidates, n)
+		}
+	}
+	sort.Slice(c.NATCandidates, func(i, j int) bool {
+		if c.NATCandidates[i].Prefix.Addr() != c.NATCandidates[j].Prefix.Addr() {
+			return c.NATCandidates[i].Prefix.Addr().Less(c.NATCandidates[j].Prefix.Addr())
+		}
+		return c.NATCandidates[i].Prefix.Bits() < c.NATCandidates[j].Prefix.Bits()
+	})
+	return c
+}
@@ -0,0 +1,81 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"sort"
+)
+
+// Reservation is the number of addresses reserved at the start and end of every subnet, counting the network and
+// broadcast addresses.
+type Reservation struct {
+	First int `json:"first"`
+	Last  int `json:"last"`
+}
+
+// CloudReservations are the addresses each cloud provider reserves in every subnet: the network address, the router,
+// DNS, and, for AWS and Azure, an address held for future use.
+var CloudReservations = map[string]Reservation{
+	"aws":   {First: 4, Last: 1},
+	"azure": {First: 4, Last: 1},
+	"gcp":   {First: 2, Last: 2},
+}
+
+// CloudProviders returns the names of the providers in CloudReservations in sorted order.
+func CloudProviders() []string {
+	names := make([]string, 0, len(CloudReservations))
+	for name := range CloudReservations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GatewayConventions lists the accepted gateway conventions: the first or last usable address of each subnet.
+var GatewayConventions = []string{"first", "last"}
+
+// Reserve removes r's addresses from the usable host range of the network and each of its subnets. Networks too small
+// to hold the reserved addresses are left with no usable hosts.
+func (n *Network) Reserve(r Reservation) {
+	size := AddressCount(n.CIDR)
+	hosts := new(big.Int).Sub(size, big.NewInt(int64(r.First+r.Last)))
+	if hosts.Sign() > 0 {
+		first, _ := AddToAddr(n.NetworkAddr, big.NewInt(int64(r.First)))
+		last, _ := AddToAddr(n.NetworkAddr, new(big.Int).Sub(size, big.NewInt(int64(r.Last+1))))
+		n.FirstHostIP, n.LastHostIP, n.MaxHosts = first, last, hosts
+	} else {
+		n.MaxHosts = new(big.Int)
+	}
+	for i := range n.Subnets {
+		n.Subnets[i].Reserve(r)
+	}
+}
+
+// SetGateway records the gateway address of the network and each of its subnets, following the convention: the first
+// or last address of the standard host range. Networks without room for a gateway, /31s and smaller, are skipped.
+// returns an error if the convention is unknown.
+func (n *Network) SetGateway(convention string) error {
+	var gw netip.Addr
+	switch convention {
+	case "first":
+		gw = n.NetworkAddr.Next()
+	case "last":
+		gw = n.BroadcastAddr.Prev()
+	default:
+		return fmt.Errorf("unknown gateway convention %q, expected first or last", convention)
+	}
+	n.Gateway = nil
+	if n.MaskSize-n.MaskBits > 1 {
+		n.Gateway = &gw
+	}
+	for i := range n.Subnets {
+		if err := n.Subnets[i].SetGateway(convention); err != nil {
+			return err
+		}
+	}
+	return nil
+}
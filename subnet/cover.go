@@ -0,0 +1,117 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"math/big"
+	"net/netip"
+)
+
+// Size returns the number of addresses in the range.
+func (r Range) Size() *big.Int {
+	size := new(big.Int).Sub(addrInt(r.To), addrInt(r.From))
+	return size.Add(size, big.NewInt(1))
+}
+
+// contains reports whether every address in prefix p lies inside the range.
+func (r Range) contains(p netip.Prefix) bool {
+	return !p.Addr().Less(r.From) && !r.To.Less(lastAddr(p))
+}
+
+// overlaps reports whether prefix p shares any addresses with the range.
+func (r Range) overlaps(p netip.Prefix) bool {
+	return !r.To.Less(p.Addr()) && !lastAddr(p).Less(r.From)
+}
+
+// halves returns the two prefixes one bit longer than p.
+func halves(p netip.Prefix) (netip.Prefix, netip.Prefix) {
+	lower := netip.PrefixFrom(p.Addr(), p.Bits()+1)
+	return lower, netip.PrefixFrom(lastAddr(lower).Next(), p.Bits()+1)
+}
+
+// rootPrefix returns the prefix containing every address of the range's address family.
+func (r Range) rootPrefix() netip.Prefix {
+	root, _ := r.From.Prefix(0)
+	return root
+}
+
+// Enclosing returns the smallest prefix containing every address of the range.
+func (r Range) Enclosing() netip.Prefix {
+	p, _ := commonPrefix(netip.PrefixFrom(r.From, r.From.BitLen()), netip.PrefixFrom(r.To, r.To.BitLen()))
+	return p
+}
+
+// Cover returns the fewest prefixes that exactly cover the range, in address order.
+func Cover(r Range) []netip.Prefix {
+	var cover func(p netip.Prefix) []netip.Prefix
+	cover = func(p netip.Prefix) []netip.Prefix {
+		switch {
+		case !r.overlaps(p):
+			return nil
+		case r.contains(p):
+			return []netip.Prefix{p}
+		}
+		lower, upper := halves(p)
+		return append(cover(lower), cover(upper)...)
+	}
+	return cover(r.rootPrefix())
+}
+
+// coverChoice is the best way found to cover the part of the range inside a prefix with a limited number of prefixes.
+type coverChoice struct {
+	ok       bool
+	size     *big.Int // addresses covered, including those outside the range
+	prefixes []netip.Prefix
+}
+
+// CoverBudget returns at most max prefixes covering the range with the fewest addresses outside it, in address order.
+// returns the prefixes and the number of addresses they cover outside the range. If the exact cover fits the budget,
+// it is returned with no overshoot.
+func CoverBudget(r Range, max int) ([]netip.Prefix, *big.Int) {
+	exact := Cover(r)
+	if max <= 0 || len(exact) <= max {
+		return exact, new(big.Int)
+	}
+
+	type key struct {
+		p netip.Prefix
+		k int
+	}
+	memo := map[key]coverChoice{}
+
+	// best covers the range addresses in p with at most k prefixes. Only prefixes on the range's two boundaries are
+	// partially covered, so the search stays small.
+	var best func(p netip.Prefix, k int) coverChoice
+	best = func(p netip.Prefix, k int) coverChoice {
+		switch {
+		case !r.overlaps(p):
+			return coverChoice{ok: true, size: new(big.Int)}
+		case k == 0:
+			return coverChoice{}
+		case r.contains(p):
+			return coverChoice{ok: true, size: AddressCount(p), prefixes: []netip.Prefix{p}}
+		}
+		if c, ok := memo[key{p, k}]; ok {
+			return c
+		}
+
+		choice := coverChoice{ok: true, size: AddressCount(p), prefixes: []netip.Prefix{p}}
+		lower, upper := halves(p)
+		for k1 := 0; k1 <= k; k1++ {
+			l, u := best(lower, k1), best(upper, k-k1)
+			if !l.ok || !u.ok {
+				continue
+			}
+			size := new(big.Int).Add(l.size, u.size)
+			if size.Cmp(choice.size) < 0 || size.Cmp(choice.size) == 0 && len(l.prefixes)+len(u.prefixes) < len(choice.prefixes) {
+				choice = coverChoice{ok: true, size: size, prefixes: append(append([]netip.Prefix{}, l.prefixes...), u.prefixes...)}
+			}
+		}
+		memo[key{p, k}] = choice
+		return choice
+	}
+
+	c := best(r.rootPrefix(), max)
+	return c.prefixes, c.size.Sub(c.size, r.Size())
+}
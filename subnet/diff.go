@@ -0,0 +1,84 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"net/netip"
+	"reflect"
+)
+
+// AllocationChange is an allocation as it was before and after a plan was changed.
+type AllocationChange struct {
+	Before Allocation `json:"before"`
+	After  Allocation `json:"after"`
+}
+
+// PlanDiff lists the differences between two versions of a plan.
+type PlanDiff struct {
+	AddedSupernets   []netip.Prefix     `json:"addedSupernets,omitempty"`
+	RemovedSupernets []netip.Prefix     `json:"removedSupernets,omitempty"`
+	Added            []Allocation       `json:"added,omitempty"`
+	Removed          []Allocation       `json:"removed,omitempty"`
+	Changed          []AllocationChange `json:"changed,omitempty"`
+}
+
+// Empty reports whether the two versions of the plan were the same.
+func (d PlanDiff) Empty() bool {
+	return len(d.AddedSupernets)+len(d.RemovedSupernets)+len(d.Added)+len(d.Removed)+len(d.Changed) == 0
+}
+
+// allocationKey identifies an allocation across versions of a plan by its ID, or by its prefix if it doesn't have one
+// yet.
+func allocationKey(a Allocation) string {
+	if a.ID != "" {
+		return a.ID
+	}
+	return a.CIDR.String()
+}
+
+// Diff returns the changes made to old to produce p. Allocations are matched by ID, or by prefix when the old version
+// hadn't been given IDs yet.
+func (p Plan) Diff(old Plan) PlanDiff {
+	var d PlanDiff
+	for _, s := range p.Supernets {
+		if !containsPrefix(old.Supernets, s) {
+			d.AddedSupernets = append(d.AddedSupernets, s)
+		}
+	}
+	for _, s := range old.Supernets {
+		if !containsPrefix(p.Supernets, s) {
+			d.RemovedSupernets = append(d.RemovedSupernets, s)
+		}
+	}
+
+	before := map[string]Allocation{}
+	for _, a := range old.Allocations {
+		before[allocationKey(a)] = a
+	}
+	for _, a := range p.Allocations {
+		key := allocationKey(a)
+		b, ok := before[key]
+		if !ok {
+			b, ok = before[a.CIDR.String()]
+			key = a.CIDR.String()
+		}
+		if !ok {
+			d.Added = append(d.Added, a)
+			continue
+		}
+		if b.ID == "" {
+			b.ID = a.ID // only gaining an ID isn't a change worth reporting
+		}
+		if !reflect.DeepEqual(a, b) {
+			d.Changed = append(d.Changed, AllocationChange{Before: b, After: a})
+		}
+		delete(before, key)
+	}
+	for _, a := range old.Allocations {
+		if _, ok := before[allocationKey(a)]; ok {
+			d.Removed = append(d.Removed, a)
+		}
+	}
+	return d
+}
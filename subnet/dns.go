@@ -0,0 +1,71 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Roles of a subnet's infrastructure addresses.
+const (
+	RoleGateway  = "gw"
+	RoleReserved = "infra"
+)
+
+// InfraAddr is an infrastructure address of a subnet: its gateway, or one of the addresses reserved beside it.
+type InfraAddr struct {
+	Addr  netip.Addr `json:"addr"`
+	Role  string     `json:"role"`
+	Index int        `json:"index,omitempty"` // position among the reserved addresses, counting from 1
+}
+
+// InfraAddrs returns the gateway of prefix, following the gateway convention, and the reserved addresses after it:
+// counting up from a first-address gateway, or down from a last-address gateway. Networks without room for a gateway,
+// /31s and smaller, have none, and reserved addresses stop at the end of the host range.
+// returns an error if the convention is unknown.
+func InfraAddrs(prefix netip.Prefix, convention string, reserved int) ([]InfraAddr, error) {
+	n := NewNetworkFromPrefix(prefix)
+	if err := n.SetGateway(convention); err != nil {
+		return nil, err
+	}
+	if n.Gateway == nil {
+		return nil, nil
+	}
+	addrs := []InfraAddr{{Addr: *n.Gateway, Role: RoleGateway}}
+	a := *n.Gateway
+	for i := 1; i <= reserved; i++ {
+		if convention == "first" {
+			a = a.Next()
+		} else {
+			a = a.Prev()
+		}
+		if a.Less(n.FirstHostIP) || n.LastHostIP.Less(a) {
+			break
+		}
+		addrs = append(addrs, InfraAddr{Addr: a, Role: RoleReserved, Index: i})
+	}
+	return addrs, nil
+}
+
+// DNSLabel returns name as a DNS label: lower case, with every character other than a letter, digit, or hyphen
+// replaced by a hyphen, and no leading or trailing hyphens.
+func DNSLabel(name string) string {
+	label := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, name)
+	return strings.Trim(label, "-")
+}
+
+// PrefixLabel returns a DNS label for an unnamed prefix, such as 10-0-4-0-24 or 2001-db8---64.
+func PrefixLabel(p netip.Prefix) string {
+	return DNSLabel(fmt.Sprintf("%s-%d", strings.NewReplacer(".", "-", ":", "-").Replace(p.Addr().String()), p.Bits()))
+}
@@ -0,0 +1,95 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/netip"
+	"regexp"
+)
+
+// DocumentationPrefixes lists the prefixes reserved for use in documentation and examples.
+var DocumentationPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("3fff::/20"),
+}
+
+// documentationMACPrefix is the unicast MAC address block reserved for documentation by RFC 7042.
+var documentationMACPrefix = net.HardwareAddr{0x00, 0x00, 0x5e, 0x00, 0x53}
+
+// IsDocumentation reports whether every address in p is reserved for documentation.
+func IsDocumentation(p netip.Prefix) bool {
+	for _, d := range DocumentationPrefixes {
+		if d.Bits() <= p.Bits() && d.Contains(p.Addr()) {
+			return true
+		}
+	}
+	return false
+}
+
+// DocumentationNetworks returns the first count prefixes of size bits carved from the documentation prefixes of the
+// requested address family, in order.
+// returns an error if the documentation prefixes don't hold that many prefixes of that size.
+func DocumentationNetworks(ipv6 bool, bits, count int) ([]netip.Prefix, error) {
+	var found []netip.Prefix
+	if count <= 0 {
+		return found, nil
+	}
+	for _, d := range DocumentationPrefixes {
+		if d.Addr().Is6() != ipv6 || bits < d.Bits() || bits > d.Addr().BitLen() {
+			continue
+		}
+		n := NewNetworkFromPrefix(d)
+		if bits == d.Bits() {
+			found = append(found, d)
+		} else {
+			subnets, err := n.SubnetsOf(bits, new(big.Int), big.NewInt(1), count-len(found))
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range subnets {
+				found = append(found, s.CIDR)
+			}
+		}
+		if len(found) >= count {
+			return found[:count], nil
+		}
+	}
+	return nil, fmt.Errorf("the documentation prefixes only hold %d /%d prefixes", len(found), bits)
+}
+
+// DocumentationMACs returns the first count of the 256 MAC addresses reserved for documentation.
+// returns an error if more than 256 are requested.
+func DocumentationMACs(count int) ([]net.HardwareAddr, error) {
+	if count > 256 {
+		return nil, fmt.Errorf("only 256 documentation MAC addresses exist, %d requested", count)
+	}
+	macs := make([]net.HardwareAddr, count)
+	for i := range macs {
+		macs[i] = append(append(net.HardwareAddr{}, documentationMACPrefix...), byte(i))
+	}
+	return macs, nil
+}
+
+// addrPattern matches candidate IPv4 and IPv6 addresses in text, with an optional prefix length.
+var addrPattern = regexp.MustCompile(`(?i)\b\d{1,3}(?:\.\d{1,3}){3}\b(?:/\d{1,2}\b)?|[0-9a-f]{0,4}(?::[0-9a-f]{0,4}){2,7}(?:/\d{1,3}\b)?`)
+
+// FindPrefixes returns the addresses and prefixes found in text s. Addresses without a prefix length are returned as
+// single address prefixes, and prefixes keep any host bits set.
+func FindPrefixes(s string) []netip.Prefix {
+	var found []netip.Prefix
+	for _, match := range addrPattern.FindAllString(s, -1) {
+		if p, err := netip.ParsePrefix(match); err == nil {
+			found = append(found, p)
+		} else if a, err := netip.ParseAddr(match); err == nil {
+			found = append(found, netip.PrefixFrom(a, a.BitLen()))
+		}
+	}
+	return found
+}
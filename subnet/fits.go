@@ -0,0 +1,37 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"math/big"
+	"net/netip"
+)
+
+// Fit is the capacity of a network when split into subnets of one prefix length.
+type Fit struct {
+	Bits       int        `json:"bits"`
+	SubnetMask netip.Addr `json:"subnetMask"`
+	Subnets    *big.Int   `json:"subnets"`
+	Hosts      *big.Int   `json:"hostsPerSubnet"`
+	TotalHosts *big.Int   `json:"totalHosts"`
+}
+
+// Fits returns the capacity of the network for every prefix length from its own through maxBits: how many subnets of
+// that length fit, and how many usable hosts each of them and all of them together hold.
+func (n Network) Fits(maxBits int) []Fit {
+	maxBits = min(maxBits, n.MaskSize)
+	fits := make([]Fit, 0, max(maxBits-n.MaskBits+1, 0))
+	for bits := n.MaskBits; bits <= maxBits; bits++ {
+		s := NewNetworkFromPrefix(netip.PrefixFrom(n.NetworkAddr, bits))
+		count := n.SubnetCount(bits)
+		fits = append(fits, Fit{
+			Bits:       bits,
+			SubnetMask: s.SubnetMask,
+			Subnets:    count,
+			Hosts:      s.MaxHosts,
+			TotalHosts: new(big.Int).Mul(count, s.MaxHosts),
+		})
+	}
+	return fits
+}
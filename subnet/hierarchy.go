@@ -0,0 +1,125 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+)
+
+// Block is one level of a hierarchical plan, such as a region, site, or VLAN. Blocks holding other blocks become
+// containers, the rest become allocations.
+type Block struct {
+	Name   string       `json:"name"`
+	CIDR   netip.Prefix `json:"cidr,omitempty"`  // fixed prefix, otherwise the next free prefix of Size is used
+	Size   int          `json:"size,omitempty"`  // prefix length
+	Count  int          `json:"count,omitempty"` // number of copies, named <name>1 to <name>N
+	Level  string       `json:"level,omitempty"` // labels the block and everything inside it with level=name
+	VLAN   int          `json:"vlan,omitempty"`
+	Blocks []Block      `json:"blocks,omitempty"`
+}
+
+// expand returns the blocks with every block of Count copies replaced by the copies.
+func expand(blocks []Block) []Block {
+	var expanded []Block
+	for _, b := range blocks {
+		if b.Count <= 1 {
+			expanded = append(expanded, b)
+			continue
+		}
+		for i := 1; i <= b.Count; i++ {
+			c := b
+			c.Name, c.Count = fmt.Sprintf("%s%d", b.Name, i), 0
+			expanded = append(expanded, c)
+		}
+	}
+	return expanded
+}
+
+// NewPlanFromHierarchy returns a plan with root's prefix as its supernet and an allocation for every block nested
+// inside it. Allocations are named after the path to their block, such as us-east-nyc1-users, and blocks holding other
+// blocks are allocated as containers. Blocks with a fixed CIDR are placed first, then the rest largest first.
+// returns an error if root has no CIDR, or a block doesn't fit in its parent.
+func NewPlanFromHierarchy(root Block) (Plan, error) {
+	p := Plan{Name: root.Name, Allocations: []Allocation{}}
+	if !root.CIDR.IsValid() {
+		return p, fmt.Errorf("the top level block needs a cidr")
+	}
+	p.Supernets = []netip.Prefix{root.CIDR.Masked()}
+	labels := map[string]string{}
+	if root.Level != "" {
+		labels[root.Level] = root.Name
+	}
+	return p, p.allocateBlocks(root.CIDR.Masked(), "", labels, expand(root.Blocks))
+}
+
+// allocateBlocks places the blocks inside parent and allocates them, and everything inside them, with their names
+// prefixed by path and the labels of their ancestors.
+func (p *Plan) allocateBlocks(parent netip.Prefix, path string, labels map[string]string, blocks []Block) error {
+	placed := make([]netip.Prefix, len(blocks))
+	var taken, order []int
+	for i, b := range blocks {
+		switch {
+		case b.CIDR.IsValid():
+			cidr := b.CIDR.Masked()
+			if cidr.Bits() <= parent.Bits() || !parent.Contains(cidr.Addr()) {
+				return fmt.Errorf("%s: %v is not inside %v", b.Name, cidr, parent)
+			}
+			for _, j := range taken {
+				if placed[j].Overlaps(cidr) {
+					return fmt.Errorf("%s: %v overlaps %s %v", b.Name, cidr, blocks[j].Name, placed[j])
+				}
+			}
+			placed[i] = cidr
+			taken = append(taken, i)
+		case b.Size <= parent.Bits() || b.Size > parent.Addr().BitLen():
+			return fmt.Errorf("%s: a /%d doesn't fit in %v", b.Name, b.Size, parent)
+		default:
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return blocks[order[i]].Size < blocks[order[j]].Size })
+	for _, i := range order {
+		used := make([]netip.Prefix, len(taken))
+		for k, j := range taken {
+			used[k] = placed[j]
+		}
+		next, ok := NextFree(parent, blocks[i].Size, used)
+		if !ok {
+			return fmt.Errorf("%s: no free /%d left in %v", blocks[i].Name, blocks[i].Size, parent)
+		}
+		placed[i] = next
+		taken = append(taken, i)
+	}
+
+	for i, b := range blocks {
+		name := b.Name
+		if path != "" {
+			name = path + "-" + b.Name
+		}
+		inherited := make(map[string]string, len(labels)+1)
+		for k, v := range labels {
+			inherited[k] = v
+		}
+		if b.Level != "" {
+			inherited[b.Level] = b.Name
+		}
+		a := Allocation{CIDR: placed[i], Name: name, VLAN: b.VLAN}
+		if len(inherited) > 0 {
+			a.Labels = inherited
+		}
+		children := expand(b.Blocks)
+		if len(children) > 0 {
+			a.Status = StatusContainer
+		}
+		if err := p.Allocate(a); err != nil {
+			return err
+		}
+		if err := p.allocateBlocks(placed[i], name, inherited, children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Actions recorded in a plan's history.
+const (
+	ActionAllocated       = "allocated"
+	ActionFreed           = "freed"
+	ActionChanged         = "changed"
+	ActionSupernetAdded   = "supernet added"
+	ActionSupernetRemoved = "supernet removed"
+)
+
+// Event is an entry in a plan's history: who changed which prefix, how, and when.
+type Event struct {
+	Time   time.Time    `json:"time"`
+	Actor  string       `json:"actor,omitempty"`
+	Action string       `json:"action"`
+	CIDR   netip.Prefix `json:"cidr"`
+	ID     string       `json:"id,omitempty"`   // allocation ID, for following a block across renames
+	Name   string       `json:"name,omitempty"` // allocation name at the time of the event
+	Detail string       `json:"detail,omitempty"`
+}
+
+// changedFields returns a description of the fields that differ between two versions of an allocation, such as
+// "status from reserved to allocated".
+func changedFields(before, after Allocation) string {
+	var changes []string
+	describe := func(field, b, a string) {
+		if b != a {
+			changes = append(changes, field+" from "+quoteEmpty(b)+" to "+quoteEmpty(a))
+		}
+	}
+	describe("cidr", before.CIDR.String(), after.CIDR.String())
+	describe("name", before.Name, after.Name)
+	describe("status", statusOf(before), statusOf(after))
+	describe("zone", before.Zone, after.Zone)
+	describe("note", before.Note, after.Note)
+	describe("vlan", strconv.Itoa(before.VLAN), strconv.Itoa(after.VLAN))
+	describe("pair", before.Pair, after.Pair)
+	keys := map[string]bool{}
+	for k := range before.Labels {
+		keys[k] = true
+	}
+	for k := range after.Labels {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		describe("label "+k, before.Labels[k], after.Labels[k])
+	}
+	return strings.Join(changes, ", ")
+}
+
+// quoteEmpty returns s, or "" in quotes if it is empty, so a cleared field reads clearly.
+func quoteEmpty(s string) string {
+	if s == "" {
+		return `""`
+	}
+	return s
+}
+
+// Events returns the history entries describing the changes in d, made by actor at time t.
+func (d PlanDiff) Events(t time.Time, actor string) []Event {
+	var events []Event
+	for _, s := range d.AddedSupernets {
+		events = append(events, Event{Time: t, Actor: actor, Action: ActionSupernetAdded, CIDR: s})
+	}
+	for _, s := range d.RemovedSupernets {
+		events = append(events, Event{Time: t, Actor: actor, Action: ActionSupernetRemoved, CIDR: s})
+	}
+	for _, a := range d.Added {
+		events = append(events, Event{Time: t, Actor: actor, Action: ActionAllocated, CIDR: a.CIDR, ID: a.ID, Name: a.Name, Detail: statusOf(a)})
+	}
+	for _, a := range d.Removed {
+		events = append(events, Event{Time: t, Actor: actor, Action: ActionFreed, CIDR: a.CIDR, ID: a.ID, Name: a.Name})
+	}
+	for _, c := range d.Changed {
+		events = append(events, Event{Time: t, Actor: actor, Action: ActionChanged, CIDR: c.After.CIDR, ID: c.After.ID, Name: c.After.Name, Detail: changedFields(c.Before, c.After)})
+	}
+	return events
+}
+
+// mergeHistory returns the events of both histories in time order, keeping events present in both, such as those
+// from before a plan was split, once.
+func mergeHistory(a, b []Event) []Event {
+	merged := append([]Event{}, a...)
+next:
+	for _, e := range b {
+		for _, m := range a {
+			if m.Time.Equal(e.Time) && m.Action == e.Action && m.CIDR == e.CIDR && m.ID == e.ID {
+				continue next
+			}
+		}
+		merged = append(merged, e)
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Time.Before(merged[j].Time) })
+	return merged
+}
+
+// RecordMerge records a merged plan being written over old, the plan previously at the output, in p.History. The
+// histories of old and of the merged plans are kept, and events are added by actor only for what they don't already
+// record: supernets and allocations no event mentions, and anything removed or changed since old.
+// returns the differences from old.
+func (p *Plan) RecordMerge(old Plan, t time.Time, actor string) PlanDiff {
+	p.AssignIDs()
+	p.History = mergeHistory(p.History, old.History)
+	diff := p.Diff(old)
+	unrecorded := diff
+	unrecorded.AddedSupernets, unrecorded.Added = nil, nil
+	for _, s := range diff.AddedSupernets {
+		if !recorded(p.History, func(e Event) bool { return e.Action == ActionSupernetAdded && e.CIDR == s }) {
+			unrecorded.AddedSupernets = append(unrecorded.AddedSupernets, s)
+		}
+	}
+	for _, a := range diff.Added {
+		// events written before allocations had IDs are matched by prefix instead
+		if !recorded(p.History, func(e Event) bool { return e.ID == a.ID || e.ID == "" && e.CIDR == a.CIDR }) {
+			unrecorded.Added = append(unrecorded.Added, a)
+		}
+	}
+	p.History = append(p.History, unrecorded.Events(t, actor)...)
+	return diff
+}
+
+// recorded reports whether any event in history matches.
+func recorded(history []Event, match func(Event) bool) bool {
+	for _, e := range history {
+		if match(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// EventsFor returns the events of the plan's history about prefixes overlapping prefix, oldest first.
+func (p Plan) EventsFor(prefix netip.Prefix) []Event {
+	var events []Event
+	for _, e := range p.History {
+		if e.CIDR.Overlaps(prefix) {
+			events = append(events, e)
+		}
+	}
+	return events
+}
@@ -0,0 +1,102 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Lint rule names, as accepted by LintRules.Severities.
+const (
+	RuleDuplicateName = "duplicate-name"
+	RuleNamePattern   = "name-pattern"
+	RuleMissingVLAN   = "missing-vlan"
+	RulePlatformRange = "platform-range"
+)
+
+// LintRuleNames lists the lint rules.
+var LintRuleNames = []string{RuleDuplicateName, RuleNamePattern, RuleMissingVLAN, RulePlatformRange}
+
+// SeverityOff disables a lint rule.
+const SeverityOff = "off"
+
+// DefaultLintSeverities are the severities used for rules not given in LintRules.Severities. Requiring VLAN IDs is
+// opt-in, as many plans don't track them.
+var DefaultLintSeverities = map[string]string{
+	RuleDuplicateName: SeverityWarning,
+	RuleNamePattern:   SeverityWarning,
+	RuleMissingVLAN:   SeverityOff,
+	RulePlatformRange: SeverityWarning,
+}
+
+// LintRules configures the naming and metadata conventions checked by Lint.
+type LintRules struct {
+	// NamePattern, if set, must match the name of every named allocation.
+	NamePattern *regexp.Regexp
+	// Platforms are the platform default ranges allocations must not overlap, or PlatformRanges if nil.
+	Platforms []PlatformRange
+	// Severities maps rule names to the severity of the issues they report, or off. Rules not present use
+	// DefaultLintSeverities.
+	Severities map[string]string
+}
+
+// severity returns the severity of rule, or off if the rule is disabled.
+func (r LintRules) severity(rule string) string {
+	if s, ok := r.Severities[rule]; ok {
+		return s
+	}
+	return DefaultLintSeverities[rule]
+}
+
+// Lint checks the plan's allocations against the conventions in r: names used by more than one allocation, names not
+// matching the name pattern, allocated prefixes without a VLAN ID, and allocated prefixes overlapping a platform's
+// default range.
+// returns an error if a rule or severity in r is unknown.
+func (p Plan) Lint(r LintRules) ([]Issue, error) {
+	for rule, severity := range r.Severities {
+		if _, ok := DefaultLintSeverities[rule]; !ok {
+			return nil, fmt.Errorf("unknown lint rule %q", rule)
+		}
+		switch severity {
+		case SeverityError, SeverityWarning, SeverityInfo, SeverityOff:
+		default:
+			return nil, fmt.Errorf("invalid severity %q for %s, expected error, warning, info, or off", severity, rule)
+		}
+	}
+
+	var issues []Issue
+	report := func(rule string, a Allocation, format string, args ...any) {
+		if s := r.severity(rule); s != SeverityOff {
+			issues = append(issues, Issue{s, a.CIDR, fmt.Sprintf(format, args...) + " [" + rule + "]"})
+		}
+	}
+	platforms := r.Platforms
+	if platforms == nil {
+		platforms = PlatformRanges
+	}
+	firstUse := map[string]Allocation{}
+	for _, a := range p.Allocations {
+		if a.Name != "" {
+			if first, ok := firstUse[a.Name]; ok && (a.Pair == "" || a.Pair != first.ID) {
+				// dual-stack pairs share their name on purpose
+				report(RuleDuplicateName, a, "name %q is already used by %v", a.Name, first.CIDR)
+			} else if !ok {
+				firstUse[a.Name] = a
+			}
+			if r.NamePattern != nil && !r.NamePattern.MatchString(a.Name) {
+				report(RuleNamePattern, a, "name %q doesn't match %s", a.Name, r.NamePattern)
+			}
+		}
+		if a.VLAN == 0 && statusOf(a) == StatusAllocated {
+			report(RuleMissingVLAN, a, "allocation %s has no VLAN ID", a.Name)
+		}
+		if statusOf(a) == StatusAllocated {
+			for _, pr := range PlatformCollisions(a.CIDR, platforms) {
+				report(RulePlatformRange, a, "overlaps the platform default range %v", pr)
+			}
+		}
+	}
+	return issues, nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// ouiCSV is a selection of common vendors from the IEEE MA-L registry, in the registry's oui.csv layout.
+//
+//go:embed oui.csv
+var ouiCSV []byte
+
+// OUIDatabase maps organizationally unique identifiers, the first three octets of a MAC address, to vendor names.
+type OUIDatabase map[[3]byte]string
+
+// BuiltinOUIs returns the vendors embedded in subnetCalc: a selection of common network, server, and virtualization
+// vendors rather than the whole registry.
+func BuiltinOUIs() OUIDatabase {
+	db, _ := ReadOUIs(bytes.NewReader(ouiCSV))
+	return db
+}
+
+// ReadOUIs reads a vendor database in either of the layouts the IEEE publishes the MA-L registry in: oui.csv, or the
+// "00-11-22 (hex) Vendor" lines of oui.txt. Lines in neither layout are skipped.
+// returns an error if r can't be read.
+func ReadOUIs(r io.Reader) (OUIDatabase, error) {
+	db := OUIDatabase{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		var assignment, vendor string
+		if hexAt := strings.Index(line, "(hex)"); hexAt >= 0 {
+			assignment = strings.ReplaceAll(strings.TrimSpace(line[:hexAt]), "-", "")
+			vendor = strings.TrimSpace(line[hexAt+len("(hex)"):])
+		} else if fields, err := csv.NewReader(strings.NewReader(line)).Read(); err == nil && len(fields) >= 3 {
+			assignment, vendor = fields[1], strings.TrimSpace(fields[2])
+		}
+		b, err := hex.DecodeString(assignment)
+		if err != nil || len(b) != 3 || vendor == "" {
+			continue
+		}
+		db[[3]byte(b)] = vendor
+	}
+	return db, scanner.Err()
+}
+
+// ParseMAC parses a 48-bit MAC address separated by colons, dashes, or Cisco style dots, or written as 12 bare hex
+// digits.
+// returns an error if s isn't a 48-bit MAC address.
+func ParseMAC(s string) (net.HardwareAddr, error) {
+	if b, err := hex.DecodeString(s); err == nil && len(b) == 6 {
+		return b, nil
+	}
+	mac, err := net.ParseMAC(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(mac) != 6 {
+		return nil, fmt.Errorf("%s is not a 48-bit MAC address", s)
+	}
+	return mac, nil
+}
+
+// InterfaceID returns the modified EUI-64 interface identifier for mac described in RFC 4291 appendix A: ff:fe
+// inserted in the middle and the universal/local bit inverted.
+func InterfaceID(mac net.HardwareAddr) [8]byte {
+	return [8]byte{mac[0] ^ 0x02, mac[1], mac[2], 0xff, 0xfe, mac[3], mac[4], mac[5]}
+}
+
+// SLAACAddr returns the address a host with mac configures for itself in the /64 prefix by stateless address
+// autoconfiguration, using its modified EUI-64 interface identifier.
+// returns an error if prefix isn't an IPv6 prefix of /64 or shorter.
+func SLAACAddr(prefix netip.Prefix, mac net.HardwareAddr) (netip.Addr, error) {
+	if !prefix.Addr().Is6() || prefix.Bits() > 64 {
+		return netip.Addr{}, fmt.Errorf("%v is not an IPv6 prefix of /64 or shorter", prefix)
+	}
+	b := prefix.Masked().Addr().As16()
+	id := InterfaceID(mac)
+	copy(b[8:], id[:])
+	return netip.AddrFrom16(b), nil
+}
+
+// MACInfo describes a MAC address, its vendor, and the IPv6 addresses derived from it.
+type MACInfo struct {
+	MAC       string      `json:"mac"`
+	Hyphens   string      `json:"hyphens"`
+	Dots      string      `json:"dots"`
+	Bare      string      `json:"bare"`
+	OUI       string      `json:"oui"`
+	Vendor    string      `json:"vendor,omitempty"`
+	Multicast bool        `json:"multicast"`
+	Local     bool        `json:"local"` // locally administered, so the OUI wasn't assigned by the IEEE
+	EUI64     string      `json:"eui64"` // modified EUI-64 interface identifier
+	LinkLocal netip.Addr  `json:"linkLocal"`
+	SLAAC     *netip.Addr `json:"slaac,omitempty"`
+}
+
+// NewMACInfo returns the details of mac, with its vendor looked up in db. Locally administered addresses have no
+// vendor.
+func NewMACInfo(mac net.HardwareAddr, db OUIDatabase) MACInfo {
+	bare := hex.EncodeToString(mac)
+	id := InterfaceID(mac)
+	linkLocal, _ := SLAACAddr(netip.MustParsePrefix("fe80::/64"), mac)
+	info := MACInfo{
+		MAC:       mac.String(),
+		Hyphens:   strings.ReplaceAll(mac.String(), ":", "-"),
+		Dots:      bare[0:4] + "." + bare[4:8] + "." + bare[8:12],
+		Bare:      bare,
+		OUI:       mac[:3].String(),
+		Multicast: mac[0]&0x01 != 0,
+		Local:     mac[0]&0x02 != 0,
+		EUI64:     net.HardwareAddr(id[:]).String(),
+		LinkLocal: linkLocal,
+	}
+	if !info.Local {
+		info.Vendor = db[[3]byte(mac[:3])]
+	}
+	return info
+}
@@ -0,0 +1,595 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/netip"
+)
+
+// Network holds the details of an IP network and, optionally, the subnets it has been split into.
+type Network struct {
+	CIDR          netip.Prefix  `json:"cidr"`
+	FirstHostIP   netip.Addr    `json:"firstIP"`
+	LastHostIP    netip.Addr    `json:"lastIP"`
+	NetworkAddr   netip.Addr    `json:"networkAddr"`
+	BroadcastAddr netip.Addr    `json:"broadcastAddr"`
+	Gateway       *netip.Addr   `json:"gateway,omitempty"`
+	Anycast       *netip.Addr   `json:"subnetRouterAnycast,omitempty"`
+	SubnetMask    netip.Addr    `json:"subnetMask"`
+	WildcardMask  netip.Addr    `json:"wildcardMask"`
+	MaskBits      int           `json:"maskBits"`
+	SubnetBits    int           `json:"subnetBits"`
+	MaxSubnets    *big.Int      `json:"maxSubnets"`
+	MaxHosts      *big.Int      `json:"maxHosts"`
+	MaskSize      int           `json:"-"`
+	Label         string        `json:"label,omitempty"`
+	Status        string        `json:"status,omitempty"`
+	VLAN          int           `json:"vlan,omitempty"`
+	Note          string        `json:"note,omitempty"`
+	Utilization   *float64      `json:"utilization,omitempty"`
+	Index         *big.Int      `json:"index,omitempty"`
+	SubnetCount64 *big.Int      `json:"subnetCount64,omitempty"`
+	GUA           *GUAStructure `json:"gua,omitempty"`
+	Numeric       *NumericForms `json:"numeric,omitempty"`
+	Address       *AddressInfo  `json:"address,omitempty"`
+	Stats         *Stats        `json:"stats,omitempty"`
+	Subnets       []Network     `json:"subnets,omitempty"`
+}
+
+// globalUnicast is the IPv6 global unicast address space defined in RFC 4291.
+var globalUnicast = netip.MustParsePrefix("2000::/3")
+
+// GUAStructure describes how the bits of an IPv6 global unicast prefix are divided, as described in RFC 3587.
+type GUAStructure struct {
+	RoutingPrefixBits int `json:"globalRoutingPrefixBits"`
+	SubnetIDBits      int `json:"subnetIdBits"`
+	InterfaceIDBits   int `json:"interfaceIdBits"`
+}
+
+// guaStructures and subnetCounts64 memoize the GUA structure and /64 count for every IPv6 prefix length, so a large
+// split doesn't allocate them for every subnet. The values are shared between networks and must not be modified.
+var guaStructures, subnetCounts64 = func() ([]*GUAStructure, []*big.Int) {
+	structures := make([]*GUAStructure, 129)
+	counts := make([]*big.Int, 65)
+	for bits := range structures {
+		routing := min(bits, 64)
+		structures[bits] = &GUAStructure{RoutingPrefixBits: routing, SubnetIDBits: 64 - routing, InterfaceIDBits: 64}
+		if bits <= 64 {
+			counts[bits] = new(big.Int).Lsh(big.NewInt(1), uint(64-bits))
+		}
+	}
+	return structures, counts
+}()
+
+// hostCounts4 and hostCounts6 memoize the number of usable hosts for every prefix length of each family for the same
+// reason, and are shared in the same way.
+var hostCounts4, hostCounts6 = hostCountTable(32), hostCountTable(128)
+
+// hostCountTable returns the number of usable hosts, excluding the network and broadcast addresses, in prefixes of
+// every length from 0 through bitLen. A host prefix, /32 or /128, is the single host it names, and a point-to-point
+// prefix, /31 or /127, uses both of its addresses.
+func hostCountTable(bitLen int) []*big.Int {
+	table := make([]*big.Int, bitLen+1)
+	for bits := range table {
+		hosts := new(big.Int).Lsh(big.NewInt(1), uint(bitLen-bits))
+		if hosts.Sub(hosts, big.NewInt(2)).Sign() < 0 {
+			hosts.SetInt64(0)
+		}
+		table[bits] = hosts
+	}
+	table[bitLen-1] = big.NewInt(2)
+	table[bitLen] = big.NewInt(1)
+	return table
+}
+
+// getGUAStructure returns the global routing prefix, subnet ID, and interface ID bits of an IPv6 global unicast
+// network, or nil for any other network. Interface IDs are always 64 bits, so prefixes longer than a /64 have no
+// subnet ID bits left.
+func (n Network) getGUAStructure() *GUAStructure {
+	if !n.NetworkAddr.Is6() || !globalUnicast.Contains(n.NetworkAddr) {
+		return nil
+	}
+	return guaStructures[n.MaskBits]
+}
+
+// getSubnetCount64 returns the number of /64 subnets in an IPv6 network, or nil for IPv4 networks and IPv6 networks
+// smaller than a /64.
+func (n Network) getSubnetCount64() *big.Int {
+	if !n.NetworkAddr.Is6() || n.MaskBits > 64 {
+		return nil
+	}
+	return subnetCounts64[n.MaskBits]
+}
+
+// getMaxHosts returns the number of usable hosts in the network, excluding the network and broadcast addresses.
+func (n Network) getMaxHosts() *big.Int {
+	if n.MaskSize == 32 {
+		return hostCounts4[n.MaskBits]
+	}
+	return hostCounts6[n.MaskBits]
+}
+
+// getAnycastAddr returns the subnet-router anycast address of an IPv6 network, its network address, as described in
+// RFC 4291 section 2.6.1, or nil for IPv4 networks and IPv6 point-to-point and host prefixes, which RFC 6164 exempts.
+func (n Network) getAnycastAddr() *netip.Addr {
+	if !n.NetworkAddr.Is6() || n.MaskBits >= n.MaskSize-1 {
+		return nil
+	}
+	a := n.NetworkAddr
+	return &a
+}
+
+// getBroadcastAddr calculates the broadcast address for a subnet, the network address with every host bit set.
+// returns the broadcast address as a netip.Addr.
+func (n Network) getBroadcastAddr() netip.Addr {
+	return lastAddr(n.CIDR)
+}
+
+// getSubnetBits calculates the available subnet bits for a given network address and mask bits based on the network class.
+// returns an integer representing the number of subnet bits.
+func (n Network) getSubnetBits() int {
+	firstOctet := n.NetworkAddr.AsSlice()[0]
+	switch {
+	case firstOctet < 128:
+		return n.MaskBits - 8
+	case firstOctet < 192:
+		return n.MaskBits - 16
+	case firstOctet < 224:
+		return n.MaskBits - 24
+	case firstOctet < 240:
+		return n.MaskBits - 32
+	default:
+		return n.MaskBits - 40
+	}
+}
+
+// maxSubnetCounts memoizes the number of subnets for every count of subnet bits, up to the 120 of an IPv6 /128 in the
+// class A range, so each Network shares one value instead of allocating its own.
+var maxSubnetCounts = func() []*big.Int {
+	counts := make([]*big.Int, 121)
+	for bits := range counts {
+		counts[bits] = new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	}
+	return counts
+}()
+
+// getMaxSubnets returns the number of subnets of the network's size in its classful network, or 0 if the network is
+// larger than its class.
+func (n Network) getMaxSubnets() *big.Int {
+	if n.SubnetBits < 0 {
+		return new(big.Int)
+	}
+	return maxSubnetCounts[n.SubnetBits]
+}
+
+// getSubnetMask calculates the subnet mask given the number of mask bits and the mask size.
+// returns the subnet mask as a netip.Addr.
+func (n Network) getSubnetMask() netip.Addr {
+	return MaskFromBits(n.MaskBits, n.MaskSize)
+}
+
+// splitPrealloc is the most subnets Split allocates room for up front. Larger splits grow n.Subnets as they go, so a
+// bad size doesn't reserve gigabytes before the first subnet is generated.
+const splitPrealloc = 1 << 16
+
+// Split populates n.Subnets with every subnet of size subnetMaskBits contained in the network. Every subnet is held in
+// memory, so very large splits, such as 0.0.0.0/0 into /32s, exhaust it; use SplitSeq to generate them lazily instead.
+// returns an error if the subnets would not be smaller than the network, or if there are more than fit in a slice.
+func (n *Network) Split(subnetMaskBits int) error {
+	if subnetMaskBits <= n.MaskBits || subnetMaskBits > n.MaskSize {
+		return fmt.Errorf("subnet mask bits, %d, must be larger than the supernet's mask bits: %d", subnetMaskBits, n.MaskBits)
+	}
+
+	// get the number of subnets of size 'subnetMaskBits' that will fit in the supernet
+	count := n.SubnetCount(subnetMaskBits)
+	if !count.IsInt64() || count.Int64() > math.MaxInt {
+		return fmt.Errorf("%v contains %v /%d subnets, too many to hold in memory", n.CIDR, count, subnetMaskBits)
+	}
+	numSubnets := int(count.Int64())
+
+	n.Subnets = make([]Network, 0, min(numSubnets, splitPrealloc))
+	size := uint128{lo: 1}.lsh(uint(n.MaskSize - subnetMaskBits))
+	next := u128(n.NetworkAddr)
+	for i := 0; i < numSubnets; i++ {
+		addr, _ := next.addr(n.MaskSize)
+		n.Subnets = append(n.Subnets, NewNetworkFromPrefix(netip.PrefixFrom(addr, subnetMaskBits)))
+		if i == numSubnets-1 {
+			// stop before stepping past the last subnet, which overflows at the top of the address space
+			break
+		}
+		var ok bool
+		if next, ok = next.add(size); !ok {
+			return fmt.Errorf("subnet %d of %v overflows the address space", i+2, n.CIDR)
+		}
+	}
+	return nil
+}
+
+// Seq is a sequence of networks generated one at a time, passed to yield until it returns false. It has the shape of
+// iter.Seq[Network], so it can be ranged over once the module targets Go 1.23.
+type Seq func(yield func(Network) bool)
+
+// SplitSeq returns the subnets of size bits contained in prefix as a sequence, generating each one only when it is
+// needed, so splits too large to hold in memory can be consumed lazily. Each subnet's Index is set to its 1-based
+// position in the split.
+// returns an error if the subnets would not be smaller than the prefix.
+func SplitSeq(prefix netip.Prefix, bits int) (Seq, error) {
+	prefix = prefix.Masked()
+	if bits <= prefix.Bits() || bits > prefix.Addr().BitLen() {
+		return nil, fmt.Errorf("subnet mask bits, %d, must be larger than the supernet's mask bits: %d", bits, prefix.Bits())
+	}
+	return func(yield func(Network) bool) {
+		count := AddressCount(prefix)
+		count.Rsh(count, uint(prefix.Addr().BitLen()-bits))
+		size := uint128{lo: 1}.lsh(uint(prefix.Addr().BitLen() - bits))
+		next, one := u128(prefix.Addr()), big.NewInt(1)
+		for i := big.NewInt(1); i.Cmp(count) <= 0; i.Add(i, one) {
+			addr, _ := next.addr(prefix.Addr().BitLen())
+			s := NewNetworkFromPrefix(netip.PrefixFrom(addr, bits))
+			s.Index = new(big.Int).Set(i)
+			if !yield(s) {
+				return
+			}
+			// stepping past the last subnet overflows at the top of the address space, but the loop ends first
+			next, _ = next.add(size)
+		}
+	}, nil
+}
+
+// SetUtilization records the fraction of the network and each of its subnets covered by the allocated prefixes.
+func (n *Network) SetUtilization(allocated []netip.Prefix) {
+	u := Utilization(n.CIDR, allocated)
+	n.Utilization = &u
+	for i := range n.Subnets {
+		n.Subnets[i].SetUtilization(allocated)
+	}
+}
+
+// SubnetCount returns the number of subnets of size bits contained in the network.
+func (n Network) SubnetCount(bits int) *big.Int {
+	if bits < n.MaskBits {
+		return new(big.Int)
+	}
+	return new(big.Int).Lsh(big.NewInt(1), uint(bits-n.MaskBits))
+}
+
+// Contains reports whether addr is inside the network.
+func (n Network) Contains(addr netip.Addr) bool {
+	return n.CIDR.Contains(addr.Unmap())
+}
+
+// ContainsPrefix reports whether every address of p is inside the network.
+func (n Network) ContainsPrefix(p netip.Prefix) bool {
+	return n.CIDR.Bits() <= p.Bits() && n.Contains(p.Addr())
+}
+
+// Overlaps returns the addresses the network shares with other. Prefixes are aligned blocks, so this is always the
+// whole of the smaller of the two.
+// returns false if they share no addresses.
+func (n Network) Overlaps(other Network) (Range, bool) {
+	if !n.CIDR.Overlaps(other.CIDR) {
+		return Range{}, false
+	}
+	if n.CIDR.Bits() > other.CIDR.Bits() {
+		return RangeOf(n.CIDR), true
+	}
+	return RangeOf(other.CIDR), true
+}
+
+// Offset returns the position of addr in the network, counting the network address as 0.
+// returns false if addr is outside the network.
+func (n Network) Offset(addr netip.Addr) (*big.Int, bool) {
+	if !n.Contains(addr) {
+		return nil, false
+	}
+	offset, _ := u128(addr.Unmap()).sub(u128(n.CIDR.Masked().Addr()))
+	return offset.big(), true
+}
+
+// SubnetsOf returns up to limit of the network's subnets of size bits, starting with the subnet at offset and then
+// every step subnets, without generating the subnets in between. A limit of 0 returns every selected subnet. Each
+// subnet's Index is set to its 1-based position among all of the network's subnets of that size.
+// returns an error if the subnets would not be smaller than the network.
+func (n Network) SubnetsOf(bits int, offset, step *big.Int, limit int) ([]Network, error) {
+	if bits <= n.MaskBits || bits > n.MaskSize {
+		return nil, fmt.Errorf("subnet mask bits, %d, must be larger than the supernet's mask bits: %d", bits, n.MaskBits)
+	}
+	if step.Sign() <= 0 {
+		return nil, fmt.Errorf("step must be positive, got %v", step)
+	}
+
+	var subnets []Network
+	count := n.SubnetCount(bits)
+	base := u128(n.NetworkAddr)
+	for i := new(big.Int).Set(offset); i.Cmp(count) < 0 && (limit == 0 || len(subnets) < limit); i.Add(i, step) {
+		// i is less than the subnet count, so the subnet's address always fits in the network
+		index, _ := u128FromBig(i)
+		addr, _ := base.or(index.lsh(uint(n.MaskSize - bits))).addr(n.MaskSize)
+		s := NewNetworkFromPrefix(netip.PrefixFrom(addr, bits))
+		s.Index = new(big.Int).Add(i, big.NewInt(1))
+		subnets = append(subnets, s)
+	}
+	return subnets, nil
+}
+
+// SplitSizes populates n.Subnets with one subnet of each of the requested sizes, in order. Each subnet starts at the
+// first address after the previous subnet that is aligned to its size, so a smaller subnet followed by a larger one
+// leaves a gap.
+// returns an error if a size isn't smaller than the network or the subnets don't fit in it.
+func (n *Network) SplitSizes(sizes []int) error {
+	return n.SplitSizesWithGrowth(sizes, 0)
+}
+
+// SplitSizesWithGrowth works like SplitSizes, but places each subnet at the start of a block large enough for it to
+// double in size growth times, and adds the rest of the block to n.Subnets with the reserved status so the subnet can
+// grow later without renumbering.
+// returns an error if a size isn't smaller than the network or the subnets and their reserves don't fit in it.
+func (n *Network) SplitSizesWithGrowth(sizes []int, growth int) error {
+	n.Subnets = nil
+	next := n.NetworkAddr
+	for _, bits := range sizes {
+		if bits <= n.MaskBits || bits > n.MaskSize {
+			return fmt.Errorf("subnet mask bits, %d, must be larger than the supernet's mask bits: %d", bits, n.MaskBits)
+		}
+		if bits-growth < n.MaskBits {
+			return fmt.Errorf("a /%d can't grow %d times inside %v", bits, growth, n.CIDR)
+		}
+		block, _ := next.Prefix(bits - growth)
+		if block.Addr() != next {
+			block = netip.PrefixFrom(lastAddr(block).Next(), bits-growth)
+		}
+		if !next.IsValid() || !block.Addr().IsValid() || !n.CIDR.Contains(block.Addr()) {
+			return fmt.Errorf("subnets of sizes %v do not fit in %v", sizes, n.CIDR)
+		}
+
+		p := netip.PrefixFrom(block.Addr(), bits)
+		n.Subnets = append(n.Subnets, NewNetworkFromPrefix(p))
+		if growth > 0 {
+			for _, r := range Exclude(block, []netip.Prefix{p}) {
+				reserved := NewNetworkFromPrefix(r)
+				reserved.Label = fmt.Sprintf("growth for %v", p)
+				reserved.Status = StatusReserved
+				n.Subnets = append(n.Subnets, reserved)
+			}
+		}
+		next = lastAddr(block).Next()
+	}
+	return nil
+}
+
+// NewNetworkFromPrefix returns a Network struct containing details about the network the prefix belongs to.
+func NewNetworkFromPrefix(p netip.Prefix) Network {
+	var n Network
+	n.CIDR = p.Masked()
+	n.NetworkAddr = n.CIDR.Addr()
+	n.MaskBits = n.CIDR.Bits()
+	n.MaskSize = n.CIDR.Addr().BitLen()
+	n.SubnetMask = n.getSubnetMask()
+	n.WildcardMask = CalculateWildcardMask(n.SubnetMask)
+	n.BroadcastAddr = n.getBroadcastAddr()
+	n.Anycast = n.getAnycastAddr()
+	n.FirstHostIP = n.NetworkAddr.Next()
+	n.LastHostIP = n.BroadcastAddr.Prev()
+	if n.MaskBits >= n.MaskSize-1 {
+		n.FirstHostIP, n.LastHostIP = n.NetworkAddr, n.BroadcastAddr
+	}
+	n.SubnetBits = n.getSubnetBits()
+	n.MaxSubnets = n.getMaxSubnets()
+	n.MaxHosts = n.getMaxHosts()
+	n.SubnetCount64 = n.getSubnetCount64()
+	n.GUA = n.getGUAStructure()
+	return n
+}
+
+// NewNetwork parses a CIDR and returns a Network struct with details about the network.
+// returns an error if the CIDR isn't a valid IPv4 or IPv6 prefix.
+func NewNetwork(cidr string) (Network, error) {
+	// use netip package to confirm the provided input is a valid ipv4 or ipv6 CIDR
+	p, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return Network{}, err
+	}
+	return NewNetworkFromPrefix(p), nil
+}
+
+// PointToPoint reports whether n is a point-to-point link whose two addresses are both usable hosts: an IPv4 /31, as
+// described in RFC 3021, or an IPv6 /127, as described in RFC 6164.
+func (n Network) PointToPoint() bool {
+	return n.MaskBits == n.MaskSize-1
+}
+
+// HasBroadcast reports whether n has a broadcast address. IPv6 networks, point-to-point links, and IPv4 host routes
+// don't, so the last address of the network is just that.
+func (n Network) HasBroadcast() bool {
+	return n.NetworkAddr.Is4() && n.MaskBits < n.MaskSize-1
+}
+
+// NetworkView is the JSON form of a Network, written by both MarshalJSON and the JSON formatters. Its addresses and
+// prefixes are already text, so how they are written is chosen before the network is encoded, and the last address of
+// the network is held under the broadcastAddr or the lastAddr key, whichever applies.
+type NetworkView struct {
+	CIDR          string           `json:"cidr"`
+	FirstHostIP   string           `json:"firstIP"`
+	LastHostIP    string           `json:"lastIP"`
+	NetworkAddr   string           `json:"networkAddr"`
+	BroadcastAddr string           `json:"broadcastAddr,omitempty"`
+	LastAddr      string           `json:"lastAddr,omitempty"`
+	Gateway       string           `json:"gateway,omitempty"`
+	Anycast       string           `json:"subnetRouterAnycast,omitempty"`
+	SubnetMask    string           `json:"subnetMask"`
+	WildcardMask  string           `json:"wildcardMask"`
+	MaskBits      int              `json:"maskBits"`
+	SubnetBits    int              `json:"subnetBits"`
+	MaxSubnets    *big.Int         `json:"maxSubnets"`
+	MaxHosts      *big.Int         `json:"maxHosts"`
+	Label         string           `json:"label,omitempty"`
+	Status        string           `json:"status,omitempty"`
+	VLAN          int              `json:"vlan,omitempty"`
+	Note          string           `json:"note,omitempty"`
+	Utilization   *float64         `json:"utilization,omitempty"`
+	Index         *big.Int         `json:"index,omitempty"`
+	SubnetCount64 *big.Int         `json:"subnetCount64,omitempty"`
+	GUA           *GUAStructure    `json:"gua,omitempty"`
+	Numeric       *NumericForms    `json:"numeric,omitempty"`
+	Address       *AddressInfoView `json:"address,omitempty"`
+	Stats         *StatsView       `json:"stats,omitempty"`
+	Subnets       []NetworkView    `json:"subnets,omitempty"`
+}
+
+// AddressInfoView is the JSON form of an AddressInfo within a NetworkView.
+type AddressInfoView struct {
+	Addr           string   `json:"addr"`
+	Classification string   `json:"classification"`
+	Block          string   `json:"block,omitempty"`
+	RFC            string   `json:"rfc,omitempty"`
+	ReverseName    string   `json:"reverseName"`
+	Integer        *big.Int `json:"integer"`
+	Hex            string   `json:"hex"`
+}
+
+// StatsView is the JSON form of a Stats within a NetworkView.
+type StatsView struct {
+	Subnets       int      `json:"subnets"`
+	UsableHosts   *big.Int `json:"usableHosts"`
+	Smallest      string   `json:"smallest"`
+	Largest       string   `json:"largest"`
+	FreeAddresses *big.Int `json:"freeAddresses"`
+	Free          []string `json:"free,omitempty"`
+}
+
+// ViewOptions controls how a NetworkView is written.
+type ViewOptions struct {
+	// Addr and Prefix write valid addresses and prefixes as text, using their String methods if nil. Invalid ones are
+	// always written as empty strings, the way netip encodes them.
+	Addr   func(netip.Addr) string
+	Prefix func(netip.Prefix) string
+	// LegacyBroadcast holds the last address under broadcastAddr even for networks without a broadcast address.
+	LegacyBroadcast bool
+}
+
+// addr returns the text of a, or an empty string if it isn't valid.
+func (o ViewOptions) addr(a netip.Addr) string {
+	switch {
+	case !a.IsValid():
+		return ""
+	case o.Addr == nil:
+		return a.String()
+	}
+	return o.Addr(a)
+}
+
+// prefix returns the text of p, or an empty string if it isn't valid.
+func (o ViewOptions) prefix(p netip.Prefix) string {
+	switch {
+	case !p.IsValid():
+		return ""
+	case o.Prefix == nil:
+		return p.String()
+	}
+	return o.Prefix(p)
+}
+
+// View returns the JSON form of n and its subnets, written with o.
+func (n Network) View(o ViewOptions) NetworkView {
+	v := NetworkView{
+		CIDR: o.prefix(n.CIDR), FirstHostIP: o.addr(n.FirstHostIP), LastHostIP: o.addr(n.LastHostIP),
+		NetworkAddr: o.addr(n.NetworkAddr), SubnetMask: o.addr(n.SubnetMask), WildcardMask: o.addr(n.WildcardMask),
+		MaskBits: n.MaskBits, SubnetBits: n.SubnetBits, MaxSubnets: n.MaxSubnets, MaxHosts: n.MaxHosts,
+		Label: n.Label, Status: n.Status, VLAN: n.VLAN, Note: n.Note, Utilization: n.Utilization, Index: n.Index,
+		SubnetCount64: n.SubnetCount64, GUA: n.GUA, Numeric: n.Numeric,
+	}
+	if n.HasBroadcast() || o.LegacyBroadcast {
+		v.BroadcastAddr = o.addr(n.BroadcastAddr)
+	} else {
+		v.LastAddr = o.addr(n.BroadcastAddr)
+	}
+	if n.Gateway != nil {
+		v.Gateway = o.addr(*n.Gateway)
+	}
+	if n.Anycast != nil {
+		v.Anycast = o.addr(*n.Anycast)
+	}
+	if a := n.Address; a != nil {
+		v.Address = &AddressInfoView{Addr: o.addr(a.Addr), Classification: a.Classification, RFC: a.RFC,
+			ReverseName: a.ReverseName, Integer: a.Integer, Hex: a.Hex}
+		if a.Block != nil {
+			v.Address.Block = o.prefix(*a.Block)
+		}
+	}
+	if st := n.Stats; st != nil {
+		v.Stats = &StatsView{Subnets: st.Subnets, UsableHosts: st.UsableHosts, Smallest: o.prefix(st.Smallest),
+			Largest: o.prefix(st.Largest), FreeAddresses: st.FreeAddresses}
+		for _, p := range st.Free {
+			v.Stats.Free = append(v.Stats.Free, o.prefix(p))
+		}
+	}
+	for _, s := range n.Subnets {
+		v.Subnets = append(v.Subnets, s.View(o))
+	}
+	return v
+}
+
+// MarshalJSON encodes the network's View with addresses in their usual form.
+func (n Network) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.View(ViewOptions{}))
+}
+
+// UnmarshalJSON decodes a network written by the JSON formatter, including nested subnets. Fields derived from the
+// CIDR that are missing from the document are recalculated, so a document containing only a cidr is enough.
+func (n *Network) UnmarshalJSON(b []byte) error {
+	// decode through an alias type so this method isn't called recursively
+	type network Network
+	var decoded network
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return err
+	}
+	if !decoded.CIDR.IsValid() {
+		return errors.New("network is missing a valid cidr")
+	}
+
+	derived := NewNetworkFromPrefix(decoded.CIDR)
+	if decoded.CIDR != derived.CIDR {
+		return fmt.Errorf("network cidr %s has host bits set, expected %s", decoded.CIDR, derived.CIDR)
+	}
+	derived.Gateway = decoded.Gateway
+	derived.Label = decoded.Label
+	derived.Status = decoded.Status
+	derived.VLAN = decoded.VLAN
+	derived.Note = decoded.Note
+	derived.Utilization = decoded.Utilization
+	derived.Index = decoded.Index
+	derived.Address = decoded.Address
+	derived.Stats = decoded.Stats
+	derived.Subnets = decoded.Subnets
+	*n = derived
+	return nil
+}
+
+// ReadNetwork decodes a network from JSON written by the JSON formatter, with or without the --with-meta envelope.
+func ReadNetwork(r io.Reader) (Network, error) {
+	var doc struct {
+		Network *Network `json:"network"`
+		CIDR    string   `json:"cidr"`
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return Network{}, err
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return Network{}, err
+	}
+	if doc.Network != nil && doc.CIDR == "" {
+		return *doc.Network, nil
+	}
+
+	var n Network
+	err = json.Unmarshal(b, &n)
+	return n, err
+}
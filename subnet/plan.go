@@ -0,0 +1,642 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Allocation statuses.
+const (
+	StatusAllocated  = "allocated"
+	StatusReserved   = "reserved"
+	StatusDeprecated = "deprecated"
+	StatusContainer  = "container" // groups the allocations nested inside it, such as a region or site
+)
+
+// Statuses lists the known allocation statuses.
+var Statuses = []string{StatusAllocated, StatusReserved, StatusDeprecated, StatusContainer}
+
+// Allocation is a named prefix carved out of one of a plan's supernets.
+type Allocation struct {
+	ID     string            `json:"id,omitempty"`
+	CIDR   netip.Prefix      `json:"cidr"`
+	Name   string            `json:"name,omitempty"`
+	Status string            `json:"status,omitempty"`
+	Zone   string            `json:"zone,omitempty"`
+	VLAN   int               `json:"vlan,omitempty"`
+	Pair   string            `json:"pair,omitempty"` // ID of the allocation in the other address family planned with it
+	Note   string            `json:"note,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Extra holds members of the allocation's JSON object that subnetCalc doesn't know about, so they survive being
+	// loaded and saved again.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Plan is an address plan: the supernets available to a site or organization and the allocations made from them.
+type Plan struct {
+	Name        string         `json:"name,omitempty"`
+	Supernets   []netip.Prefix `json:"supernets,omitempty"`
+	Allocations []Allocation   `json:"allocations"`
+	History     []Event        `json:"history,omitempty"` // append-only record of the changes made by subnetCalc
+
+	// Extra holds members of the plan's JSON object that subnetCalc doesn't know about, so they survive being loaded
+	// and saved again.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes an allocation, keeping any unknown members in Extra.
+func (a *Allocation) UnmarshalJSON(b []byte) error {
+	type allocation Allocation
+	var decoded allocation
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return err
+	}
+	extra, err := unknownMembers(b, decoded)
+	if err != nil {
+		return err
+	}
+	*a = Allocation(decoded)
+	a.Extra = extra
+	return nil
+}
+
+// MarshalJSON encodes an allocation followed by the unknown members in Extra.
+func (a Allocation) MarshalJSON() ([]byte, error) {
+	type allocation Allocation
+	b, err := json.Marshal(allocation(a))
+	if err != nil {
+		return nil, err
+	}
+	return appendMembers(b, a.Extra)
+}
+
+// UnmarshalJSON decodes a plan, keeping any unknown members in Extra.
+func (p *Plan) UnmarshalJSON(b []byte) error {
+	type plan Plan
+	var decoded plan
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return err
+	}
+	extra, err := unknownMembers(b, decoded)
+	if err != nil {
+		return err
+	}
+	*p = Plan(decoded)
+	p.Extra = extra
+	return nil
+}
+
+// MarshalJSON encodes a plan followed by the unknown members in Extra.
+func (p Plan) MarshalJSON() ([]byte, error) {
+	type plan Plan
+	b, err := json.Marshal(plan(p))
+	if err != nil {
+		return nil, err
+	}
+	return appendMembers(b, p.Extra)
+}
+
+// unknownMembers returns the members of the JSON object b that don't correspond to a field of the struct v.
+func unknownMembers(b []byte, v any) (map[string]json.RawMessage, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(b, &members); err != nil {
+		return nil, err
+	}
+	t := reflect.TypeOf(v)
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		for key := range members {
+			// encoding/json matches member names case-insensitively
+			if strings.EqualFold(key, name) {
+				delete(members, key)
+			}
+		}
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return members, nil
+}
+
+// appendMembers adds the members to the end of the JSON object b, in sorted order.
+func appendMembers(b []byte, members map[string]json.RawMessage) ([]byte, error) {
+	if len(members) == 0 {
+		return b, nil
+	}
+	keys := make([]string, 0, len(members))
+	for k := range members {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b = b[:len(b)-1] // drop the closing brace
+	for _, k := range keys {
+		if len(b) > 1 {
+			b = append(b, ',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		b = append(append(append(b, key...), ':'), members[k]...)
+	}
+	return append(b, '}'), nil
+}
+
+// Severity of a validation issue.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Issue is a problem found while validating a plan.
+type Issue struct {
+	Severity string       `json:"severity"`
+	CIDR     netip.Prefix `json:"cidr"`
+	Message  string       `json:"message"`
+}
+
+// LoadPlan reads a plan from a JSON file.
+func LoadPlan(path string) (Plan, error) {
+	var p Plan
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal(b, &p); err != nil {
+		return p, fmt.Errorf("parsing plan %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Save writes the plan to a JSON file, replacing any existing file.
+func (p Plan) Save(path string) error {
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+// supernetFor returns the plan's supernet containing prefix, or false if no supernet contains it.
+func (p Plan) supernetFor(prefix netip.Prefix) (netip.Prefix, bool) {
+	for _, s := range p.Supernets {
+		if s.Bits() <= prefix.Bits() && s.Contains(prefix.Addr()) {
+			return s, true
+		}
+	}
+	return netip.Prefix{}, false
+}
+
+// nested reports whether one of the allocations is a container holding the other, the only way allocations may overlap.
+func nested(a, b Allocation) bool {
+	holds := func(outer, inner Allocation) bool {
+		return statusOf(outer) == StatusContainer && outer.CIDR.Bits() < inner.CIDR.Bits() && outer.CIDR.Contains(inner.CIDR.Addr())
+	}
+	return holds(a, b) || holds(b, a)
+}
+
+// Allocate adds an allocation to the plan. The status defaults to allocated.
+// returns an error if the prefix has host bits set, lies outside every supernet, or overlaps an existing allocation
+// other than a container holding it.
+func (p *Plan) Allocate(a Allocation) error {
+	if a.CIDR != a.CIDR.Masked() {
+		return fmt.Errorf("%v has host bits set, expected %v", a.CIDR, a.CIDR.Masked())
+	}
+	if _, ok := p.supernetFor(a.CIDR); !ok && len(p.Supernets) > 0 {
+		return fmt.Errorf("%v is not inside any of the plan's supernets", a.CIDR)
+	}
+	for _, existing := range p.Allocations {
+		if existing.CIDR.Overlaps(a.CIDR) && !nested(existing, a) {
+			return fmt.Errorf("%v overlaps existing allocation %v %s", a.CIDR, existing.CIDR, existing.Name)
+		}
+	}
+	if a.Status == "" {
+		a.Status = StatusAllocated
+	}
+	if a.ID == "" {
+		a.ID = NewID()
+	}
+	p.Allocations = append(p.Allocations, a)
+	return nil
+}
+
+// AllocateNext allocates the first free prefix of size bits in any of the plan's supernets.
+// returns the allocation made, or an error if no supernet has room for it.
+func (p *Plan) AllocateNext(bits int, a Allocation) (Allocation, error) {
+	for _, s := range p.Supernets {
+		if next, ok := NextFree(s, bits, p.allocatedPrefixes()); ok {
+			a.CIDR = next
+			return a, p.Allocate(a)
+		}
+	}
+	return a, fmt.Errorf("no free /%d left in the plan's supernets", bits)
+}
+
+// nextFreeIn returns the first free prefix of size bits in the plan's supernets of the given family, 4 or 6.
+func (p Plan) nextFreeIn(family, bits int) (netip.Prefix, bool) {
+	for _, s := range p.Supernets {
+		if s.Addr().Is4() != (family == 4) {
+			continue
+		}
+		if next, ok := NextFree(s, bits, p.allocatedPrefixes()); ok {
+			return next, true
+		}
+	}
+	return netip.Prefix{}, false
+}
+
+// AllocateDualStack allocates the first free IPv4 prefix of size bits4 and the first free IPv6 prefix of size bits6
+// with the same name, status, zone, and VLAN, each recording the other's ID as its pair.
+// returns the IPv4 and IPv6 allocations, or an error if either family has no room, in which case neither is made.
+func (p *Plan) AllocateDualStack(bits4, bits6 int, a Allocation) (Allocation, Allocation, error) {
+	v4, v6 := a, a
+	var ok bool
+	if v4.CIDR, ok = p.nextFreeIn(4, bits4); !ok {
+		return v4, v6, fmt.Errorf("no free IPv4 /%d left in the plan's supernets", bits4)
+	}
+	if v6.CIDR, ok = p.nextFreeIn(6, bits6); !ok {
+		return v4, v6, fmt.Errorf("no free IPv6 /%d left in the plan's supernets", bits6)
+	}
+	v4.ID, v6.ID = NewID(), NewID()
+	v4.Pair, v6.Pair = v6.ID, v4.ID
+	if err := p.Allocate(v4); err != nil {
+		return v4, v6, err
+	}
+	if err := p.Allocate(v6); err != nil {
+		p.Allocations = p.Allocations[:len(p.Allocations)-1]
+		return v4, v6, err
+	}
+	return p.Allocations[len(p.Allocations)-2], p.Allocations[len(p.Allocations)-1], nil
+}
+
+// AllocateNextWithGrowth allocates the first prefix of size bits in the next free block large enough for it to double
+// in size growth times, and reserves the rest of the block so the allocation can grow later without renumbering.
+// returns the allocation and the reserved prefixes.
+func (p *Plan) AllocateNextWithGrowth(bits, growth int, a Allocation) (Allocation, []netip.Prefix, error) {
+	if growth <= 0 {
+		a, err := p.AllocateNext(bits, a)
+		return a, nil, err
+	}
+	for _, s := range p.Supernets {
+		block, ok := NextFree(s, bits-growth, p.allocatedPrefixes())
+		if !ok {
+			continue
+		}
+		a.CIDR = netip.PrefixFrom(block.Addr(), bits)
+		if err := p.Allocate(a); err != nil {
+			return a, nil, err
+		}
+		a = p.Allocations[len(p.Allocations)-1]
+
+		name := a.Name
+		if name == "" {
+			name = a.CIDR.String()
+		}
+		reserved := Exclude(block, []netip.Prefix{a.CIDR})
+		for _, r := range reserved {
+			if err := p.Allocate(Allocation{CIDR: r, Name: "growth for " + name, Status: StatusReserved, Zone: a.Zone}); err != nil {
+				return a, nil, err
+			}
+		}
+		return a, reserved, nil
+	}
+	return a, nil, fmt.Errorf("no free /%d left in the plan's supernets for a /%d and its growth reserve", bits-growth, bits)
+}
+
+// GrowthRoom returns how many times the allocation of prefix can double in size, taking over neighboring space that
+// is free or only reserved, without leaving its supernet or the container holding it.
+func (p Plan) GrowthRoom(prefix netip.Prefix) int {
+	var others Trie[struct{}]
+	for _, a := range p.Allocations {
+		if a.CIDR != prefix && statusOf(a) != StatusReserved && statusOf(a) != StatusContainer {
+			others.Insert(a.CIDR, struct{}{})
+		}
+	}
+	// without supernets the whole address space is available
+	smallest := 0
+	if len(p.Supernets) > 0 {
+		supernet, ok := p.supernetFor(prefix)
+		if !ok {
+			return 0
+		}
+		smallest = supernet.Bits()
+	}
+	for _, a := range p.Allocations {
+		if statusOf(a) == StatusContainer && a.CIDR.Bits() < prefix.Bits() && a.CIDR.Contains(prefix.Addr()) {
+			smallest = max(smallest, a.CIDR.Bits())
+		}
+	}
+
+	room := 0
+	for bits := prefix.Bits() - 1; bits >= smallest; bits-- {
+		if others.Overlaps(netip.PrefixFrom(prefix.Addr(), bits).Masked()) {
+			break
+		}
+		room++
+	}
+	return room
+}
+
+// NewID returns a random version 4 UUID identifying an allocation.
+func NewID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// AssignIDs gives every allocation without an ID a new one, so the same block can be tracked across renames, diffs,
+// and re-exports once the plan is saved.
+// returns the number of IDs assigned.
+func (p *Plan) AssignIDs() int {
+	assigned := 0
+	for i := range p.Allocations {
+		if p.Allocations[i].ID == "" {
+			p.Allocations[i].ID = NewID()
+			assigned++
+		}
+	}
+	return assigned
+}
+
+// Find returns the allocation for prefix, which may be modified in place.
+// returns false if the prefix hasn't been allocated.
+func (p *Plan) Find(prefix netip.Prefix) (*Allocation, bool) {
+	for i := range p.Allocations {
+		if p.Allocations[i].CIDR == prefix.Masked() {
+			return &p.Allocations[i], true
+		}
+	}
+	return nil, false
+}
+
+// Containing returns the allocation containing prefix, or the prefix's own allocation. Containers are skipped, as they
+// only group the allocations inside them.
+// returns false if no allocation contains the prefix.
+func (p Plan) Containing(prefix netip.Prefix) (Allocation, bool) {
+	prefix = prefix.Masked()
+	for _, a := range p.Allocations {
+		if statusOf(a) != StatusContainer && a.CIDR.Bits() <= prefix.Bits() && a.CIDR.Contains(prefix.Addr()) {
+			return a, true
+		}
+	}
+	return Allocation{}, false
+}
+
+// Free removes the allocation for prefix from the plan.
+// returns an error if the prefix hasn't been allocated.
+func (p *Plan) Free(prefix netip.Prefix) error {
+	for i, a := range p.Allocations {
+		if a.CIDR == prefix.Masked() {
+			p.Allocations = append(p.Allocations[:i], p.Allocations[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%v is not allocated", prefix)
+}
+
+// allocatedPrefixes returns the prefix of every allocation in the plan except containers, whose space is only used by
+// the allocations inside them.
+func (p Plan) allocatedPrefixes() []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(p.Allocations))
+	for _, a := range p.Allocations {
+		if statusOf(a) != StatusContainer {
+			prefixes = append(prefixes, a.CIDR)
+		}
+	}
+	return prefixes
+}
+
+// FreeSpace returns the smallest set of prefixes covering the space in the plan's supernets that isn't allocated.
+func (p Plan) FreeSpace() []netip.Prefix {
+	var free []netip.Prefix
+	used := p.allocatedPrefixes()
+	for _, s := range p.Supernets {
+		free = append(free, Exclude(s, used)...)
+	}
+	return free
+}
+
+// Merge adds the supernets and allocations of other to the plan. Supernets and allocations already in the plan, with
+// the same prefix and ID, are skipped.
+// returns an error issue for each allocation overlapping one already in the plan and a warning for each duplicated
+// name. Conflicting allocations are still added, so the result fails Validate until they are resolved.
+func (p *Plan) Merge(other Plan) []Issue {
+	if p.Name == "" {
+		p.Name = other.Name
+	}
+	for _, s := range other.Supernets {
+		if !containsPrefix(p.Supernets, s) {
+			p.Supernets = append(p.Supernets, s)
+		}
+	}
+
+	var issues []Issue
+	existing := append([]Allocation{}, p.Allocations...)
+next:
+	for _, a := range other.Allocations {
+		for _, e := range existing {
+			switch {
+			case e.CIDR == a.CIDR && e.ID == a.ID:
+				continue next
+			case e.CIDR.Overlaps(a.CIDR) && !nested(e, a):
+				issues = append(issues, Issue{SeverityError, a.CIDR, fmt.Sprintf("%s overlaps allocation %v %s", a.Name, e.CIDR, e.Name)})
+			case a.Name != "" && e.Name == a.Name:
+				issues = append(issues, Issue{SeverityWarning, a.CIDR, fmt.Sprintf("name %q is already used by %v", a.Name, e.CIDR)})
+			}
+		}
+		p.Allocations = append(p.Allocations, a)
+	}
+	p.History = mergeHistory(p.History, other.History)
+	return issues
+}
+
+// containsPrefix reports whether prefixes contains p.
+func containsPrefix(prefixes []netip.Prefix, p netip.Prefix) bool {
+	for _, q := range prefixes {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Networks returns a network for each of the plan's supernets, with the allocations inside it as subnets labelled with
+// their name, status, VLAN, and note. Allocations outside every supernet are returned as networks of their own.
+func (p Plan) Networks() []Network {
+	allocated := p.allocatedPrefixes()
+	var networks []Network
+	for _, s := range p.Supernets {
+		n := NewNetworkFromPrefix(s)
+		for _, a := range p.Allocations {
+			if n.CIDR.Bits() <= a.CIDR.Bits() && n.CIDR.Contains(a.CIDR.Addr()) {
+				sub := NewNetworkFromPrefix(a.CIDR)
+				sub.Label, sub.Status, sub.VLAN, sub.Note = a.Name, statusOf(a), a.VLAN, a.Note
+				n.Subnets = append(n.Subnets, sub)
+			}
+		}
+		sort.Slice(n.Subnets, func(i, j int) bool { return n.Subnets[i].CIDR.Addr().Less(n.Subnets[j].CIDR.Addr()) })
+		n.SetUtilization(allocated)
+		networks = append(networks, n)
+	}
+	for _, a := range p.Allocations {
+		if _, ok := p.supernetFor(a.CIDR); !ok {
+			n := NewNetworkFromPrefix(a.CIDR)
+			n.Label, n.Status, n.VLAN, n.Note = a.Name, statusOf(a), a.VLAN, a.Note
+			networks = append(networks, n)
+		}
+	}
+	return networks
+}
+
+// Merge is a pair of sibling allocations with the same status that could be joined into their parent prefix.
+type Merge struct {
+	Parent netip.Prefix `json:"parent"`
+	Lower  netip.Prefix `json:"lower"`
+	Upper  netip.Prefix `json:"upper"`
+	Status string       `json:"status"`
+}
+
+// Merges returns every pair of sibling allocations with the same status, in address order. Free space never needs
+// merging as FreeSpace always returns the largest possible blocks, and containers are never merged.
+func (p Plan) Merges() []Merge {
+	byPrefix := map[netip.Prefix]Allocation{}
+	for _, a := range p.Allocations {
+		byPrefix[a.CIDR] = a
+	}
+
+	var merges []Merge
+	for _, a := range p.Allocations {
+		if a.CIDR.Bits() == 0 || statusOf(a) == StatusContainer {
+			continue
+		}
+		parent, _ := a.CIDR.Addr().Prefix(a.CIDR.Bits() - 1)
+		if parent.Addr() != a.CIDR.Addr() {
+			continue // only look for buddies from the lower half
+		}
+		upper := netip.PrefixFrom(lastAddr(a.CIDR).Next(), a.CIDR.Bits())
+		if b, ok := byPrefix[upper]; ok && statusOf(a) == statusOf(b) {
+			merges = append(merges, Merge{Parent: parent, Lower: a.CIDR, Upper: upper, Status: statusOf(a)})
+		}
+	}
+	sort.Slice(merges, func(i, j int) bool { return merges[i].Parent.Addr().Less(merges[j].Parent.Addr()) })
+	return merges
+}
+
+// statusOf returns the status of an allocation, treating an empty status as allocated.
+func statusOf(a Allocation) string {
+	if a.Status == "" {
+		return StatusAllocated
+	}
+	return a.Status
+}
+
+// Utilization returns the fraction of prefix covered by the allocated prefixes, from 0 to 1.
+func Utilization(prefix netip.Prefix, allocated []netip.Prefix) float64 {
+	used := Coverage(clip(prefix, allocated))
+	ratio, _ := new(big.Float).Quo(new(big.Float).SetInt(used), new(big.Float).SetInt(AddressCount(prefix))).Float64()
+	return ratio
+}
+
+// Utilization returns the fraction of the plan's supernet address space of the given family, 4 or 6, that has been
+// allocated, from 0 to 1. The families aren't combined, since a single IPv6 supernet would dwarf any IPv4 space. It
+// returns false if the plan has no supernets of that family.
+func (p Plan) Utilization(family int) (float64, bool) {
+	total, used := new(big.Int), new(big.Int)
+	allocated := p.allocatedPrefixes()
+	for _, s := range p.Supernets {
+		if s.Addr().Is4() != (family == 4) {
+			continue
+		}
+		total.Add(total, AddressCount(s))
+		used.Add(used, Coverage(clip(s, allocated)))
+	}
+	if total.Sign() == 0 {
+		return 0, false
+	}
+	ratio, _ := new(big.Float).Quo(new(big.Float).SetInt(used), new(big.Float).SetInt(total)).Float64()
+	return ratio, true
+}
+
+// Allocated returns the prefix of every allocation in the plan except containers.
+func (p Plan) Allocated() []netip.Prefix {
+	return p.allocatedPrefixes()
+}
+
+// UtilizationOf returns the fraction of prefix covered by the plan's allocations, from 0 to 1.
+func (p Plan) UtilizationOf(prefix netip.Prefix) float64 {
+	return Utilization(prefix, p.allocatedPrefixes())
+}
+
+// clip returns the parts of the prefixes that lie inside supernet.
+func clip(supernet netip.Prefix, prefixes []netip.Prefix) []netip.Prefix {
+	var inside []netip.Prefix
+	for _, p := range prefixes {
+		switch {
+		case !p.Overlaps(supernet):
+		case p.Bits() <= supernet.Bits():
+			return []netip.Prefix{supernet}
+		default:
+			inside = append(inside, p)
+		}
+	}
+	return inside
+}
+
+// Validate checks the plan for allocations with host bits set, allocations outside every supernet, overlapping
+// allocations, unknown statuses, and broken dual-stack pairs. Sibling allocations that could be merged are reported
+// as info.
+func (p Plan) Validate() []Issue {
+	var issues []Issue
+	byID := map[string]Allocation{}
+	for _, a := range p.Allocations {
+		if a.ID != "" {
+			byID[a.ID] = a
+		}
+	}
+	for i, a := range p.Allocations {
+		if a.CIDR != a.CIDR.Masked() {
+			issues = append(issues, Issue{SeverityError, a.CIDR, fmt.Sprintf("host bits are set, expected %v", a.CIDR.Masked())})
+		}
+		if _, ok := p.supernetFor(a.CIDR); !ok && len(p.Supernets) > 0 {
+			issues = append(issues, Issue{SeverityError, a.CIDR, "not inside any of the plan's supernets"})
+		}
+		switch a.Status {
+		case "", StatusAllocated, StatusReserved, StatusDeprecated, StatusContainer:
+		default:
+			issues = append(issues, Issue{SeverityWarning, a.CIDR, fmt.Sprintf("unknown status %q", a.Status)})
+		}
+		if a.Pair != "" {
+			if pair, ok := byID[a.Pair]; !ok {
+				issues = append(issues, Issue{SeverityWarning, a.CIDR, fmt.Sprintf("paired allocation %s not found", a.Pair)})
+			} else if pair.CIDR.Addr().Is4() == a.CIDR.Addr().Is4() {
+				issues = append(issues, Issue{SeverityWarning, a.CIDR, fmt.Sprintf("paired with %v of the same address family", pair.CIDR)})
+			}
+		}
+		for _, other := range p.Allocations[i+1:] {
+			if a.CIDR.Overlaps(other.CIDR) && !nested(a, other) {
+				issues = append(issues, Issue{SeverityError, a.CIDR, fmt.Sprintf("overlaps allocation %v %s", other.CIDR, other.Name)})
+			}
+		}
+	}
+	for _, m := range p.Merges() {
+		issues = append(issues, Issue{SeverityInfo, m.Parent, fmt.Sprintf("%s siblings %v and %v could be merged", m.Status, m.Lower, m.Upper)})
+	}
+	return issues
+}
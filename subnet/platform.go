@@ -0,0 +1,52 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// PlatformRange is an address range a platform uses by default. Hosts running the platform route the range to it, so
+// networks overlapping it become unreachable from them, the classic VPN that broke because Docker uses that range.
+type PlatformRange struct {
+	Prefix   netip.Prefix `json:"prefix"`
+	Platform string       `json:"platform"`
+	Use      string       `json:"use,omitempty"`
+}
+
+// PlatformRanges lists the default ranges of common container, virtualization, and VPN platforms.
+var PlatformRanges = []PlatformRange{
+	{netip.MustParsePrefix("172.17.0.0/16"), "Docker", "default bridge network"},
+	{netip.MustParsePrefix("10.88.0.0/16"), "Podman", "default network"},
+	{netip.MustParsePrefix("10.96.0.0/12"), "Kubernetes", "kubeadm default service CIDR"},
+	{netip.MustParsePrefix("10.244.0.0/16"), "Kubernetes", "Flannel default pod CIDR"},
+	{netip.MustParsePrefix("10.42.0.0/16"), "k3s", "default pod CIDR"},
+	{netip.MustParsePrefix("10.43.0.0/16"), "k3s", "default service CIDR"},
+	{netip.MustParsePrefix("10.100.0.0/16"), "Amazon EKS", "service CIDR"},
+	{netip.MustParsePrefix("172.20.0.0/16"), "Amazon EKS", "service CIDR when the VPC is in 10.0.0.0/8"},
+	{netip.MustParsePrefix("192.168.49.0/24"), "minikube", "docker driver network"},
+	{netip.MustParsePrefix("192.168.56.0/21"), "VirtualBox", "host-only networks"},
+	{netip.MustParsePrefix("10.0.2.0/24"), "VirtualBox", "NAT network"},
+	{netip.MustParsePrefix("192.168.122.0/24"), "libvirt", "default network"},
+	{netip.MustParsePrefix("172.16.0.0/12"), "WSL 2", "NAT network range"},
+	{netip.MustParsePrefix("100.64.0.0/10"), "Tailscale", "CGNAT node addresses"},
+	{netip.MustParsePrefix("fd7a:115c:a1e0::/48"), "Tailscale", "IPv6 node addresses"},
+}
+
+// String returns the range followed by the platform using it, such as 172.17.0.0/16 (Docker default bridge network).
+func (r PlatformRange) String() string {
+	return fmt.Sprintf("%v (%s %s)", r.Prefix, r.Platform, r.Use)
+}
+
+// PlatformCollisions returns the ranges overlapping prefix p.
+func PlatformCollisions(p netip.Prefix, ranges []PlatformRange) []PlatformRange {
+	var found []PlatformRange
+	for _, r := range ranges {
+		if r.Prefix.Overlaps(p) {
+			found = append(found, r)
+		}
+	}
+	return found
+}
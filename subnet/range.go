@@ -0,0 +1,111 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strings"
+)
+
+// Range is an inclusive range of IP addresses of a single address family.
+type Range struct {
+	From netip.Addr `json:"from"`
+	To   netip.Addr `json:"to"`
+}
+
+// String returns the range in "from-to" notation.
+func (r Range) String() string {
+	return fmt.Sprintf("%s-%s", r.From, r.To)
+}
+
+// lastAddr returns the highest address contained in prefix p.
+func lastAddr(p netip.Prefix) netip.Addr {
+	if !p.IsValid() {
+		return netip.Addr{}
+	}
+	a := p.Masked().Addr()
+	last, _ := u128(a).or(hostMask(p.Bits(), a.BitLen())).addr(a.BitLen())
+	return last
+}
+
+// addrInt returns the address as an unsigned integer.
+func addrInt(a netip.Addr) *big.Int {
+	return u128(a).big()
+}
+
+// intAddr returns the address of the given bit length, 32 or 128, with the integer value i.
+// returns false if i doesn't fit in an address of that length.
+func intAddr(i *big.Int, bitLen int) (netip.Addr, bool) {
+	u, ok := u128FromBig(i)
+	if !ok {
+		return netip.Addr{}, false
+	}
+	return u.addr(bitLen)
+}
+
+// AddToAddr returns the address n addresses after a, or before it if n is negative.
+// returns an error instead of wrapping around if the result falls outside of a's address family.
+func AddToAddr(a netip.Addr, n *big.Int) (netip.Addr, error) {
+	var sum uint128
+	ok := false
+	if delta, fits := u128FromBig(new(big.Int).Abs(n)); fits {
+		if n.Sign() < 0 {
+			sum, ok = u128(a).sub(delta)
+		} else {
+			sum, ok = u128(a).add(delta)
+		}
+	}
+	if ok {
+		if addr, fits := sum.addr(a.BitLen()); fits {
+			return addr, nil
+		}
+	}
+	family := "IPv4"
+	if a.Is6() {
+		family = "IPv6"
+	}
+	return netip.Addr{}, fmt.Errorf("adding %v to %v falls outside of the %s address space", n, a, family)
+}
+
+// RangeOf returns the range of addresses contained in prefix p.
+func RangeOf(p netip.Prefix) Range {
+	return Range{From: p.Masked().Addr(), To: lastAddr(p)}
+}
+
+// ParseRange parses a range in "from-to" notation, a CIDR, or a single IP address.
+// returns an error if the addresses are invalid, of different families, or out of order.
+func ParseRange(s string) (Range, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return Range{}, err
+		}
+		return RangeOf(p), nil
+	}
+
+	from, to, found := strings.Cut(s, "-")
+	start, err := netip.ParseAddr(strings.TrimSpace(from))
+	if err != nil {
+		return Range{}, err
+	}
+	if !found {
+		return Range{From: start.Unmap(), To: start.Unmap()}, nil
+	}
+	end, err := netip.ParseAddr(strings.TrimSpace(to))
+	if err != nil {
+		return Range{}, err
+	}
+
+	r := Range{From: start.Unmap(), To: end.Unmap()}
+	if r.From.Is4() != r.To.Is4() {
+		return Range{}, fmt.Errorf("range %q mixes IPv4 and IPv6 addresses", s)
+	}
+	if r.To.Less(r.From) {
+		return Range{}, fmt.Errorf("range %q ends before it starts", s)
+	}
+	return r, nil
+}
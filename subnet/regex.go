@@ -0,0 +1,151 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Regex returns a regular expression matching the dotted-decimal form of every IPv4 address in range r.
+// the expression is not anchored, so callers may wrap it as their tooling requires.
+func Regex(r Range) (string, error) {
+	if !r.From.Is4() || !r.To.Is4() {
+		return "", errors.New("regular expressions can only be generated for IPv4 ranges")
+	}
+	from, to := r.From.As4(), r.To.As4()
+	return octetsRegex(from[:], to[:]), nil
+}
+
+// octetsRegex builds a pattern matching every dotted octet sequence between from and to, inclusive.
+// both slices must have the same length.
+func octetsRegex(from, to []byte) string {
+	if len(from) == 0 {
+		return ""
+	}
+	if len(from) == 1 {
+		return numberRegex(int(from[0]), int(to[0]))
+	}
+	if from[0] == to[0] {
+		return fmt.Sprintf(`%d\.%s`, from[0], octetsRegex(from[1:], to[1:]))
+	}
+
+	var alts []string
+	lo, hi := int(from[0]), int(to[0])
+
+	// the first and last values of the leading octet only need their own branch when the remaining octets are not a full range
+	if !allBytes(from[1:], 0x00) {
+		alts = append(alts, fmt.Sprintf(`%d\.%s`, from[0], octetsRegex(from[1:], fill(len(from)-1, 0xFF))))
+		lo++
+	}
+	tail := ""
+	if !allBytes(to[1:], 0xFF) {
+		tail = fmt.Sprintf(`%d\.%s`, to[0], octetsRegex(fill(len(to)-1, 0x00), to[1:]))
+		hi--
+	}
+	if lo <= hi {
+		alts = append(alts, fmt.Sprintf(`%s\.%s`, numberRegex(lo, hi), octetsRegex(fill(len(from)-1, 0x00), fill(len(to)-1, 0xFF))))
+	}
+	if tail != "" {
+		alts = append(alts, tail)
+	}
+	return group(alts)
+}
+
+// numberRegex returns a pattern matching the decimal integers from lo through hi without leading zeros.
+func numberRegex(lo, hi int) string {
+	var alts []string
+	for digits, min, max := 1, 0, 9; min <= hi; digits, min, max = digits+1, max+1, max*10+9 {
+		a, b := lo, hi
+		if a < min {
+			a = min
+		}
+		if b > max {
+			b = max
+		}
+		if a <= b {
+			alts = append(alts, sameLengthRegex(fmt.Sprint(a), fmt.Sprint(b))...)
+		}
+	}
+	return group(alts)
+}
+
+// sameLengthRegex returns the alternatives matching every number from a through b, which must have the same number of digits.
+func sameLengthRegex(a, b string) []string {
+	if a == b {
+		return []string{a}
+	}
+	if len(a) == 1 {
+		return []string{digitClass(a[0], b[0])}
+	}
+	if a[0] == b[0] {
+		return []string{string(a[0]) + group(sameLengthRegex(a[1:], b[1:]))}
+	}
+
+	var alts []string
+	lo, hi := a[0], b[0]
+	rest := len(a) - 1
+	if strings.Trim(a[1:], "0") != "" {
+		alts = append(alts, string(a[0])+group(sameLengthRegex(a[1:], strings.Repeat("9", rest))))
+		lo++
+	}
+	tail := ""
+	if strings.Trim(b[1:], "9") != "" {
+		tail = string(b[0]) + group(sameLengthRegex(strings.Repeat("0", rest), b[1:]))
+		hi--
+	}
+	if lo <= hi {
+		anyDigits := `\d`
+		if rest > 1 {
+			anyDigits = fmt.Sprintf(`\d{%d}`, rest)
+		}
+		alts = append(alts, digitClass(lo, hi)+anyDigits)
+	}
+	if tail != "" {
+		alts = append(alts, tail)
+	}
+	return alts
+}
+
+// digitClass returns a pattern matching a single digit from lo through hi.
+func digitClass(lo, hi byte) string {
+	switch {
+	case lo == hi:
+		return string(lo)
+	case lo == '0' && hi == '9':
+		return `\d`
+	case hi == lo+1:
+		return fmt.Sprintf("[%c%c]", lo, hi)
+	default:
+		return fmt.Sprintf("[%c-%c]", lo, hi)
+	}
+}
+
+// group joins alternatives into a non-capturing group, omitting the group when there is only one.
+func group(alts []string) string {
+	if len(alts) == 1 {
+		return alts[0]
+	}
+	return "(?:" + strings.Join(alts, "|") + ")"
+}
+
+// allBytes reports whether every byte in b equals v.
+func allBytes(b []byte, v byte) bool {
+	for _, x := range b {
+		if x != v {
+			return false
+		}
+	}
+	return true
+}
+
+// fill returns a slice of n bytes set to v.
+func fill(n int, v byte) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = v
+	}
+	return b
+}
@@ -0,0 +1,88 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// ReverseStyles lists the naming conventions accepted by ReverseDelegationFor for RFC 2317 zone labels: "rfc" writes
+// the first address and prefix length as in the RFC, e.g. 64/26, "dash" replaces the slash with a dash, e.g. 64-26, for
+// software that rejects slashes, and "range" uses the first and last addresses, e.g. 64-127.
+var ReverseStyles = []string{"rfc", "dash", "range"}
+
+// CNAME is a record the parent reverse zone needs to point a PTR name into a delegated classless zone.
+type CNAME struct {
+	Name   string `json:"name"`
+	Target string `json:"target"`
+}
+
+// ReverseDelegation holds the reverse zones for an IPv4 network and, for networks smaller than a /24, the CNAME
+// records the parent zone needs to delegate them as described in RFC 2317.
+type ReverseDelegation struct {
+	Network netip.Prefix `json:"network"`
+	Parent  string       `json:"parent,omitempty"`
+	Zones   []string     `json:"zones"`
+	CNAMEs  []CNAME      `json:"cnames,omitempty"`
+}
+
+// reverseName returns the in-addr.arpa name for the first octets of a.
+func reverseName(a netip.Addr, octets int) string {
+	b := a.As4()
+	name := "in-addr.arpa"
+	for i := 0; i < octets; i++ {
+		name = fmt.Sprintf("%d.%s", b[i], name)
+	}
+	return name
+}
+
+// ReverseDelegationFor returns the reverse zones for an IPv4 network. Networks of /24 or larger are split into the
+// /8, /16, or /24 zones they cover, so no CNAMEs are needed. Smaller networks get a single classless zone below their
+// /24, named according to style, and a CNAME for every address pointing into it.
+// returns an error if p isn't IPv4 or style isn't one of ReverseStyles.
+func ReverseDelegationFor(p netip.Prefix, style string) (ReverseDelegation, error) {
+	if !p.Addr().Is4() {
+		return ReverseDelegation{}, fmt.Errorf("%v is not an IPv4 network, RFC 2317 delegation only applies to in-addr.arpa", p)
+	}
+	p = p.Masked()
+	d := ReverseDelegation{Network: p}
+
+	if p.Bits() <= 24 {
+		// delegate on the next octet boundary, e.g. a /20 becomes sixteen /24 zones
+		octets := (p.Bits() + 7) / 8
+		bits := octets * 8
+		if bits == 0 {
+			return d, fmt.Errorf("%v covers the whole in-addr.arpa zone", p)
+		}
+		for a := p.Addr(); a.IsValid() && p.Contains(a); {
+			zone := netip.PrefixFrom(a, bits)
+			d.Zones = append(d.Zones, reverseName(a, octets))
+			a = lastAddr(zone).Next()
+		}
+		return d, nil
+	}
+
+	first := p.Addr().As4()[3]
+	last := lastAddr(p).As4()[3]
+	var label string
+	switch style {
+	case "", "rfc":
+		label = fmt.Sprintf("%d/%d", first, p.Bits())
+	case "dash":
+		label = fmt.Sprintf("%d-%d", first, p.Bits())
+	case "range":
+		label = fmt.Sprintf("%d-%d", first, last)
+	default:
+		return d, fmt.Errorf("unknown reverse zone style %q, expected one of %v", style, ReverseStyles)
+	}
+
+	d.Parent = reverseName(p.Addr(), 3)
+	zone := label + "." + d.Parent
+	d.Zones = []string{zone}
+	for i := int(first); i <= int(last); i++ {
+		d.CNAMEs = append(d.CNAMEs, CNAME{Name: fmt.Sprintf("%d.%s.", i, d.Parent), Target: fmt.Sprintf("%d.%s.", i, zone)})
+	}
+	return d, nil
+}
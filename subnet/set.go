@@ -0,0 +1,333 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"container/heap"
+	"math/big"
+	"net/netip"
+	"sort"
+)
+
+// Overlap describes two prefixes that share addresses. Because prefixes are aligned blocks, one always contains the
+// other, so Outer is the larger of the two.
+type Overlap struct {
+	Outer netip.Prefix `json:"outer"`
+	Inner netip.Prefix `json:"inner"`
+}
+
+// Relations between two prefixes, as returned by Relate.
+const (
+	RelationEqual    = "equal"
+	RelationContains = "contains"
+	RelationWithin   = "within"
+	RelationDisjoint = ""
+)
+
+// Relate returns how prefix a relates to prefix b: equal, containing it, within it, or disjoint. Prefixes can't partly
+// overlap, so these are the only possibilities.
+func Relate(a, b netip.Prefix) string {
+	a, b = a.Masked(), b.Masked()
+	switch {
+	case a == b:
+		return RelationEqual
+	case !a.Overlaps(b):
+		return RelationDisjoint
+	case a.Bits() < b.Bits():
+		return RelationContains
+	default:
+		return RelationWithin
+	}
+}
+
+// SortPrefixes sorts prefixes in place by address family, address, and then mask length.
+func SortPrefixes(prefixes []netip.Prefix) {
+	sort.Slice(prefixes, func(i, j int) bool {
+		a, b := prefixes[i], prefixes[j]
+		if a.Addr().Is4() != b.Addr().Is4() {
+			return a.Addr().Is4()
+		}
+		if c := a.Addr().Compare(b.Addr()); c != 0 {
+			return c < 0
+		}
+		return a.Bits() < b.Bits()
+	})
+}
+
+// Aggregate returns the smallest set of prefixes covering exactly the same addresses as the provided prefixes.
+// duplicates and prefixes contained in other prefixes are dropped, and adjacent sibling prefixes are merged.
+func Aggregate(prefixes []netip.Prefix) []netip.Prefix {
+	var out []netip.Prefix
+	for _, p := range prefixes {
+		out = append(out, p.Masked())
+	}
+	SortPrefixes(out)
+
+	// a stack based merge: each new prefix is either covered by the top of the stack, merged with it, or pushed
+	var stack []netip.Prefix
+	for _, p := range out {
+		if len(stack) > 0 && stack[len(stack)-1].Overlaps(p) {
+			continue
+		}
+		stack = append(stack, p)
+		for len(stack) > 1 {
+			a, b := stack[len(stack)-2], stack[len(stack)-1]
+			merged, ok := mergeSiblings(a, b)
+			if !ok {
+				break
+			}
+			stack = append(stack[:len(stack)-2], merged)
+		}
+	}
+	return stack
+}
+
+// AggregateBudget returns at most max prefixes covering all of the provided prefixes, in address order. When the exact
+// aggregate doesn't fit, neighboring prefixes are repeatedly replaced by the smallest prefix covering both, choosing the
+// pair that adds the fewest addresses. A max below the number of address families present is raised to it, as one
+// prefix can't cover both.
+// returns the prefixes and the number of addresses they cover that weren't in the provided prefixes.
+func AggregateBudget(prefixes []netip.Prefix, max int) ([]netip.Prefix, *big.Int) {
+	out := Aggregate(prefixes)
+	if max <= 0 || len(out) <= max {
+		return out, new(big.Int)
+	}
+	covered := Coverage(out)
+
+	// the prefixes are kept in a linked list so a merge doesn't shift the rest, and the candidate merges in a heap,
+	// indexed by their parent so a merge inside a parent can lower its cost without rescanning the list
+	var first *mergeNode
+	for i := len(out) - 1; i >= 0; i-- {
+		first = &mergeNode{prefix: out[i], next: first}
+		if first.next != nil {
+			first.next.prev = first
+		}
+	}
+	count := len(out)
+	candidates := &mergeHeap{}
+	byParent := map[netip.Prefix]*mergeCandidate{}
+	consider := func(left, right *mergeNode) {
+		if left == nil || right == nil {
+			return
+		}
+		parent, ok := commonPrefix(left.prefix, right.prefix)
+		if !ok {
+			return
+		}
+		// the parent may swallow more prefixes on either side, which are contiguous as the list is sorted
+		extra := AddressCount(parent)
+		for n := left; n != nil && parent.Contains(n.prefix.Addr()); n = n.prev {
+			extra.Sub(extra, AddressCount(n.prefix))
+		}
+		for n := right; n != nil && parent.Contains(n.prefix.Addr()); n = n.next {
+			extra.Sub(extra, AddressCount(n.prefix))
+		}
+		c := &mergeCandidate{left: left, right: right, parent: parent, extra: extra}
+		byParent[parent] = c
+		heap.Push(candidates, c)
+	}
+	// replace swaps the run of nodes from a to b for a single node holding p.
+	replace := func(a, b *mergeNode, p netip.Prefix) *mergeNode {
+		n := &mergeNode{prefix: p, prev: a.prev, next: b.next}
+		for m := a; m != b.next; m = m.next {
+			m.removed = true
+			count--
+		}
+		if n.prev != nil {
+			n.prev.next = n
+		} else {
+			first = n
+		}
+		if n.next != nil {
+			n.next.prev = n
+		}
+		count++
+		return n
+	}
+	for n := first; n != nil; n = n.next {
+		consider(n, n.next)
+	}
+
+	for count > max && candidates.Len() > 0 {
+		c := heap.Pop(candidates).(*mergeCandidate)
+		if !c.live() || byParent[c.parent] != c {
+			continue
+		}
+		delete(byParent, c.parent)
+		a, b := c.left, c.right
+		for a.prev != nil && c.parent.Contains(a.prev.prefix.Addr()) {
+			a = a.prev
+		}
+		for b.next != nil && c.parent.Contains(b.next.prefix.Addr()) {
+			b = b.next
+		}
+		n := replace(a, b, c.parent)
+
+		// every merge whose parent contains this one now adds that many fewer addresses
+		for bits := c.parent.Bits() - 1; bits >= 0; bits-- {
+			q, _ := c.parent.Addr().Prefix(bits)
+			if ancestor, ok := byParent[q]; ok && ancestor.live() {
+				ancestor.extra.Sub(ancestor.extra, c.extra)
+				heap.Fix(candidates, ancestor.index)
+			}
+		}
+
+		// the parent may complete a block with its neighbor, which Aggregate would merge
+		for {
+			if parent, ok := mergeSiblings(n.prefix, nextPrefix(n)); ok {
+				n = replace(n, n.next, parent)
+			} else if parent, ok := mergeSiblings(prevPrefix(n), n.prefix); ok {
+				n = replace(n.prev, n, parent)
+			} else {
+				break
+			}
+		}
+		consider(n.prev, n)
+		consider(n, n.next)
+	}
+
+	out = out[:0]
+	for n := first; n != nil; n = n.next {
+		out = append(out, n.prefix)
+	}
+	extra := Coverage(out)
+	return out, extra.Sub(extra, covered)
+}
+
+// mergeNode is a prefix in the list AggregateBudget merges.
+type mergeNode struct {
+	prefix     netip.Prefix
+	prev, next *mergeNode
+	removed    bool
+}
+
+// prevPrefix returns the prefix before n, or the zero prefix at the start of the list.
+func prevPrefix(n *mergeNode) netip.Prefix {
+	if n.prev == nil {
+		return netip.Prefix{}
+	}
+	return n.prev.prefix
+}
+
+// nextPrefix returns the prefix after n, or the zero prefix at the end of the list.
+func nextPrefix(n *mergeNode) netip.Prefix {
+	if n.next == nil {
+		return netip.Prefix{}
+	}
+	return n.next.prefix
+}
+
+// mergeCandidate is a pair of neighboring prefixes that AggregateBudget could replace by their smallest common parent,
+// and the number of addresses doing so would add.
+type mergeCandidate struct {
+	left, right *mergeNode
+	parent      netip.Prefix
+	extra       *big.Int
+	index       int // position in the heap
+}
+
+// live reports whether the candidate's prefixes are still neighbors in the list.
+func (c *mergeCandidate) live() bool {
+	return !c.left.removed && !c.right.removed && c.left.next == c.right
+}
+
+// mergeHeap is a min-heap of merge candidates, cheapest first and then in address order.
+type mergeHeap []*mergeCandidate
+
+func (h mergeHeap) Len() int { return len(h) }
+
+func (h mergeHeap) Less(i, j int) bool {
+	if c := h[i].extra.Cmp(h[j].extra); c != 0 {
+		return c < 0
+	}
+	return h[i].left.prefix.Addr().Less(h[j].left.prefix.Addr())
+}
+
+func (h mergeHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+
+func (h *mergeHeap) Push(x any) {
+	c := x.(*mergeCandidate)
+	c.index = len(*h)
+	*h = append(*h, c)
+}
+
+func (h *mergeHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// commonPrefix returns the smallest prefix containing both a and b, or false if they are from different families.
+func commonPrefix(a, b netip.Prefix) (netip.Prefix, bool) {
+	if a.Addr().Is4() != b.Addr().Is4() {
+		return netip.Prefix{}, false
+	}
+	bits := min(a.Bits(), b.Bits())
+	for bits > 0 {
+		p, _ := a.Addr().Prefix(bits)
+		if p.Contains(b.Addr()) {
+			return p, true
+		}
+		bits--
+	}
+	return netip.PrefixFrom(a.Addr(), 0).Masked(), true
+}
+
+// mergeSiblings returns the parent prefix of a and b if they are the two halves of the same block.
+func mergeSiblings(a, b netip.Prefix) (netip.Prefix, bool) {
+	if a.Bits() != b.Bits() || a.Bits() == 0 || a.Addr().Is4() != b.Addr().Is4() {
+		return netip.Prefix{}, false
+	}
+	parent, _ := a.Addr().Prefix(a.Bits() - 1)
+	if !parent.Contains(b.Addr()) || a.Addr() == b.Addr() || parent.Addr() != a.Addr() {
+		return netip.Prefix{}, false
+	}
+	return parent, true
+}
+
+// FindOverlaps returns every pair of prefixes that share addresses.
+func FindOverlaps(prefixes []netip.Prefix) []Overlap {
+	sorted := make([]netip.Prefix, len(prefixes))
+	for i, p := range prefixes {
+		sorted[i] = p.Masked()
+	}
+	SortPrefixes(sorted)
+
+	// after sorting, a prefix can only be contained by prefixes that come before it, so look each one up among the
+	// prefixes already seen. the trie counts duplicates, which overlap each other.
+	var overlaps []Overlap
+	var seen Trie[int]
+	for _, p := range sorted {
+		count := 0
+		seen.Supernets(p, func(outer netip.Prefix, n int) bool {
+			if outer == p {
+				count = n
+			}
+			for i := 0; i < n; i++ {
+				overlaps = append(overlaps, Overlap{Outer: outer, Inner: p})
+			}
+			return true
+		})
+		seen.Insert(p, count+1)
+	}
+	return overlaps
+}
+
+// AddressCount returns the number of addresses contained in prefix p.
+func AddressCount(p netip.Prefix) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(p.Addr().BitLen()-p.Bits()))
+}
+
+// Coverage returns the number of distinct addresses covered by the provided prefixes.
+func Coverage(prefixes []netip.Prefix) *big.Int {
+	total := new(big.Int)
+	for _, p := range Aggregate(prefixes) {
+		total.Add(total, AddressCount(p))
+	}
+	return total
+}
@@ -0,0 +1,248 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"math/big"
+	"math/rand"
+	"net/netip"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// testUniverse is the address space the brute force tests draw prefixes from, small enough to enumerate.
+var testUniverse = netip.MustParsePrefix("10.0.0.0/24")
+
+// randomPrefixes returns n random prefixes inside testUniverse, some of them with host bits set.
+func randomPrefixes(r *rand.Rand, n int) []netip.Prefix {
+	base := testUniverse.Addr().As4()
+	prefixes := make([]netip.Prefix, n)
+	for i := range prefixes {
+		a := base
+		a[3] = byte(r.Intn(256))
+		prefixes[i] = netip.PrefixFrom(netip.AddrFrom4(a), testUniverse.Bits()+r.Intn(9))
+	}
+	return prefixes
+}
+
+// addrSet returns which addresses of testUniverse the prefixes cover, by the last octet.
+func addrSet(prefixes []netip.Prefix) [256]bool {
+	var set [256]bool
+	for i := range set {
+		a := testUniverse.Addr().As4()
+		a[3] = byte(i)
+		for _, p := range prefixes {
+			if p.Masked().Contains(netip.AddrFrom4(a)) {
+				set[i] = true
+				break
+			}
+		}
+	}
+	return set
+}
+
+// naiveCover returns the fewest prefixes covering exactly the addresses in set, in address order, by splitting p in
+// half until each half is either entirely in the set or entirely out of it.
+func naiveCover(p netip.Prefix, set [256]bool) []netip.Prefix {
+	first := int(p.Addr().As4()[3])
+	size := 1 << (32 - p.Bits())
+	all, none := true, true
+	for i := first; i < first+size; i++ {
+		all = all && set[i]
+		none = none && !set[i]
+	}
+	switch {
+	case all:
+		return []netip.Prefix{p}
+	case none:
+		return nil
+	}
+	lower := netip.PrefixFrom(p.Addr(), p.Bits()+1)
+	upper := netip.PrefixFrom(lastAddr(lower).Next(), p.Bits()+1)
+	return append(naiveCover(lower, set), naiveCover(upper, set)...)
+}
+
+// mustPrefixes parses each of s as a prefix.
+func mustPrefixes(s ...string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, len(s))
+	for i, p := range s {
+		prefixes[i] = netip.MustParsePrefix(p)
+	}
+	return prefixes
+}
+
+func TestAggregate(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefixes []netip.Prefix
+		want     []netip.Prefix
+	}{
+		{"empty", nil, nil},
+		{"siblings", mustPrefixes("10.0.0.0/25", "10.0.0.128/25"), mustPrefixes("10.0.0.0/24")},
+		{"cascade", mustPrefixes("10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/25"), mustPrefixes("10.0.0.0/24")},
+		{"not siblings", mustPrefixes("10.0.0.128/25", "10.0.1.0/25"), mustPrefixes("10.0.0.128/25", "10.0.1.0/25")},
+		{"contained", mustPrefixes("10.0.0.0/16", "10.0.5.0/24", "10.0.0.0/16"), mustPrefixes("10.0.0.0/16")},
+		{"host bits", mustPrefixes("10.0.0.1/24"), mustPrefixes("10.0.0.0/24")},
+		{"whole space", mustPrefixes("0.0.0.0/1", "128.0.0.0/1"), mustPrefixes("0.0.0.0/0")},
+		{"mixed families", mustPrefixes("2001:db8::/33", "10.0.0.0/8", "2001:db8:8000::/33"),
+			mustPrefixes("10.0.0.0/8", "2001:db8::/32")},
+		{"ipv6 hosts", mustPrefixes("2001:db8::/128", "2001:db8::1/128"), mustPrefixes("2001:db8::/127")},
+	}
+	for _, tt := range tests {
+		if got := Aggregate(tt.prefixes); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Aggregate(%v) = %v, want %v", tt.name, tt.prefixes, got, tt.want)
+		}
+	}
+}
+
+func TestAggregateBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		prefixes := randomPrefixes(r, 1+r.Intn(12))
+		want := naiveCover(testUniverse, addrSet(prefixes))
+		if got := Aggregate(prefixes); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Aggregate(%v) = %v, want %v", prefixes, got, want)
+		}
+		count := 0
+		for _, covered := range addrSet(prefixes) {
+			if covered {
+				count++
+			}
+		}
+		if got := Coverage(prefixes); got.Cmp(big.NewInt(int64(count))) != 0 {
+			t.Fatalf("Coverage(%v) = %v, want %d", prefixes, got, count)
+		}
+	}
+}
+
+func TestAggregateBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		prefixes  []netip.Prefix
+		max       int
+		want      []netip.Prefix
+		wantExtra int64
+	}{
+		{"fits", mustPrefixes("10.0.0.0/24", "10.0.2.0/24"), 2, mustPrefixes("10.0.0.0/24", "10.0.2.0/24"), 0},
+		{"no limit", mustPrefixes("10.0.0.0/24", "10.0.2.0/24"), 0, mustPrefixes("10.0.0.0/24", "10.0.2.0/24"), 0},
+		{"merge", mustPrefixes("10.0.0.0/24", "10.0.2.0/24"), 1, mustPrefixes("10.0.0.0/22"), 512},
+		{"cheapest pair", mustPrefixes("10.0.0.0/24", "10.0.1.0/25", "10.0.8.0/24"), 2,
+			mustPrefixes("10.0.0.0/23", "10.0.8.0/24"), 128},
+		{"one per family", mustPrefixes("10.0.0.0/24", "2001:db8::/64"), 1,
+			mustPrefixes("10.0.0.0/24", "2001:db8::/64"), 0},
+	}
+	for _, tt := range tests {
+		got, extra := AggregateBudget(tt.prefixes, tt.max)
+		if !reflect.DeepEqual(got, tt.want) || extra.Cmp(big.NewInt(tt.wantExtra)) != 0 {
+			t.Errorf("%s: AggregateBudget(%v, %d) = %v, %v, want %v, %d", tt.name, tt.prefixes, tt.max, got, extra,
+				tt.want, tt.wantExtra)
+		}
+	}
+}
+
+func TestAggregateBudgetBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 300; i++ {
+		prefixes := randomPrefixes(r, 1+r.Intn(12))
+		max := 1 + r.Intn(4)
+		got, extra := AggregateBudget(prefixes, max)
+		if len(got) > max {
+			t.Fatalf("AggregateBudget(%v, %d) returned %d prefixes: %v", prefixes, max, len(got), got)
+		}
+		in, out := addrSet(prefixes), addrSet(got)
+		added := 0
+		for a := range in {
+			if in[a] && !out[a] {
+				t.Fatalf("AggregateBudget(%v, %d) = %v, which doesn't cover address %d", prefixes, max, got, a)
+			}
+			if out[a] && !in[a] {
+				added++
+			}
+		}
+		if extra.Cmp(big.NewInt(int64(added))) != 0 {
+			t.Fatalf("AggregateBudget(%v, %d) = %v with %v extra addresses, want %d", prefixes, max, got, extra, added)
+		}
+		if !reflect.DeepEqual(got, Aggregate(got)) {
+			t.Fatalf("AggregateBudget(%v, %d) = %v, which isn't aggregated", prefixes, max, got)
+		}
+	}
+}
+
+func TestExclude(t *testing.T) {
+	tests := []struct {
+		name     string
+		supernet netip.Prefix
+		excluded []netip.Prefix
+		want     []netip.Prefix
+	}{
+		{"nothing", netip.MustParsePrefix("10.0.0.0/24"), nil, mustPrefixes("10.0.0.0/24")},
+		{"everything", netip.MustParsePrefix("10.0.0.0/24"), mustPrefixes("10.0.0.0/16"), nil},
+		{"first half", netip.MustParsePrefix("10.0.0.0/24"), mustPrefixes("10.0.0.0/25"), mustPrefixes("10.0.0.128/25")},
+		{"one host", netip.MustParsePrefix("10.0.0.0/30"), mustPrefixes("10.0.0.1/32"),
+			mustPrefixes("10.0.0.0/32", "10.0.0.2/31")},
+		{"outside", netip.MustParsePrefix("10.0.0.0/24"), mustPrefixes("10.0.1.0/24", "2001:db8::/32"),
+			mustPrefixes("10.0.0.0/24")},
+		{"whole space", netip.MustParsePrefix("0.0.0.0/0"), mustPrefixes("0.0.0.0/1"), mustPrefixes("128.0.0.0/1")},
+		{"ipv6", netip.MustParsePrefix("2001:db8::/126"), mustPrefixes("2001:db8::3/128"),
+			mustPrefixes("2001:db8::/127", "2001:db8::2/128")},
+	}
+	for _, tt := range tests {
+		if got := Exclude(tt.supernet, tt.excluded); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Exclude(%v, %v) = %v, want %v", tt.name, tt.supernet, tt.excluded, got, tt.want)
+		}
+	}
+}
+
+func TestExcludeBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for i := 0; i < 500; i++ {
+		excluded := randomPrefixes(r, r.Intn(8))
+		set := addrSet(excluded)
+		for a := range set {
+			set[a] = !set[a]
+		}
+		want := naiveCover(testUniverse, set)
+		if got := Exclude(testUniverse, excluded); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Exclude(%v, %v) = %v, want %v", testUniverse, excluded, got, want)
+		}
+	}
+}
+
+func TestFindOverlapsBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	for i := 0; i < 300; i++ {
+		prefixes := randomPrefixes(r, r.Intn(10))
+		var want []Overlap
+		for a := range prefixes {
+			for b := a + 1; b < len(prefixes); b++ {
+				p, q := prefixes[a].Masked(), prefixes[b].Masked()
+				if !p.Overlaps(q) {
+					continue
+				}
+				if q.Bits() < p.Bits() {
+					p, q = q, p
+				}
+				want = append(want, Overlap{Outer: p, Inner: q})
+			}
+		}
+		got := FindOverlaps(prefixes)
+		sortOverlaps(got)
+		sortOverlaps(want)
+		if len(got) != len(want) || len(got) > 0 && !reflect.DeepEqual(got, want) {
+			t.Fatalf("FindOverlaps(%v) = %v, want %v", prefixes, got, want)
+		}
+	}
+}
+
+// sortOverlaps sorts overlaps by their outer and then inner prefixes, so results can be compared regardless of order.
+func sortOverlaps(overlaps []Overlap) {
+	sort.Slice(overlaps, func(i, j int) bool {
+		a, b := overlaps[i], overlaps[j]
+		if a.Outer != b.Outer {
+			return a.Outer.String() < b.Outer.String()
+		}
+		return a.Inner.String() < b.Inner.String()
+	})
+}
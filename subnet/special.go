@@ -0,0 +1,94 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+
+// Package subnet contains the address math used by subnetCalc.
+package subnet
+
+import "net/netip"
+
+// Context identifies where a prefix is intended to be used.
+type Context string
+
+const (
+	// ContextWAN is used for prefixes that will be announced or routed on the public internet.
+	ContextWAN Context = "wan"
+	// ContextLAN is used for prefixes that will be assigned to hosts on a local network.
+	ContextLAN Context = "lan"
+)
+
+// SpecialBlock describes an IANA special-purpose address block.
+type SpecialBlock struct {
+	Prefix    netip.Prefix
+	Name      string
+	RFC       string
+	WAN       bool // block may appear on the public internet
+	LANUsable bool // block may be assigned to hosts on a local network
+}
+
+// SpecialBlocks lists the special-purpose address blocks subnetCalc knows about.
+var SpecialBlocks = []SpecialBlock{
+	{netip.MustParsePrefix("0.0.0.0/8"), "this network", "RFC 791", false, false},
+	{netip.MustParsePrefix("10.0.0.0/8"), "private-use", "RFC 1918", false, true},
+	{netip.MustParsePrefix("100.64.0.0/10"), "shared address space", "RFC 6598", false, true},
+	{netip.MustParsePrefix("127.0.0.0/8"), "loopback", "RFC 1122", false, false},
+	{netip.MustParsePrefix("169.254.0.0/16"), "link-local", "RFC 3927", false, true},
+	{netip.MustParsePrefix("172.16.0.0/12"), "private-use", "RFC 1918", false, true},
+	{netip.MustParsePrefix("192.0.0.0/24"), "IETF protocol assignments", "RFC 6890", false, true},
+	{netip.MustParsePrefix("192.0.2.0/24"), "documentation (TEST-NET-1)", "RFC 5737", false, true},
+	{netip.MustParsePrefix("192.168.0.0/16"), "private-use", "RFC 1918", false, true},
+	{netip.MustParsePrefix("198.18.0.0/15"), "benchmarking", "RFC 2544", false, true},
+	{netip.MustParsePrefix("198.51.100.0/24"), "documentation (TEST-NET-2)", "RFC 5737", false, true},
+	{netip.MustParsePrefix("203.0.113.0/24"), "documentation (TEST-NET-3)", "RFC 5737", false, true},
+	{netip.MustParsePrefix("224.0.0.0/4"), "multicast", "RFC 5771", true, false},
+	{netip.MustParsePrefix("240.0.0.0/4"), "reserved (class E)", "RFC 1112", false, false},
+	{netip.MustParsePrefix("255.255.255.255/32"), "limited broadcast", "RFC 919", false, false},
+	{netip.MustParsePrefix("::/128"), "unspecified address", "RFC 4291", false, false},
+	{netip.MustParsePrefix("::1/128"), "loopback", "RFC 4291", false, false},
+	{netip.MustParsePrefix("::ffff:0:0/96"), "IPv4-mapped address", "RFC 4291", false, false},
+	{netip.MustParsePrefix("100::/64"), "discard-only", "RFC 6666", false, false},
+	{netip.MustParsePrefix("2001:db8::/32"), "documentation", "RFC 3849", false, true},
+	{netip.MustParsePrefix("3fff::/20"), "documentation", "RFC 9637", false, true},
+	{netip.MustParsePrefix("fc00::/7"), "unique-local", "RFC 4193", false, true},
+	{netip.MustParsePrefix("fe80::/10"), "link-local", "RFC 4291", false, true},
+	{netip.MustParsePrefix("ff00::/8"), "multicast", "RFC 4291", true, false},
+}
+
+// Martians returns the special-purpose blocks overlapping prefix p that should never be used in context ctx.
+func Martians(p netip.Prefix, ctx Context) []SpecialBlock {
+	var found []SpecialBlock
+	for _, b := range SpecialBlocks {
+		if !b.Prefix.Overlaps(p) {
+			continue
+		}
+		if (ctx == ContextWAN && !b.WAN) || (ctx == ContextLAN && !b.LANUsable) {
+			found = append(found, b)
+		}
+	}
+	return found
+}
+
+// specialBlockFor returns the most specific special-purpose block containing prefix p, or nil if there isn't one.
+func specialBlockFor(p netip.Prefix) *SpecialBlock {
+	var match *SpecialBlock
+	for i, b := range SpecialBlocks {
+		if b.Prefix.Bits() <= p.Bits() && b.Prefix.Contains(p.Addr()) && (match == nil || b.Prefix.Bits() > match.Prefix.Bits()) {
+			match = &SpecialBlocks[i]
+		}
+	}
+	return match
+}
+
+// Classify returns the name of the most specific special-purpose block containing prefix p. Prefixes outside of every
+// special-purpose block are classified as "public" for IPv4 and "global unicast" for IPv6.
+func Classify(p netip.Prefix) string {
+	match := specialBlockFor(p)
+	switch {
+	case match != nil:
+		return match.Name
+	case p.Addr().Is4():
+		return "public"
+	default:
+		return "global unicast"
+	}
+}
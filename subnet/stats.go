@@ -0,0 +1,48 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"math/big"
+	"net/netip"
+)
+
+// Stats summarizes the subnets a network has been split into.
+type Stats struct {
+	Subnets       int            `json:"subnets"`
+	UsableHosts   *big.Int       `json:"usableHosts"`
+	Smallest      netip.Prefix   `json:"smallest"`
+	Largest       netip.Prefix   `json:"largest"`
+	FreeAddresses *big.Int       `json:"freeAddresses"`
+	Free          []netip.Prefix `json:"free,omitempty"`
+}
+
+// SetStats records statistics about the network's subnets in n.Stats, or clears it if the network hasn't been split.
+func (n *Network) SetStats() {
+	if len(n.Subnets) == 0 {
+		n.Stats = nil
+		return
+	}
+
+	s := Stats{Subnets: len(n.Subnets), UsableHosts: new(big.Int), Smallest: n.Subnets[0].CIDR, Largest: n.Subnets[0].CIDR}
+	used := new(big.Int)
+	prefixes := make([]netip.Prefix, 0, len(n.Subnets))
+	for _, sub := range n.Subnets {
+		s.UsableHosts.Add(s.UsableHosts, sub.MaxHosts)
+		used.Add(used, AddressCount(sub.CIDR))
+		prefixes = append(prefixes, sub.CIDR)
+		if sub.MaskBits > s.Smallest.Bits() {
+			s.Smallest = sub.CIDR
+		}
+		if sub.MaskBits < s.Largest.Bits() {
+			s.Largest = sub.CIDR
+		}
+	}
+	s.FreeAddresses = used.Sub(AddressCount(n.CIDR), used)
+	// an even split leaves nothing free, so only look for free blocks when there are some
+	if s.FreeAddresses.Sign() > 0 {
+		s.Free = Exclude(n.CIDR, prefixes)
+	}
+	n.Stats = &s
+}
@@ -0,0 +1,97 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"sort"
+)
+
+// Tier is one allocation a plan template makes at every site.
+type Tier struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`           // prefix length
+	VLAN int    `json:"vlan,omitempty"` // VLAN ID, the same at every site
+}
+
+// Template describes the allocations made at each site of a plan.
+type Template struct {
+	Description string `json:"description,omitempty"`
+	Tiers       []Tier `json:"tiers"`
+}
+
+// PlanTemplates are the built-in plan templates.
+var PlanTemplates = map[string]Template{
+	"3-tier": {
+		Description: "management, server, and user networks",
+		Tiers:       []Tier{{Name: "mgmt", Size: 26}, {Name: "servers", Size: 24}, {Name: "users", Size: 23}},
+	},
+	"branch": {
+		Description: "small office with voice and guest networks",
+		Tiers:       []Tier{{Name: "mgmt", Size: 27}, {Name: "users", Size: 24}, {Name: "voice", Size: 25}, {Name: "guest", Size: 25}},
+	},
+	"datacenter": {
+		Description: "out-of-band, management, server, and storage networks",
+		Tiers:       []Tier{{Name: "oob", Size: 24}, {Name: "mgmt", Size: 24}, {Name: "servers", Size: 21}, {Name: "storage", Size: 23}},
+	},
+}
+
+// SiteBits returns the prefix length of the smallest block holding every tier of the template, for addresses of
+// bitLen bits.
+// returns an error if the template has no tiers or a tier's size is invalid.
+func (t Template) SiteBits(bitLen int) (int, error) {
+	if len(t.Tiers) == 0 {
+		return 0, fmt.Errorf("template has no tiers")
+	}
+	total := new(big.Int)
+	for _, tier := range t.Tiers {
+		if tier.Size < 1 || tier.Size > bitLen {
+			return 0, fmt.Errorf("tier %s has invalid size /%d", tier.Name, tier.Size)
+		}
+		total.Add(total, new(big.Int).Lsh(big.NewInt(1), uint(bitLen-tier.Size)))
+	}
+	// tiers are powers of two, so placed largest first they pack into the next power of two up
+	hostBits := total.Sub(total, big.NewInt(1)).BitLen()
+	return bitLen - hostBits, nil
+}
+
+// NewPlanFromTemplate returns a plan for sites sites in supernet. Each site is given its own aligned block, so it can
+// be summarized as one route, holding one allocation per tier named site<n>-<tier>.
+// returns an error if the sites don't fit in supernet.
+func NewPlanFromTemplate(name string, supernet netip.Prefix, t Template, sites int) (Plan, error) {
+	supernet = supernet.Masked()
+	p := Plan{Name: name, Supernets: []netip.Prefix{supernet}, Allocations: []Allocation{}}
+	siteBits, err := t.SiteBits(supernet.Addr().BitLen())
+	if err != nil {
+		return p, err
+	}
+	n := NewNetworkFromPrefix(supernet)
+	if siteBits < supernet.Bits() || n.SubnetCount(siteBits).Cmp(big.NewInt(int64(sites))) < 0 {
+		return p, fmt.Errorf("%d sites of /%d don't fit in %v", sites, siteBits, supernet)
+	}
+
+	// largest tiers first, so each fits at the next aligned address
+	tiers := append([]Tier{}, t.Tiers...)
+	sort.SliceStable(tiers, func(i, j int) bool { return tiers[i].Size < tiers[j].Size })
+	for site := 1; site <= sites; site++ {
+		block := netip.PrefixFrom(supernet.Addr(), siteBits)
+		if siteBits > supernet.Bits() {
+			blocks, _ := n.SubnetsOf(siteBits, big.NewInt(int64(site-1)), big.NewInt(1), 1)
+			block = blocks[0].CIDR
+		}
+		for _, tier := range tiers {
+			next, ok := NextFree(block, tier.Size, p.allocatedPrefixes())
+			if !ok {
+				return p, fmt.Errorf("tier %s doesn't fit in site block %v", tier.Name, block)
+			}
+			a := Allocation{CIDR: next, Name: fmt.Sprintf("site%d-%s", site, tier.Name), VLAN: tier.VLAN}
+			if err := p.Allocate(a); err != nil {
+				return p, err
+			}
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,181 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import "net/netip"
+
+// Trie is a path-compressed binary trie of prefixes, each holding a value of type T. It answers longest prefix match,
+// containment, and overlap queries in time proportional to the prefix length rather than the number of prefixes, and
+// walks prefixes in the order SortPrefixes uses. IPv4 and IPv6 prefixes are kept in separate trees. The zero value is
+// an empty trie.
+type Trie[T any] struct {
+	v4, v6 *trieNode[T]
+	size   int
+}
+
+// trieNode is a prefix in a trie. Nodes that weren't inserted, but branch where two inserted prefixes diverge, have set
+// false.
+type trieNode[T any] struct {
+	prefix netip.Prefix
+	value  T
+	set    bool
+	child  [2]*trieNode[T]
+}
+
+// bitAt returns bit i of a, counting from the most significant bit.
+func bitAt(a netip.Addr, i int) int {
+	u, shift := u128(a), a.BitLen()-1-i
+	if shift >= 64 {
+		return int(u.hi >> (shift - 64) & 1)
+	}
+	return int(u.lo >> shift & 1)
+}
+
+// root returns the tree holding prefixes of a's family.
+func (t *Trie[T]) root(a netip.Addr) **trieNode[T] {
+	if a.Is4() {
+		return &t.v4
+	}
+	return &t.v6
+}
+
+// Len returns the number of prefixes in the trie.
+func (t *Trie[T]) Len() int {
+	return t.size
+}
+
+// Insert adds prefix p to the trie with value v, replacing the value if p is already present. Host bits are ignored.
+func (t *Trie[T]) Insert(p netip.Prefix, v T) {
+	p = p.Masked()
+	link := t.root(p.Addr())
+	for {
+		n := *link
+		if n == nil {
+			*link = &trieNode[T]{prefix: p, value: v, set: true}
+			t.size++
+			return
+		}
+
+		common := min(CommonPrefixLen(n.prefix.Addr(), p.Addr()), n.prefix.Bits(), p.Bits())
+		switch {
+		case common == n.prefix.Bits() && common == p.Bits():
+			if !n.set {
+				t.size++
+			}
+			n.value, n.set = v, true
+			return
+		case common == n.prefix.Bits():
+			link = &n.child[bitAt(p.Addr(), common)]
+		case common == p.Bits():
+			// p contains the node, so it takes the node's place
+			parent := &trieNode[T]{prefix: p, value: v, set: true}
+			parent.child[bitAt(n.prefix.Addr(), common)] = n
+			*link = parent
+			t.size++
+			return
+		default:
+			// p and the node diverge below their common bits, so branch there
+			branch := &trieNode[T]{prefix: netip.PrefixFrom(p.Addr(), common).Masked()}
+			branch.child[bitAt(n.prefix.Addr(), common)] = n
+			branch.child[bitAt(p.Addr(), common)] = &trieNode[T]{prefix: p, value: v, set: true}
+			*link = branch
+			t.size++
+			return
+		}
+	}
+}
+
+// Get returns the value of prefix p.
+// returns false if p isn't in the trie.
+func (t *Trie[T]) Get(p netip.Prefix) (T, bool) {
+	var found T
+	ok := false
+	t.Supernets(p, func(q netip.Prefix, v T) bool {
+		if q == p.Masked() {
+			found, ok = v, true
+		}
+		return false
+	})
+	return found, ok
+}
+
+// Lookup returns the longest prefix in the trie containing address a, and its value.
+// returns false if no prefix contains a.
+func (t *Trie[T]) Lookup(a netip.Addr) (netip.Prefix, T, bool) {
+	var match netip.Prefix
+	var value T
+	t.Supernets(netip.PrefixFrom(a, a.BitLen()), func(p netip.Prefix, v T) bool {
+		match, value = p, v
+		return false
+	})
+	return match, value, match.IsValid()
+}
+
+// Supernets calls fn for each prefix in the trie that contains p, including p itself, from the longest to the
+// shortest, until fn returns false.
+func (t *Trie[T]) Supernets(p netip.Prefix, fn func(netip.Prefix, T) bool) {
+	p = p.Masked()
+	var path []*trieNode[T]
+	for n := *t.root(p.Addr()); n != nil && n.prefix.Bits() <= p.Bits() && n.prefix.Contains(p.Addr()); {
+		if n.set {
+			path = append(path, n)
+		}
+		if n.prefix.Bits() == p.Bits() {
+			break
+		}
+		n = n.child[bitAt(p.Addr(), n.prefix.Bits())]
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		if !fn(path[i].prefix, path[i].value) {
+			return
+		}
+	}
+}
+
+// Subnets calls fn for each prefix in the trie contained in p, including p itself, in address order, until fn returns
+// false.
+func (t *Trie[T]) Subnets(p netip.Prefix, fn func(netip.Prefix, T) bool) {
+	p = p.Masked()
+	n := *t.root(p.Addr())
+	for n != nil && n.prefix.Bits() < p.Bits() && n.prefix.Contains(p.Addr()) {
+		n = n.child[bitAt(p.Addr(), n.prefix.Bits())]
+	}
+	if n != nil && p.Contains(n.prefix.Addr()) && n.prefix.Bits() >= p.Bits() {
+		n.walk(fn)
+	}
+}
+
+// Overlaps reports whether any prefix in the trie shares addresses with p.
+func (t *Trie[T]) Overlaps(p netip.Prefix) bool {
+	found := false
+	stop := func(netip.Prefix, T) bool {
+		found = true
+		return false
+	}
+	t.Supernets(p, stop)
+	if !found {
+		t.Subnets(p, stop)
+	}
+	return found
+}
+
+// Walk calls fn for each prefix in the trie, IPv4 before IPv6, sorted by address and then mask length, until fn
+// returns false.
+func (t *Trie[T]) Walk(fn func(netip.Prefix, T) bool) {
+	if t.v4.walk(fn) {
+		t.v6.walk(fn)
+	}
+}
+
+// walk calls fn for the node and its descendants in order.
+// returns false if fn stopped the walk.
+func (n *trieNode[T]) walk(fn func(netip.Prefix, T) bool) bool {
+	if n == nil {
+		return true
+	}
+	if n.set && !fn(n.prefix, n.value) {
+		return false
+	}
+	return n.child[0].walk(fn) && n.child[1].walk(fn)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"math/rand"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+// testTrie returns a trie holding each of prefixes with its index as the value, so later duplicates replace earlier
+// ones.
+func testTrie(prefixes []netip.Prefix) *Trie[int] {
+	var t Trie[int]
+	for i, p := range prefixes {
+		t.Insert(p, i)
+	}
+	return &t
+}
+
+func TestTrieLookup(t *testing.T) {
+	trie := testTrie(mustPrefixes("0.0.0.0/0", "10.0.0.0/8", "10.1.0.0/16", "10.1.2.3/32", "::/0", "2001:db8::/32",
+		"2001:db8::1/128"))
+	tests := []struct {
+		addr string
+		want string
+		ok   bool
+	}{
+		{"10.1.2.3", "10.1.2.3/32", true},
+		{"10.1.2.4", "10.1.0.0/16", true},
+		{"10.2.0.0", "10.0.0.0/8", true},
+		{"192.0.2.1", "0.0.0.0/0", true},
+		{"2001:db8::1", "2001:db8::1/128", true},
+		{"2001:db8::2", "2001:db8::/32", true},
+		{"fe80::1", "::/0", true},
+	}
+	for _, tt := range tests {
+		got, _, ok := trie.Lookup(netip.MustParseAddr(tt.addr))
+		if ok != tt.ok || got != netip.MustParsePrefix(tt.want) {
+			t.Errorf("Lookup(%s) = %v, %t, want %s, %t", tt.addr, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	// each family is its own tree, so a default route of one doesn't match the other
+	trie = testTrie(mustPrefixes("0.0.0.0/0"))
+	if got, _, ok := trie.Lookup(netip.MustParseAddr("2001:db8::1")); ok {
+		t.Errorf("Lookup(2001:db8::1) = %v, want no match", got)
+	}
+}
+
+func TestTrieInsert(t *testing.T) {
+	trie := testTrie(mustPrefixes("10.0.0.0/24", "10.0.1.0/24", "10.0.0.5/24", "2001:db8::/32"))
+	if got := trie.Len(); got != 3 {
+		t.Errorf("Len() = %d, want 3", got)
+	}
+	if v, ok := trie.Get(netip.MustParsePrefix("10.0.0.0/24")); !ok || v != 2 {
+		t.Errorf("Get(10.0.0.0/24) = %d, %t, want the replaced value 2, true", v, ok)
+	}
+	// 10.0.0.0/23 exists as a branch node, but wasn't inserted
+	if v, ok := trie.Get(netip.MustParsePrefix("10.0.0.0/23")); ok {
+		t.Errorf("Get(10.0.0.0/23) = %d, true, want false", v)
+	}
+	trie.Insert(netip.MustParsePrefix("10.0.0.0/23"), 9)
+	if got := trie.Len(); got != 4 {
+		t.Errorf("Len() after inserting a branch prefix = %d, want 4", got)
+	}
+}
+
+func TestTrieBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	for i := 0; i < 300; i++ {
+		prefixes := randomPrefixes(r, r.Intn(16))
+		trie := testTrie(prefixes)
+
+		// the naive trie is a map from each masked prefix to the index of its last insertion
+		naive := map[netip.Prefix]int{}
+		for j, p := range prefixes {
+			naive[p.Masked()] = j
+		}
+		if trie.Len() != len(naive) {
+			t.Fatalf("Len() of %v = %d, want %d", prefixes, trie.Len(), len(naive))
+		}
+
+		var want []netip.Prefix
+		for p := range naive {
+			want = append(want, p)
+		}
+		SortPrefixes(want)
+		var walked []netip.Prefix
+		trie.Walk(func(p netip.Prefix, v int) bool {
+			if v != naive[p] {
+				t.Fatalf("Walk of %v gave %v the value %d, want %d", prefixes, p, v, naive[p])
+			}
+			walked = append(walked, p)
+			return true
+		})
+		if len(walked) != len(want) || len(want) > 0 && !reflect.DeepEqual(walked, want) {
+			t.Fatalf("Walk of %v = %v, want %v", prefixes, walked, want)
+		}
+
+		for a := 0; a < 256; a++ {
+			addr := netip.AddrFrom4([4]byte{10, 0, 0, byte(a)})
+			var longest netip.Prefix
+			for p := range naive {
+				if p.Contains(addr) && (!longest.IsValid() || p.Bits() > longest.Bits()) {
+					longest = p
+				}
+			}
+			got, v, ok := trie.Lookup(addr)
+			if got != longest || ok != longest.IsValid() || ok && v != naive[longest] {
+				t.Fatalf("Lookup(%v) in %v = %v, %d, %t, want %v", addr, prefixes, got, v, ok, longest)
+			}
+		}
+
+		for _, q := range randomPrefixes(r, 8) {
+			q = q.Masked()
+			overlaps := false
+			var supernets, subnets []netip.Prefix
+			for _, p := range want {
+				overlaps = overlaps || p.Overlaps(q)
+				if p.Bits() <= q.Bits() && p.Contains(q.Addr()) {
+					supernets = append([]netip.Prefix{p}, supernets...)
+				}
+				if q.Bits() <= p.Bits() && q.Contains(p.Addr()) {
+					subnets = append(subnets, p)
+				}
+			}
+			if got := trie.Overlaps(q); got != overlaps {
+				t.Fatalf("Overlaps(%v) in %v = %t, want %t", q, prefixes, got, overlaps)
+			}
+			if got := collect(trie.Supernets, q); !reflect.DeepEqual(got, supernets) {
+				t.Fatalf("Supernets(%v) in %v = %v, want %v", q, prefixes, got, supernets)
+			}
+			if got := collect(trie.Subnets, q); !reflect.DeepEqual(got, subnets) {
+				t.Fatalf("Subnets(%v) in %v = %v, want %v", q, prefixes, got, subnets)
+			}
+		}
+	}
+}
+
+// collect returns the prefixes a trie query calls its function with, in order.
+func collect(query func(netip.Prefix, func(netip.Prefix, int) bool), p netip.Prefix) []netip.Prefix {
+	var got []netip.Prefix
+	query(p, func(q netip.Prefix, _ int) bool {
+		got = append(got, q)
+		return true
+	})
+	return got
+}
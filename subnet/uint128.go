@@ -0,0 +1,127 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package subnet
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"math/bits"
+	"net/netip"
+)
+
+// uint128 is an unsigned 128 bit integer used for address arithmetic without allocating. IPv4 addresses are held in
+// the low 32 bits.
+type uint128 struct {
+	hi, lo uint64
+}
+
+// u128 returns the address as an unsigned integer.
+func u128(a netip.Addr) uint128 {
+	if a.Is4() {
+		b := a.As4()
+		return uint128{lo: uint64(binary.BigEndian.Uint32(b[:]))}
+	}
+	b := a.As16()
+	return uint128{hi: binary.BigEndian.Uint64(b[:8]), lo: binary.BigEndian.Uint64(b[8:])}
+}
+
+// u128FromBig returns i as a uint128.
+// returns false if i is negative or doesn't fit in 128 bits.
+func u128FromBig(i *big.Int) (uint128, bool) {
+	if i.Sign() < 0 || i.BitLen() > 128 {
+		return uint128{}, false
+	}
+	var b [16]byte
+	i.FillBytes(b[:])
+	return uint128{hi: binary.BigEndian.Uint64(b[:8]), lo: binary.BigEndian.Uint64(b[8:])}, true
+}
+
+// addr returns the address of the given bit length, 32 or 128, with the value u.
+// returns false if u doesn't fit in an address of that length.
+func (u uint128) addr(bitLen int) (netip.Addr, bool) {
+	if bitLen == 32 {
+		if u.hi != 0 || u.lo > math.MaxUint32 {
+			return netip.Addr{}, false
+		}
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], uint32(u.lo))
+		return netip.AddrFrom4(b), true
+	}
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], u.hi)
+	binary.BigEndian.PutUint64(b[8:], u.lo)
+	return netip.AddrFrom16(b), true
+}
+
+// big returns u as a big.Int.
+func (u uint128) big() *big.Int {
+	i := new(big.Int).SetUint64(u.hi)
+	return i.Lsh(i, 64).Or(i, new(big.Int).SetUint64(u.lo))
+}
+
+// add returns u+v.
+// returns false if the sum overflows 128 bits.
+func (u uint128) add(v uint128) (uint128, bool) {
+	lo, carry := bits.Add64(u.lo, v.lo, 0)
+	hi, carry := bits.Add64(u.hi, v.hi, carry)
+	return uint128{hi: hi, lo: lo}, carry == 0
+}
+
+// sub returns u-v.
+// returns false if v is larger than u.
+func (u uint128) sub(v uint128) (uint128, bool) {
+	lo, borrow := bits.Sub64(u.lo, v.lo, 0)
+	hi, borrow := bits.Sub64(u.hi, v.hi, borrow)
+	return uint128{hi: hi, lo: lo}, borrow == 0
+}
+
+// and returns the bitwise AND of u and v.
+func (u uint128) and(v uint128) uint128 {
+	return uint128{hi: u.hi & v.hi, lo: u.lo & v.lo}
+}
+
+// or returns the bitwise OR of u and v.
+func (u uint128) or(v uint128) uint128 {
+	return uint128{hi: u.hi | v.hi, lo: u.lo | v.lo}
+}
+
+// xor returns the bitwise XOR of u and v.
+func (u uint128) xor(v uint128) uint128 {
+	return uint128{hi: u.hi ^ v.hi, lo: u.lo ^ v.lo}
+}
+
+// lsh returns u shifted left by n bits. Shifts of 128 bits or more return 0.
+func (u uint128) lsh(n uint) uint128 {
+	switch {
+	case n >= 128:
+		return uint128{}
+	case n >= 64:
+		return uint128{hi: u.lo << (n - 64)}
+	default:
+		return uint128{hi: u.hi<<n | u.lo>>(64-n), lo: u.lo << n}
+	}
+}
+
+// leadingZeros returns the number of leading zero bits in u.
+func (u uint128) leadingZeros() int {
+	if u.hi != 0 {
+		return bits.LeadingZeros64(u.hi)
+	}
+	return 64 + bits.LeadingZeros64(u.lo)
+}
+
+// hostMask returns the integer with the low bitLen-maskBits bits set, the host bits of a prefix.
+func hostMask(maskBits, bitLen int) uint128 {
+	n := bitLen - maskBits
+	switch {
+	case n <= 0:
+		return uint128{}
+	case n >= 128:
+		return uint128{hi: math.MaxUint64, lo: math.MaxUint64}
+	default:
+		m, _ := uint128{lo: 1}.lsh(uint(n)).sub(uint128{lo: 1})
+		return m
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package utils
+
+import (
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/mattn/go-isatty"
+)
+
+// TerminalWidth returns the width in columns of the terminal f is attached to.
+// returns false if f is not a terminal.
+func TerminalWidth(f *os.File) (int, bool) {
+	width, err := terminalWidth(f.Fd())
+	if err != nil || width <= 0 {
+		return 0, false
+	}
+	return width, true
+}
+
+// Width returns the number of columns output should fit in. A positive override is always used. Otherwise the width of
+// the first of stdout, stderr, and stdin attached to a terminal is used, which still finds the terminal when stdout is
+// redirected by a wrapper, falling back to the COLUMNS environment variable.
+// returns false if the width can't be determined.
+func Width(override int) (int, bool) {
+	if override > 0 {
+		return override, true
+	}
+	for _, f := range []*os.File{os.Stdout, os.Stderr, os.Stdin} {
+		if width, ok := TerminalWidth(f); ok {
+			return width, true
+		}
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width, true
+	}
+	return 0, false
+}
+
+// WatchWidth calls fn with the new width each time the terminal is resized, for long running modes that redraw their
+// output. Nothing is watched when override is positive, or on platforms without a resize signal.
+// returns a function that stops watching.
+func WatchWidth(override int, fn func(width int)) (stop func()) {
+	if override > 0 || len(resizeSignals) == 0 {
+		return func() {}
+	}
+	resized := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(resized, resizeSignals...)
+	go func() {
+		for {
+			select {
+			case <-resized:
+				if width, ok := Width(0); ok {
+					fn(width)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(resized)
+		close(done)
+	}
+}
+
+// IsTerminal reports whether f is attached to a terminal.
+func IsTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
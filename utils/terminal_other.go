@@ -0,0 +1,19 @@
+//go:build !unix && !windows
+
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package utils
+
+import (
+	"errors"
+	"os"
+)
+
+// resizeSignals is empty, resizes can not be detected on this platform.
+var resizeSignals []os.Signal
+
+// terminalWidth is not supported on this platform.
+func terminalWidth(fd uintptr) (int, error) {
+	return 0, errors.New("terminal width detection is not supported on this platform")
+}
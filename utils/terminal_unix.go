@@ -0,0 +1,24 @@
+//go:build unix
+
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package utils
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// resizeSignals are delivered when the terminal window changes size.
+var resizeSignals = []os.Signal{unix.SIGWINCH}
+
+// terminalWidth queries the kernel for the window size of the terminal attached to fd.
+func terminalWidth(fd uintptr) (int, error) {
+	ws, err := unix.IoctlGetWinsize(int(fd), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, err
+	}
+	return int(ws.Col), nil
+}
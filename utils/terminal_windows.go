@@ -0,0 +1,24 @@
+//go:build windows
+
+/*
+Copyright © 2023 Jake Rogers <code@supportoss.org>
+*/
+package utils
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// resizeSignals is empty, consoles do not signal resizes.
+var resizeSignals []os.Signal
+
+// terminalWidth queries the console attached to fd for the width of its visible window.
+func terminalWidth(fd uintptr) (int, error) {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(fd), &info); err != nil {
+		return 0, err
+	}
+	return int(info.Window.Right-info.Window.Left) + 1, nil
+}
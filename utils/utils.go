@@ -4,7 +4,9 @@ Copyright © 2023 Jake Rogers <code@supportoss.org>
 package utils
 
 import (
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -17,8 +19,14 @@ const (
 
 var (
 	Log = Logger(DefaultLogLevel)
+
+	// moduleLevels holds the log levels of modules filtered with --log-level, overriding the level of Log.
+	moduleLevels = map[string]zerolog.Level{}
 )
 
+// LogLevels lists the level names accepted by --log-level.
+var LogLevels = []string{"trace", "debug", "info", "warn", "error"}
+
 // Logger returns a zerolog logger with a console writer.
 func Logger(level zerolog.Level) zerolog.Logger {
 	return zerolog.New(
@@ -33,9 +41,63 @@ func Logger(level zerolog.Level) zerolog.Logger {
 		Logger()
 }
 
-// SetLogLevel sets the log level based on the number of times the verbose flag is used.
+// Module returns the logger for the named module, e.g. "routes" or "importer". Its messages are tagged with the module
+// name and filtered at the module's own level when one was set with --log-level.
+func Module(name string) *zerolog.Logger {
+	l := Log.With().Str("module", name).Logger()
+	if level, ok := moduleLevels[name]; ok {
+		l = l.Level(level)
+	}
+	return &l
+}
+
+// parseLevel parses one of the names in LogLevels.
+func parseLevel(name string) (zerolog.Level, error) {
+	for _, l := range LogLevels {
+		if name == l {
+			return zerolog.ParseLevel(name)
+		}
+	}
+	return zerolog.NoLevel, fmt.Errorf("invalid log level %q, expected one of: %s", name, strings.Join(LogLevels, ", "))
+}
+
+// ConfigureLogging sets the log level from a --log-level value: an optional level name followed by comma separated
+// module=level filters, e.g. "warn,routes=debug".
+// returns an error if a level or filter is invalid.
+func ConfigureLogging(spec string) error {
+	level := Log.GetLevel()
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		module, name, isFilter := strings.Cut(entry, "=")
+		if !isFilter {
+			name = module
+		}
+		if name == "" {
+			continue
+		}
+		l, err := parseLevel(name)
+		if err != nil {
+			return err
+		}
+		if isFilter {
+			moduleLevels[module] = l
+		} else {
+			level = l
+		}
+	}
+	Log = Logger(level)
+	return nil
+}
+
+// SetLogLevel sets the log level based on the number of times the verbose flag is used, or the log-level flag.
 func SetLogLevel(cmd *cobra.Command, args []string) {
 	verbosity, _ := cmd.Flags().GetCount("verbose")
 	level := Log.GetLevel()
 	Log = Logger(level - zerolog.Level(verbosity))
+
+	if spec, _ := cmd.Flags().GetString("log-level"); spec != "" {
+		if err := ConfigureLogging(spec); err != nil {
+			Log.Fatal().Msg(err.Error())
+		}
+	}
 }